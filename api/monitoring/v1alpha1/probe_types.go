@@ -0,0 +1,130 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Probe defines synthetic checks of a list of targets. The Prometheuses
+// selecting it run a blackbox exporter with the module of the Probe and
+// scrape it under the job name probe/<namespace>/<name>.
+// +genclient
+// +genclient:noStatus
+// +k8s:openapi-gen=true
+// +kubebuilder:object:root=true
+type Probe struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	// Specification of the checks.
+	Spec ProbeSpec `json:"spec"`
+}
+
+// ProbeList is a list of Probes.
+// +k8s:openapi-gen=true
+// +kubebuilder:object:root=true
+type ProbeList struct {
+	metav1.TypeMeta `json:",inline"`
+	// Standard list metadata
+	// More info: https://github.com/kubernetes/community/blob/master/contributors/devel/sig-architecture/api-conventions.md#metadata
+	metav1.ListMeta `json:"metadata,omitempty"`
+	// List of Probes
+	Items []Probe `json:"items"`
+}
+
+// ProbeSpec define the targets of a Probe and how they are checked
+type ProbeSpec struct {
+	// Module of the blackbox exporter checking the targets.
+	Module ProbeModule `json:"module"`
+	// Targets to check: URLs for the http prober, host:port for the tcp
+	// prober and hosts for the icmp prober.
+	// +kubebuilder:validation:MinItems=1
+	Targets []string `json:"targets"`
+	// Labels added to the metrics of the targets.
+	// +optional
+	Labels map[string]string `json:"labels,omitempty"`
+	// Relabeling applied to the targets before they are redirected to the
+	// blackbox exporter. The target is in the __address__ label.
+	// +optional
+	RelabelConfigs []*RelabelConfig `json:"relabel_configs,omitempty"`
+}
+
+// ProbeModule define a blackbox exporter module, in the format of the
+// blackbox exporter configuration
+type ProbeModule struct {
+	// +kubebuilder:validation:Enum=http;tcp;icmp
+	Prober string `json:"prober"`
+	// +kubebuilder:validation:Pattern=^(0|(([0-9]+)y)?(([0-9]+)w)?(([0-9]+)d)?(([0-9]+)h)?(([0-9]+)m)?(([0-9]+)s)?(([0-9]+)ms)?)$
+	// +optional
+	Timeout *string `json:"timeout,omitempty"`
+	// Settings of the http prober.
+	// +optional
+	HTTP *HTTPProbe `json:"http,omitempty"`
+	// Settings of the tcp prober.
+	// +optional
+	TCP *TCPProbe `json:"tcp,omitempty"`
+	// Settings of the icmp prober.
+	// +optional
+	ICMP *ICMPProbe `json:"icmp,omitempty"`
+}
+
+// HTTPProbe define the settings of the http prober
+type HTTPProbe struct {
+	// +kubebuilder:validation:Enum=GET;HEAD;POST;PUT;DELETE;OPTIONS;PATCH
+	// +optional
+	Method *string `json:"method,omitempty"`
+	// Status codes accepted as a success. Defaults to 2xx.
+	// +optional
+	ValidStatusCodes []int32 `json:"valid_status_codes,omitempty"`
+	// +optional
+	FollowRedirects *bool `json:"follow_redirects,omitempty"`
+	// +kubebuilder:validation:Enum=ip4;ip6
+	// +optional
+	PreferredIPProtocol *string `json:"preferred_ip_protocol,omitempty"`
+}
+
+// TCPProbe define the settings of the tcp prober
+type TCPProbe struct {
+	// Whether the connection is upgraded to TLS.
+	// +optional
+	TLS *bool `json:"tls,omitempty"`
+	// +kubebuilder:validation:Enum=ip4;ip6
+	// +optional
+	PreferredIPProtocol *string `json:"preferred_ip_protocol,omitempty"`
+}
+
+// ICMPProbe define the settings of the icmp prober
+type ICMPProbe struct {
+	// +kubebuilder:validation:Enum=ip4;ip6
+	// +optional
+	PreferredIPProtocol *string `json:"preferred_ip_protocol,omitempty"`
+}
+
+// JobName returns the job name of the Probe in the Prometheus configuration.
+func (p *Probe) JobName() string {
+	return "probe/" + p.Namespace + "/" + p.Name
+}
+
+// ModuleName returns the name of the module of the Probe in the blackbox
+// exporter configuration.
+func (p *Probe) ModuleName() string {
+	return p.Namespace + "_" + p.Name
+}
+
+func init() {
+	SchemeBuilder.Register(&Probe{}, &ProbeList{})
+}
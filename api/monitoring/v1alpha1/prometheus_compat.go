@@ -0,0 +1,213 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/apimachinery/pkg/util/version"
+)
+
+// ForceVersionDowngradeAnnotation, when set to "true" on a Prometheus, lets
+// the validating webhook accept a downgrade to a previous major version.
+const ForceVersionDowngradeAnnotation = "monitoring.mroque/force-version-downgrade"
+
+// minSupportedVersion is the oldest Prometheus version the operator can
+// manage, the first one reloadable through the lifecycle API.
+var minSupportedVersion = version.MustParseSemantic("2.0.0")
+
+// versionRequirement is an entry of the compatibility matrix. It gives the
+// first Prometheus version supporting a spec field.
+type versionRequirement struct {
+	minVersion *version.Version
+	// fields returns the paths of the fields of the spec using the feature
+	fields func(spec *PrometheusSpec, specPath *field.Path) []*field.Path
+}
+
+// compatibilityMatrix lists the spec fields that aren't supported by every
+// Prometheus version.
+var compatibilityMatrix = []versionRequirement{{
+	minVersion: version.MustParseSemantic("2.32.0"),
+	fields: func(spec *PrometheusSpec, specPath *field.Path) []*field.Path {
+		if spec.Mode != nil && *spec.Mode == ModeAgent {
+			return []*field.Path{specPath.Child("mode")}
+		}
+		return nil
+	},
+}, {
+	minVersion: version.MustParseSemantic("2.7.0"),
+	fields: func(spec *PrometheusSpec, specPath *field.Path) []*field.Path {
+		var paths []*field.Path
+		if spec.TSDB != nil && spec.TSDB.RetentionTime != nil {
+			paths = append(paths, specPath.Child("tsdb", "retentionTime"))
+		}
+		if spec.TSDB != nil && spec.TSDB.RetentionSize != nil {
+			paths = append(paths, specPath.Child("tsdb", "retentionSize"))
+		}
+		return paths
+	},
+}, {
+	minVersion: version.MustParseSemantic("2.11.0"),
+	fields: func(spec *PrometheusSpec, specPath *field.Path) []*field.Path {
+		if spec.TSDB != nil && spec.TSDB.WALCompression != nil {
+			return []*field.Path{specPath.Child("tsdb", "walCompression")}
+		}
+		return nil
+	},
+}, {
+	minVersion: version.MustParseSemantic("2.39.0"),
+	fields: func(spec *PrometheusSpec, specPath *field.Path) []*field.Path {
+		if spec.TSDB != nil && spec.TSDB.OutOfOrderTimeWindow != nil {
+			return []*field.Path{specPath.Child("tsdb", "outOfOrderTimeWindow")}
+		}
+		return nil
+	},
+}, {
+	minVersion: version.MustParseSemantic("2.24.0"),
+	fields: func(spec *PrometheusSpec, specPath *field.Path) []*field.Path {
+		if spec.Web != nil {
+			return []*field.Path{specPath.Child("web")}
+		}
+		return nil
+	},
+}, {
+	minVersion: version.MustParseSemantic("2.15.0"),
+	fields: func(spec *PrometheusSpec, specPath *field.Path) []*field.Path {
+		var paths []*field.Path
+		for i, rw := range spec.RemoteWrite {
+			if rw.Name != nil {
+				paths = append(paths, specPath.Child("remote_write").Index(i).Child("name"))
+			}
+		}
+		return paths
+	},
+}}
+
+// scrapeConfigRequirement is an entry of the compatibility matrix of the
+// scrape configs. It gives the first Prometheus version supporting a field.
+type scrapeConfigRequirement struct {
+	minVersion *version.Version
+	// fields returns the paths of the fields of the scrape config using the feature
+	fields func(sc *ScrapeConfigSpec, scPath *field.Path) []*field.Path
+}
+
+// scrapeConfigCompatibility lists the scrape config fields that aren't
+// supported by every Prometheus version. It applies to the scrape configs of
+// the spec and to the selected ScrapeConfig objects.
+var scrapeConfigCompatibility = []scrapeConfigRequirement{{
+	minVersion: version.MustParseSemantic("2.28.0"),
+	fields: func(sc *ScrapeConfigSpec, scPath *field.Path) []*field.Path {
+		if len(sc.HTTPSDConfigs) > 0 {
+			return []*field.Path{scPath.Child("http_sd_configs")}
+		}
+		return nil
+	},
+}, {
+	minVersion: version.MustParseSemantic("2.17.0"),
+	fields: func(sc *ScrapeConfigSpec, scPath *field.Path) []*field.Path {
+		var paths []*field.Path
+		for j, sd := range sc.K8SSDConfigs {
+			if len(sd.Selectors) > 0 {
+				paths = append(paths, scPath.Child("kubernetes_sd_configs").Index(j).Child("selectors"))
+			}
+		}
+		return paths
+	},
+}}
+
+// thanosWebMinVersion is the first Thanos version able to connect to a
+// Prometheus web endpoint secured with TLS or basic authentication.
+var thanosWebMinVersion = version.MustParseSemantic("0.29.0")
+
+// validateVersion checks the requested version is supported by the operator
+// and by every field set in the spec.
+func (r *Prometheus) validateVersion(specPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	if r.Spec.Version == nil {
+		return nil
+	}
+	v, err := version.ParseSemantic(*r.Spec.Version)
+	if err != nil {
+		return append(allErrs, field.Invalid(specPath.Child("version"), *r.Spec.Version, err.Error()))
+	}
+	if v.LessThan(minSupportedVersion) {
+		return append(allErrs, field.Invalid(specPath.Child("version"), *r.Spec.Version,
+			fmt.Sprintf("Prometheus versions before %s are not supported", minSupportedVersion)))
+	}
+
+	for _, req := range compatibilityMatrix {
+		if !v.LessThan(req.minVersion) {
+			continue
+		}
+		for _, path := range req.fields(&r.Spec, specPath) {
+			allErrs = append(allErrs, field.Forbidden(path,
+				fmt.Sprintf("requires Prometheus %s or later, version is %s", req.minVersion, v)))
+		}
+	}
+	for i, sc := range r.Spec.ScrapeConfigs {
+		allErrs = append(allErrs, validateScrapeConfigVersion(v, sc, specPath.Child("scrape_configs").Index(i))...)
+	}
+
+	if r.Spec.Web != nil && r.Spec.Thanos != nil && r.Spec.Thanos.Version != nil {
+		tv, err := version.ParseSemantic(*r.Spec.Thanos.Version)
+		if err == nil && tv.LessThan(thanosWebMinVersion) {
+			allErrs = append(allErrs, field.Forbidden(specPath.Child("thanos", "version"),
+				fmt.Sprintf("securing the web endpoint requires Thanos %s or later, version is %s", thanosWebMinVersion, tv)))
+		}
+	}
+	return allErrs
+}
+
+// validateScrapeConfigVersion checks the fields set in a scrape config are
+// supported by the Prometheus version.
+func validateScrapeConfigVersion(v *version.Version, sc *ScrapeConfigSpec, scPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	for _, req := range scrapeConfigCompatibility {
+		if !v.LessThan(req.minVersion) {
+			continue
+		}
+		for _, path := range req.fields(sc, scPath) {
+			allErrs = append(allErrs, field.Forbidden(path,
+				fmt.Sprintf("requires Prometheus %s or later, version is %s", req.minVersion, v)))
+		}
+	}
+	return allErrs
+}
+
+// validateVersionUpgrade refuses downgrades to a previous major version, whose
+// storage format may not be readable, unless they are forced through the
+// ForceVersionDowngradeAnnotation.
+func (r *Prometheus) validateVersionUpgrade(old *Prometheus) *field.Error {
+	if r.Spec.Version == nil || old.Spec.Version == nil || r.Annotations[ForceVersionDowngradeAnnotation] == "true" {
+		return nil
+	}
+	newVersion, err := version.ParseSemantic(*r.Spec.Version)
+	if err != nil {
+		return nil
+	}
+	oldVersion, err := version.ParseSemantic(*old.Spec.Version)
+	if err != nil {
+		return nil
+	}
+	if newVersion.Major() < oldVersion.Major() {
+		return field.Forbidden(field.NewPath("spec", "version"),
+			fmt.Sprintf("downgrading from %s to %s crosses a major version, set the %s annotation to force it",
+				oldVersion, newVersion, ForceVersionDowngradeAnnotation))
+	}
+	return nil
+}
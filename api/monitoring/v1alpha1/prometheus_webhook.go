@@ -0,0 +1,346 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"fmt"
+	"net"
+	"strings"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1validation "k8s.io/apimachinery/pkg/apis/meta/v1/validation"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/apimachinery/pkg/util/version"
+	ctrl "sigs.k8s.io/controller-runtime"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+)
+
+// log is for logging in this package.
+var prometheuslog = logf.Log.WithName("prometheus-resource")
+
+func (r *Prometheus) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(r).
+		Complete()
+}
+
+//+kubebuilder:webhook:path=/validate-monitoring-mroque-v1alpha1-prometheus,mutating=false,failurePolicy=fail,sideEffects=None,groups=monitoring.mroque,resources=prometheuses,verbs=create;update;delete,versions=v1alpha1,name=vprometheus.kb.io,admissionReviewVersions=v1
+
+var _ webhook.Validator = &Prometheus{}
+
+// ValidateCreate implements webhook.Validator so a webhook will be registered for the type
+func (r *Prometheus) ValidateCreate() error {
+	prometheuslog.Info("validate create", "name", r.Name)
+
+	return r.validatePrometheus()
+}
+
+// ValidateUpdate implements webhook.Validator so a webhook will be registered for the type
+func (r *Prometheus) ValidateUpdate(old runtime.Object) error {
+	prometheuslog.Info("validate update", "name", r.Name)
+
+	if err := r.validateVersionUpgrade(old.(*Prometheus)); err != nil {
+		return apierrors.NewInvalid(schema.GroupKind{Group: GroupVersion.Group, Kind: "Prometheus"}, r.Name, field.ErrorList{err})
+	}
+	return r.validatePrometheus()
+}
+
+// ValidateDelete implements webhook.Validator so a webhook will be registered for the type.
+// Deletion is refused while the deletion protection annotation is set to "true".
+func (r *Prometheus) ValidateDelete() error {
+	prometheuslog.Info("validate delete", "name", r.Name)
+
+	if r.Annotations[DeletionProtectionAnnotation] == "true" {
+		return fmt.Errorf("prometheus %s/%s is protected against deletion, remove the %s annotation first",
+			r.Namespace, r.Name, DeletionProtectionAnnotation)
+	}
+	return nil
+}
+
+// validatePrometheus checks the constraints between fields that can't be
+// expressed in the OpenAPI schema of the CRD.
+func (r *Prometheus) validatePrometheus() error {
+	var allErrs field.ErrorList
+	specPath := field.NewPath("spec")
+
+	allErrs = append(allErrs, r.validateVersion(specPath)...)
+
+	if r.AgentMode() && r.Spec.Thanos != nil {
+		allErrs = append(allErrs, field.Forbidden(specPath.Child("thanos"),
+			"the Thanos sidecar can't be used in agent mode"))
+	}
+
+	if r.Spec.TSDB != nil {
+		allErrs = append(allErrs, r.validateTSDB(specPath.Child("tsdb"))...)
+	}
+
+	if r.Spec.Web != nil && r.Spec.Web.TLS != nil {
+		tls := r.Spec.Web.TLS
+		if (tls.Cert == nil) != (tls.Key == nil) {
+			allErrs = append(allErrs, field.Required(specPath.Child("web", "tls"),
+				"cert and key must be set together"))
+		}
+	}
+
+	// The Service behind the Ingress selects the pods of every shard, each
+	// one holding only part of the series
+	if r.Spec.Ingress != nil && r.Spec.Shards != nil && *r.Spec.Shards > 1 {
+		allErrs = append(allErrs, field.Forbidden(specPath.Child("ingress"),
+			"an Ingress can't be used with more than one shard"))
+	}
+
+	if r.Spec.NetworkPolicy != nil {
+		for i, cidr := range r.Spec.NetworkPolicy.APIServerCIDRs {
+			if _, _, err := net.ParseCIDR(cidr); err != nil {
+				allErrs = append(allErrs, field.Invalid(specPath.Child("networkPolicy", "apiServerCIDRs").Index(i), cidr, err.Error()))
+			}
+		}
+	}
+
+	allErrs = append(allErrs, r.validatePodOverrides(specPath)...)
+
+	allErrs = append(allErrs, metav1validation.ValidateLabelSelector(r.Spec.ScrapeConfigSelector, specPath.Child("scrapeConfigSelector"))...)
+	allErrs = append(allErrs, metav1validation.ValidateLabelSelector(r.Spec.ScrapeConfigNamespaceSelector, specPath.Child("scrapeConfigNamespaceSelector"))...)
+	allErrs = append(allErrs, metav1validation.ValidateLabelSelector(r.Spec.ProbeSelector, specPath.Child("probeSelector"))...)
+	allErrs = append(allErrs, metav1validation.ValidateLabelSelector(r.Spec.ProbeNamespaceSelector, specPath.Child("probeNamespaceSelector"))...)
+
+	jobNames := map[string]bool{}
+	for _, preset := range r.Spec.Presets {
+		jobNames[string(preset)] = true
+	}
+	for i, sc := range r.Spec.ScrapeConfigs {
+		scPath := specPath.Child("scrape_configs").Index(i)
+		allErrs = append(allErrs, r.validateScrapeConfig(sc, scPath)...)
+		if sc.JobName == nil {
+			continue
+		}
+		if jobNames[*sc.JobName] {
+			allErrs = append(allErrs, field.Duplicate(scPath.Child("job_name"), *sc.JobName))
+		}
+		jobNames[*sc.JobName] = true
+	}
+
+	if r.Spec.DaemonSet {
+		if !r.AgentMode() {
+			allErrs = append(allErrs, field.Invalid(specPath.Child("daemonSet"), r.Spec.DaemonSet,
+				"running as a DaemonSet is only supported in agent mode"))
+		}
+		if r.Spec.Shards != nil && *r.Spec.Shards > 1 {
+			allErrs = append(allErrs, field.Invalid(specPath.Child("shards"), *r.Spec.Shards,
+				"sharding can't be used when running as a DaemonSet"))
+		}
+		if r.Spec.Replicas != nil && *r.Spec.Replicas > 1 {
+			allErrs = append(allErrs, field.Invalid(specPath.Child("replicas"), *r.Spec.Replicas,
+				"replicas can't be used when running as a DaemonSet"))
+		}
+		if r.Spec.AdditionalScrapeConfigs != nil {
+			allErrs = append(allErrs, field.Forbidden(specPath.Child("additionalScrapeConfigs"),
+				"additional scrape configs can't be used when running as a DaemonSet"))
+		}
+		for i, preset := range r.Spec.Presets {
+			// They discover endpoints, which aren't tied to a node
+			if preset == PresetAPIServer || preset == PresetCoreDNS {
+				allErrs = append(allErrs, field.Forbidden(specPath.Child("presets").Index(i),
+					fmt.Sprintf("the %s preset can't be used when running as a DaemonSet", preset)))
+			}
+		}
+	}
+
+	if len(allErrs) == 0 {
+		return nil
+	}
+	return apierrors.NewInvalid(schema.GroupKind{Group: GroupVersion.Group, Kind: "Prometheus"}, r.Name, allErrs)
+}
+
+// validateScrapeConfig checks a scrape config is consistent and supported
+// by the way the Prometheus is deployed.
+func (r *Prometheus) validateScrapeConfig(sc *ScrapeConfigSpec, scPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+
+	for j, sd := range sc.DNSSDConfigs {
+		if sd.Type != nil && *sd.Type != "SRV" && sd.Port == nil {
+			allErrs = append(allErrs, field.Required(scPath.Child("dns_sd_configs").Index(j).Child("port"),
+				fmt.Sprintf("required for %s records", *sd.Type)))
+		}
+	}
+
+	if r.Spec.DaemonSet {
+		// Every agent would scrape the same targets
+		if len(sc.StaticConfigs) > 0 || len(sc.FileSDConfigs) > 0 || len(sc.DNSSDConfigs) > 0 || len(sc.HTTPSDConfigs) > 0 {
+			allErrs = append(allErrs, field.Forbidden(scPath,
+				"only kubernetes_sd_configs can be used when running as a DaemonSet"))
+		}
+		for j, sd := range sc.K8SSDConfigs {
+			if sd.Role != nil && *sd.Role != "pod" && *sd.Role != "node" {
+				allErrs = append(allErrs, field.NotSupported(
+					scPath.Child("kubernetes_sd_configs").Index(j).Child("role"),
+					*sd.Role, []string{"pod", "node"}))
+			}
+		}
+	}
+	return allErrs
+}
+
+// ValidateScrapeConfig checks a ScrapeConfig object can be added to the
+// configuration of the Prometheus. The operator leaves out the objects it
+// rejects.
+func (r *Prometheus) ValidateScrapeConfig(sc *ScrapeConfig) error {
+	specPath := field.NewPath("spec")
+	allErrs := r.validateScrapeConfig(&sc.Spec, specPath)
+
+	// The ConfigMaps are mounted from the namespace of the Prometheus, which
+	// would have to be restarted whenever a ScrapeConfig changes
+	if len(sc.Spec.FileSDConfigs) > 0 {
+		allErrs = append(allErrs, field.Forbidden(specPath.Child("file_sd_configs"),
+			"only supported in the scrape_configs of the Prometheus"))
+	}
+	// The files of the Prometheus pods, such as the token of its service
+	// account, must not be sent to targets chosen by other teams
+	if sc.Spec.BearerTokenFile != nil {
+		allErrs = append(allErrs, field.Forbidden(specPath.Child("bearer_token_file"),
+			"only supported in the scrape_configs of the Prometheus"))
+	}
+	if sc.Spec.TLSConfig != nil && sc.Spec.TLSConfig.CAFile != nil {
+		allErrs = append(allErrs, field.Forbidden(specPath.Child("tls_config", "ca_file"),
+			"only supported in the scrape_configs of the Prometheus"))
+	}
+	if r.Spec.Version != nil {
+		if v, err := version.ParseSemantic(*r.Spec.Version); err == nil {
+			allErrs = append(allErrs, validateScrapeConfigVersion(v, &sc.Spec, specPath)...)
+		}
+	}
+	return allErrs.ToAggregate()
+}
+
+// ValidateProbe checks a Probe object can be added to the configuration of
+// the Prometheus. The operator leaves out the objects it rejects.
+func (r *Prometheus) ValidateProbe(probe *Probe) error {
+	var allErrs field.ErrorList
+	specPath := field.NewPath("spec")
+
+	if r.Spec.DaemonSet {
+		// Every agent would check the same targets
+		allErrs = append(allErrs, field.Forbidden(specPath, "Probes can't be used when running as a DaemonSet"))
+	}
+
+	modulePath := specPath.Child("module")
+	module := probe.Spec.Module
+	probers := []struct {
+		name string
+		set  bool
+	}{
+		{"http", module.HTTP != nil},
+		{"tcp", module.TCP != nil},
+		{"icmp", module.ICMP != nil},
+	}
+	for _, p := range probers {
+		if p.set && p.name != module.Prober {
+			allErrs = append(allErrs, field.Forbidden(modulePath.Child(p.name),
+				fmt.Sprintf("can't be used with the %s prober", module.Prober)))
+		}
+	}
+	return allErrs.ToAggregate()
+}
+
+// validateTSDB checks the TSDB settings are supported by the Prometheus mode.
+func (r *Prometheus) validateTSDB(tsdbPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	tsdb := r.Spec.TSDB
+
+	// The agent only keeps a WAL, it doesn't have blocks
+	if r.AgentMode() {
+		blockFields := []struct {
+			name string
+			set  bool
+		}{
+			{"retentionTime", tsdb.RetentionTime != nil},
+			{"retentionSize", tsdb.RetentionSize != nil},
+			{"outOfOrderTimeWindow", tsdb.OutOfOrderTimeWindow != nil},
+			{"minBlockDuration", tsdb.MinBlockDuration != nil},
+			{"maxBlockDuration", tsdb.MaxBlockDuration != nil},
+		}
+		for _, f := range blockFields {
+			if f.set {
+				allErrs = append(allErrs, field.Forbidden(tsdbPath.Child(f.name), "not supported in agent mode"))
+			}
+		}
+	}
+
+	if r.Spec.Thanos != nil {
+		if tsdb.MinBlockDuration != nil {
+			allErrs = append(allErrs, field.Forbidden(tsdbPath.Child("minBlockDuration"),
+				"the block durations are set by the operator when the Thanos sidecar is used"))
+		}
+		if tsdb.MaxBlockDuration != nil {
+			allErrs = append(allErrs, field.Forbidden(tsdbPath.Child("maxBlockDuration"),
+				"the block durations are set by the operator when the Thanos sidecar is used"))
+		}
+	}
+	return allErrs
+}
+
+// managedFlags are the Prometheus flags set by the operator from the spec,
+// they can't be passed as additional arguments.
+var managedFlags = map[string]bool{
+	"config.file":                     true,
+	"web.enable-lifecycle":            true,
+	"web.listen-address":              true,
+	"web.config.file":                 true,
+	"web.external-url":                true,
+	"web.route-prefix":                true,
+	"agent":                           true,
+	"storage.tsdb.path":               true,
+	"storage.agent.path":              true,
+	"storage.tsdb.retention":          true,
+	"storage.tsdb.retention.time":     true,
+	"storage.tsdb.retention.size":     true,
+	"storage.tsdb.wal-compression":    true,
+	"storage.agent.wal-compression":   true,
+	"storage.tsdb.min-block-duration": true,
+	"storage.tsdb.max-block-duration": true,
+}
+
+// validatePodOverrides checks the additional arguments don't override the
+// flags managed by the operator, and that the Prometheus container isn't
+// given its arguments through containers.
+func (r *Prometheus) validatePodOverrides(specPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+
+	for i, arg := range r.Spec.AdditionalArgs {
+		argPath := specPath.Child("additionalArgs").Index(i)
+		if !strings.HasPrefix(arg, "-") {
+			allErrs = append(allErrs, field.Invalid(argPath, arg, "must be a flag starting with -"))
+			continue
+		}
+		name := strings.SplitN(strings.TrimLeft(arg, "-"), "=", 2)[0]
+		if managedFlags[name] || managedFlags[strings.TrimPrefix(name, "no-")] {
+			allErrs = append(allErrs, field.Forbidden(argPath,
+				fmt.Sprintf("the --%s flag is managed by the operator", name)))
+		}
+	}
+
+	for i, c := range r.Spec.Containers {
+		if c.Name == "prometheus" && (len(c.Args) > 0 || len(c.Command) > 0) {
+			allErrs = append(allErrs, field.Forbidden(specPath.Child("containers").Index(i).Child("args"),
+				"the arguments of the prometheus container are set through additionalArgs"))
+		}
+	}
+	return allErrs
+}
@@ -0,0 +1,125 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ = Describe("Prometheus webhook", func() {
+	newPrometheus := func(name string, annotations map[string]string) *Prometheus {
+		version := "2.40.0"
+		return &Prometheus{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        name,
+				Namespace:   "default",
+				Annotations: annotations,
+			},
+			Spec: PrometheusSpec{Version: &version},
+		}
+	}
+
+	Context("ValidateDelete", func() {
+		It("allows deleting an unprotected Prometheus", func() {
+			Expect(newPrometheus("unprotected", nil).ValidateDelete()).To(Succeed())
+		})
+
+		It("allows deleting a Prometheus whose protection is disabled", func() {
+			prometheus := newPrometheus("disabled", map[string]string{DeletionProtectionAnnotation: "false"})
+			Expect(prometheus.ValidateDelete()).To(Succeed())
+		})
+
+		It("refuses deleting a protected Prometheus", func() {
+			prometheus := newPrometheus("protected", map[string]string{DeletionProtectionAnnotation: "true"})
+			Expect(prometheus.ValidateDelete()).To(MatchError(ContainSubstring(DeletionProtectionAnnotation)))
+		})
+	})
+
+	Context("ValidateCreate", func() {
+		It("refuses an Ingress in front of several shards", func() {
+			prometheus := newPrometheus("sharded-ingress", nil)
+			shards := int32(2)
+			prometheus.Spec.Shards = &shards
+			prometheus.Spec.Ingress = &IngressSpec{Host: "prometheus.example.com"}
+			Expect(prometheus.ValidateCreate()).To(MatchError(ContainSubstring("spec.ingress")))
+
+			shards = 1
+			Expect(prometheus.ValidateCreate()).To(Succeed())
+		})
+
+		It("refuses additional scrape configs in a DaemonSet", func() {
+			prometheus := newPrometheus("daemonset-raw", nil)
+			mode := ModeAgent
+			prometheus.Spec.Mode = &mode
+			prometheus.Spec.DaemonSet = true
+			Expect(prometheus.ValidateCreate()).To(Succeed())
+
+			prometheus.Spec.AdditionalScrapeConfigs = &corev1.SecretKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: "raw"},
+				Key:                  "scrape.yml",
+			}
+			Expect(prometheus.ValidateCreate()).To(MatchError(ContainSubstring("spec.additionalScrapeConfigs")))
+		})
+	})
+
+	Context("ValidateCreate of the TSDB settings", func() {
+		It("refuses settings unsupported by the version", func() {
+			prometheus := newPrometheus("old-tsdb", nil)
+			window := "1h"
+			prometheus.Spec.TSDB = &TSDBSpec{OutOfOrderTimeWindow: &window}
+			Expect(prometheus.ValidateCreate()).To(Succeed())
+
+			version := "2.38.0"
+			prometheus.Spec.Version = &version
+			Expect(prometheus.ValidateCreate()).To(MatchError(ContainSubstring("spec.tsdb.outOfOrderTimeWindow")))
+		})
+
+		It("refuses block settings in agent mode", func() {
+			prometheus := newPrometheus("agent-tsdb", nil)
+			mode := ModeAgent
+			retention := "30d"
+			prometheus.Spec.Mode = &mode
+			prometheus.Spec.TSDB = &TSDBSpec{RetentionTime: &retention}
+			Expect(prometheus.ValidateCreate()).To(MatchError(ContainSubstring("spec.tsdb.retentionTime")))
+		})
+
+		It("refuses block durations with the Thanos sidecar", func() {
+			prometheus := newPrometheus("thanos-tsdb", nil)
+			version, duration := "0.30.0", "1h"
+			prometheus.Spec.Thanos = &ThanosSpec{Version: &version}
+			prometheus.Spec.TSDB = &TSDBSpec{MinBlockDuration: &duration}
+			Expect(prometheus.ValidateCreate()).To(MatchError(ContainSubstring("spec.tsdb.minBlockDuration")))
+		})
+	})
+
+	Context("when deleting through the API server", func() {
+		It("keeps a protected Prometheus until the annotation is removed", func() {
+			prometheus := newPrometheus("protected-api", map[string]string{DeletionProtectionAnnotation: "true"})
+			Expect(k8sClient.Create(ctx, prometheus)).To(Succeed())
+
+			Expect(k8sClient.Delete(ctx, prometheus)).NotTo(Succeed())
+
+			delete(prometheus.Annotations, DeletionProtectionAnnotation)
+			Expect(k8sClient.Update(ctx, prometheus)).To(Succeed())
+			Expect(k8sClient.Delete(ctx, prometheus)).To(Succeed())
+		})
+	})
+})
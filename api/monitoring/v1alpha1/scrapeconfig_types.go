@@ -0,0 +1,63 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ScrapeConfig defines a scrape job owned outside of the Prometheus object,
+// for instance by the team running the targets. It is added to the
+// configuration of the Prometheuses whose selectors match it, under the
+// job name <namespace>/<name>/<job_name>.
+// +genclient
+// +genclient:noStatus
+// +k8s:openapi-gen=true
+// +kubebuilder:object:root=true
+type ScrapeConfig struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	// Specification of the scrape job.
+	Spec ScrapeConfigSpec `json:"spec"`
+}
+
+// ScrapeConfigList is a list of ScrapeConfigs.
+// +k8s:openapi-gen=true
+// +kubebuilder:object:root=true
+type ScrapeConfigList struct {
+	metav1.TypeMeta `json:",inline"`
+	// Standard list metadata
+	// More info: https://github.com/kubernetes/community/blob/master/contributors/devel/sig-architecture/api-conventions.md#metadata
+	metav1.ListMeta `json:"metadata,omitempty"`
+	// List of ScrapeConfigs
+	Items []ScrapeConfig `json:"items"`
+}
+
+// JobName returns the job name of the ScrapeConfig in the Prometheus
+// configuration, prefixed with its namespace and name so that jobs of
+// different objects can't collide.
+func (s *ScrapeConfig) JobName() string {
+	jobName := ""
+	if s.Spec.JobName != nil {
+		jobName = *s.Spec.JobName
+	}
+	return s.Namespace + "/" + s.Name + "/" + jobName
+}
+
+func init() {
+	SchemeBuilder.Register(&ScrapeConfig{}, &ScrapeConfigList{})
+}
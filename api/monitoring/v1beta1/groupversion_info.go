@@ -14,10 +14,10 @@ See the License for the specific language governing permissions and
 limitations under the License.
 */
 
-// Package v1alpha1 contains API Schema definitions for the monitoring v1alpha1 API group
-//+kubebuilder:object:generate=true
-//+groupName=monitoring.mroque
-package v1alpha1
+// Package v1beta1 contains API Schema definitions for the monitoring v1beta1 API group
+// +kubebuilder:object:generate=true
+// +groupName=monitoring.mroque
+package v1beta1
 
 import (
 	"k8s.io/apimachinery/pkg/runtime/schema"
@@ -26,7 +26,7 @@ import (
 
 var (
 	// GroupVersion is group version used to register these objects
-	GroupVersion = schema.GroupVersion{Group: "monitoring.mroque", Version: "v1alpha1"}
+	GroupVersion = schema.GroupVersion{Group: "monitoring.mroque", Version: "v1beta1"}
 
 	// SchemeBuilder is used to add go types to the GroupVersionKind scheme
 	SchemeBuilder = &scheme.Builder{GroupVersion: GroupVersion}
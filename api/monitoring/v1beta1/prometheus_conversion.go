@@ -0,0 +1,405 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"sigs.k8s.io/controller-runtime/pkg/conversion"
+
+	"github.com/marieroque/best-prometheus-operator-in-the-world/api/monitoring/v1alpha1"
+)
+
+// The types of v1beta1 carry the same fields as the ones of v1alpha1, the
+// types without nested API types are converted directly. The required
+// fields, which are pointers in v1alpha1, are always set once converted to
+// v1alpha1.
+
+// ConvertTo converts this Prometheus to the hub version (v1alpha1).
+func (src *Prometheus) ConvertTo(dstRaw conversion.Hub) error {
+	dst := dstRaw.(*v1alpha1.Prometheus)
+	dst.ObjectMeta = src.ObjectMeta
+	dst.Spec = specToHub(&src.Spec)
+	dst.Status = statusToHub(&src.Status)
+	return nil
+}
+
+// ConvertFrom converts from the hub version (v1alpha1) to this version.
+func (dst *Prometheus) ConvertFrom(srcRaw conversion.Hub) error {
+	src := srcRaw.(*v1alpha1.Prometheus)
+	dst.ObjectMeta = src.ObjectMeta
+	dst.Spec = specFromHub(&src.Spec)
+	dst.Status = statusFromHub(&src.Status)
+	return nil
+}
+
+func specToHub(in *PrometheusSpec) v1alpha1.PrometheusSpec {
+	out := v1alpha1.PrometheusSpec{
+		Version:                       stringToHub(in.Version),
+		Image:                         in.Image,
+		ImageDigest:                   in.ImageDigest,
+		ImagePullPolicy:               in.ImagePullPolicy,
+		ImagePullSecrets:              in.ImagePullSecrets,
+		Paused:                        in.Paused,
+		Shards:                        in.Shards,
+		Mode:                          in.Mode,
+		DaemonSet:                     in.DaemonSet,
+		TSDB:                          (*v1alpha1.TSDBSpec)(in.TSDB),
+		Ingress:                       (*v1alpha1.IngressSpec)(in.Ingress),
+		Replicas:                      in.Replicas,
+		NetworkPolicy:                 (*v1alpha1.NetworkPolicySpec)(in.NetworkPolicy),
+		Containers:                    in.Containers,
+		InitContainers:                in.InitContainers,
+		Volumes:                       in.Volumes,
+		VolumeMounts:                  in.VolumeMounts,
+		Env:                           in.Env,
+		AdditionalArgs:                in.AdditionalArgs,
+		AdditionalScrapeConfigs:       in.AdditionalScrapeConfigs,
+		ScrapeConfigSelector:          in.ScrapeConfigSelector,
+		ScrapeConfigNamespaceSelector: in.ScrapeConfigNamespaceSelector,
+		ProbeSelector:                 in.ProbeSelector,
+		ProbeNamespaceSelector:        in.ProbeNamespaceSelector,
+	}
+	if in.ScrapeConfigs != nil {
+		out.ScrapeConfigs = make([]*v1alpha1.ScrapeConfigSpec, 0, len(in.ScrapeConfigs))
+		for i := range in.ScrapeConfigs {
+			out.ScrapeConfigs = append(out.ScrapeConfigs, scrapeConfigToHub(&in.ScrapeConfigs[i]))
+		}
+	}
+	if in.Presets != nil {
+		out.Presets = make([]v1alpha1.ScrapePreset, 0, len(in.Presets))
+		for _, preset := range in.Presets {
+			out.Presets = append(out.Presets, v1alpha1.ScrapePreset(preset))
+		}
+	}
+	if in.RemoteWrite != nil {
+		out.RemoteWrite = make([]*v1alpha1.RemoteWriteConfig, 0, len(in.RemoteWrite))
+		for _, rw := range in.RemoteWrite {
+			out.RemoteWrite = append(out.RemoteWrite, &v1alpha1.RemoteWriteConfig{
+				URL:                 stringToHub(rw.URL),
+				Name:                rw.Name,
+				RemoteTimeout:       rw.RemoteTimeout,
+				WriteRelabelConfigs: relabelConfigsToHub(rw.WriteRelabelConfigs),
+			})
+		}
+	}
+	if in.Thanos != nil {
+		out.Thanos = &v1alpha1.ThanosSpec{
+			Version:             stringToHub(in.Thanos.Version),
+			ObjectStorageConfig: in.Thanos.ObjectStorageConfig,
+		}
+	}
+	if in.Web != nil {
+		out.Web = &v1alpha1.WebSpec{
+			TLS:            (*v1alpha1.WebTLSConfig)(in.Web.TLS),
+			BasicAuthUsers: in.Web.BasicAuthUsers,
+		}
+	}
+	if in.Probes != nil {
+		out.Probes = &v1alpha1.ProbesSpec{
+			Liveness:  (*v1alpha1.ProbeConfig)(in.Probes.Liveness),
+			Readiness: (*v1alpha1.ProbeConfig)(in.Probes.Readiness),
+			Startup:   (*v1alpha1.ProbeConfig)(in.Probes.Startup),
+		}
+	}
+	return out
+}
+
+func specFromHub(in *v1alpha1.PrometheusSpec) PrometheusSpec {
+	out := PrometheusSpec{
+		Version:                       stringFromHub(in.Version),
+		Image:                         in.Image,
+		ImageDigest:                   in.ImageDigest,
+		ImagePullPolicy:               in.ImagePullPolicy,
+		ImagePullSecrets:              in.ImagePullSecrets,
+		Paused:                        in.Paused,
+		Shards:                        in.Shards,
+		Mode:                          in.Mode,
+		DaemonSet:                     in.DaemonSet,
+		TSDB:                          (*TSDBSpec)(in.TSDB),
+		Ingress:                       (*IngressSpec)(in.Ingress),
+		Replicas:                      in.Replicas,
+		NetworkPolicy:                 (*NetworkPolicySpec)(in.NetworkPolicy),
+		Containers:                    in.Containers,
+		InitContainers:                in.InitContainers,
+		Volumes:                       in.Volumes,
+		VolumeMounts:                  in.VolumeMounts,
+		Env:                           in.Env,
+		AdditionalArgs:                in.AdditionalArgs,
+		AdditionalScrapeConfigs:       in.AdditionalScrapeConfigs,
+		ScrapeConfigSelector:          in.ScrapeConfigSelector,
+		ScrapeConfigNamespaceSelector: in.ScrapeConfigNamespaceSelector,
+		ProbeSelector:                 in.ProbeSelector,
+		ProbeNamespaceSelector:        in.ProbeNamespaceSelector,
+	}
+	if in.ScrapeConfigs != nil {
+		out.ScrapeConfigs = make([]ScrapeConfigSpec, 0, len(in.ScrapeConfigs))
+		for _, sc := range in.ScrapeConfigs {
+			out.ScrapeConfigs = append(out.ScrapeConfigs, scrapeConfigFromHub(sc))
+		}
+	}
+	if in.Presets != nil {
+		out.Presets = make([]ScrapePreset, 0, len(in.Presets))
+		for _, preset := range in.Presets {
+			out.Presets = append(out.Presets, ScrapePreset(preset))
+		}
+	}
+	if in.RemoteWrite != nil {
+		out.RemoteWrite = make([]RemoteWriteConfig, 0, len(in.RemoteWrite))
+		for _, rw := range in.RemoteWrite {
+			out.RemoteWrite = append(out.RemoteWrite, RemoteWriteConfig{
+				URL:                 stringFromHub(rw.URL),
+				Name:                rw.Name,
+				RemoteTimeout:       rw.RemoteTimeout,
+				WriteRelabelConfigs: relabelConfigsFromHub(rw.WriteRelabelConfigs),
+			})
+		}
+	}
+	if in.Thanos != nil {
+		out.Thanos = &ThanosSpec{
+			Version:             stringFromHub(in.Thanos.Version),
+			ObjectStorageConfig: in.Thanos.ObjectStorageConfig,
+		}
+	}
+	if in.Web != nil {
+		out.Web = &WebSpec{
+			TLS:            (*WebTLSConfig)(in.Web.TLS),
+			BasicAuthUsers: in.Web.BasicAuthUsers,
+		}
+	}
+	if in.Probes != nil {
+		out.Probes = &ProbesSpec{
+			Liveness:  (*ProbeConfig)(in.Probes.Liveness),
+			Readiness: (*ProbeConfig)(in.Probes.Readiness),
+			Startup:   (*ProbeConfig)(in.Probes.Startup),
+		}
+	}
+	return out
+}
+
+func scrapeConfigToHub(in *ScrapeConfigSpec) *v1alpha1.ScrapeConfigSpec {
+	out := &v1alpha1.ScrapeConfigSpec{
+		JobName:         stringToHub(in.JobName),
+		MetricsPath:     in.MetricsPath,
+		Scheme:          in.Scheme,
+		TLSConfig:       (*v1alpha1.TLSConfig)(in.TLSConfig),
+		BearerTokenFile: in.BearerTokenFile,
+		RelabelConfigs:  relabelConfigsToHub(in.RelabelConfigs),
+	}
+	if in.K8SSDConfigs != nil {
+		out.K8SSDConfigs = make([]*v1alpha1.K8SSDConfig, 0, len(in.K8SSDConfigs))
+		for _, sd := range in.K8SSDConfigs {
+			k8sSD := &v1alpha1.K8SSDConfig{
+				Role:       stringToHub(sd.Role),
+				Namespaces: (*v1alpha1.K8SSDNamespaces)(sd.Namespaces),
+			}
+			if sd.Selectors != nil {
+				k8sSD.Selectors = make([]*v1alpha1.K8SSDSelector, 0, len(sd.Selectors))
+				for _, selector := range sd.Selectors {
+					k8sSD.Selectors = append(k8sSD.Selectors, &v1alpha1.K8SSDSelector{
+						Role:  stringToHub(selector.Role),
+						Label: selector.Label,
+						Field: selector.Field,
+					})
+				}
+			}
+			out.K8SSDConfigs = append(out.K8SSDConfigs, k8sSD)
+		}
+	}
+	if in.StaticConfigs != nil {
+		out.StaticConfigs = make([]*v1alpha1.StaticConfig, 0, len(in.StaticConfigs))
+		for i := range in.StaticConfigs {
+			out.StaticConfigs = append(out.StaticConfigs, (*v1alpha1.StaticConfig)(&in.StaticConfigs[i]))
+		}
+	}
+	if in.FileSDConfigs != nil {
+		out.FileSDConfigs = make([]*v1alpha1.FileSDConfig, 0, len(in.FileSDConfigs))
+		for i := range in.FileSDConfigs {
+			out.FileSDConfigs = append(out.FileSDConfigs, (*v1alpha1.FileSDConfig)(&in.FileSDConfigs[i]))
+		}
+	}
+	if in.DNSSDConfigs != nil {
+		out.DNSSDConfigs = make([]*v1alpha1.DNSSDConfig, 0, len(in.DNSSDConfigs))
+		for i := range in.DNSSDConfigs {
+			out.DNSSDConfigs = append(out.DNSSDConfigs, (*v1alpha1.DNSSDConfig)(&in.DNSSDConfigs[i]))
+		}
+	}
+	if in.HTTPSDConfigs != nil {
+		out.HTTPSDConfigs = make([]*v1alpha1.HTTPSDConfig, 0, len(in.HTTPSDConfigs))
+		for i := range in.HTTPSDConfigs {
+			out.HTTPSDConfigs = append(out.HTTPSDConfigs, (*v1alpha1.HTTPSDConfig)(&in.HTTPSDConfigs[i]))
+		}
+	}
+	return out
+}
+
+func scrapeConfigFromHub(in *v1alpha1.ScrapeConfigSpec) ScrapeConfigSpec {
+	out := ScrapeConfigSpec{
+		JobName:         stringFromHub(in.JobName),
+		MetricsPath:     in.MetricsPath,
+		Scheme:          in.Scheme,
+		TLSConfig:       (*TLSConfig)(in.TLSConfig),
+		BearerTokenFile: in.BearerTokenFile,
+		RelabelConfigs:  relabelConfigsFromHub(in.RelabelConfigs),
+	}
+	if in.K8SSDConfigs != nil {
+		out.K8SSDConfigs = make([]K8SSDConfig, 0, len(in.K8SSDConfigs))
+		for _, sd := range in.K8SSDConfigs {
+			k8sSD := K8SSDConfig{
+				Role:       stringFromHub(sd.Role),
+				Namespaces: (*K8SSDNamespaces)(sd.Namespaces),
+			}
+			if sd.Selectors != nil {
+				k8sSD.Selectors = make([]K8SSDSelector, 0, len(sd.Selectors))
+				for _, selector := range sd.Selectors {
+					k8sSD.Selectors = append(k8sSD.Selectors, K8SSDSelector{
+						Role:  stringFromHub(selector.Role),
+						Label: selector.Label,
+						Field: selector.Field,
+					})
+				}
+			}
+			out.K8SSDConfigs = append(out.K8SSDConfigs, k8sSD)
+		}
+	}
+	if in.StaticConfigs != nil {
+		out.StaticConfigs = make([]StaticConfig, 0, len(in.StaticConfigs))
+		for _, sc := range in.StaticConfigs {
+			out.StaticConfigs = append(out.StaticConfigs, StaticConfig(*sc))
+		}
+	}
+	if in.FileSDConfigs != nil {
+		out.FileSDConfigs = make([]FileSDConfig, 0, len(in.FileSDConfigs))
+		for _, sd := range in.FileSDConfigs {
+			out.FileSDConfigs = append(out.FileSDConfigs, FileSDConfig(*sd))
+		}
+	}
+	if in.DNSSDConfigs != nil {
+		out.DNSSDConfigs = make([]DNSSDConfig, 0, len(in.DNSSDConfigs))
+		for _, sd := range in.DNSSDConfigs {
+			out.DNSSDConfigs = append(out.DNSSDConfigs, DNSSDConfig(*sd))
+		}
+	}
+	if in.HTTPSDConfigs != nil {
+		out.HTTPSDConfigs = make([]HTTPSDConfig, 0, len(in.HTTPSDConfigs))
+		for _, sd := range in.HTTPSDConfigs {
+			out.HTTPSDConfigs = append(out.HTTPSDConfigs, HTTPSDConfig(*sd))
+		}
+	}
+	return out
+}
+
+func relabelConfigsToHub(in []RelabelConfig) []*v1alpha1.RelabelConfig {
+	if in == nil {
+		return nil
+	}
+	out := make([]*v1alpha1.RelabelConfig, 0, len(in))
+	for _, rc := range in {
+		relabel := v1alpha1.RelabelConfig(rc)
+		out = append(out, &relabel)
+	}
+	return out
+}
+
+func relabelConfigsFromHub(in []*v1alpha1.RelabelConfig) []RelabelConfig {
+	if in == nil {
+		return nil
+	}
+	out := make([]RelabelConfig, 0, len(in))
+	for _, rc := range in {
+		out = append(out, RelabelConfig(*rc))
+	}
+	return out
+}
+
+func statusToHub(in *PrometheusStatus) v1alpha1.PrometheusStatus {
+	out := v1alpha1.PrometheusStatus{
+		Conditions:    in.Conditions,
+		Version:       in.Version,
+		ScrapeConfigs: selectionToHub(in.ScrapeConfigs),
+		Probes:        selectionToHub(in.Probes),
+		Replicas:      in.Replicas,
+		ReadyReplicas: in.ReadyReplicas,
+		ScrapeJobs:    in.ScrapeJobs,
+	}
+	if in.Shards != nil {
+		out.Shards = make([]v1alpha1.ShardStatus, 0, len(in.Shards))
+		for _, shard := range in.Shards {
+			out.Shards = append(out.Shards, v1alpha1.ShardStatus(shard))
+		}
+	}
+	return out
+}
+
+func statusFromHub(in *v1alpha1.PrometheusStatus) PrometheusStatus {
+	out := PrometheusStatus{
+		Conditions:    in.Conditions,
+		Version:       in.Version,
+		ScrapeConfigs: selectionFromHub(in.ScrapeConfigs),
+		Probes:        selectionFromHub(in.Probes),
+		Replicas:      in.Replicas,
+		ReadyReplicas: in.ReadyReplicas,
+		ScrapeJobs:    in.ScrapeJobs,
+	}
+	if in.Shards != nil {
+		out.Shards = make([]ShardStatus, 0, len(in.Shards))
+		for _, shard := range in.Shards {
+			out.Shards = append(out.Shards, ShardStatus(shard))
+		}
+	}
+	return out
+}
+
+func selectionToHub(in *SelectedResources) *v1alpha1.SelectedResources {
+	if in == nil {
+		return nil
+	}
+	out := &v1alpha1.SelectedResources{Selected: in.Selected}
+	if in.Rejected != nil {
+		out.Rejected = make([]v1alpha1.RejectedResource, 0, len(in.Rejected))
+		for _, rejected := range in.Rejected {
+			out.Rejected = append(out.Rejected, v1alpha1.RejectedResource(rejected))
+		}
+	}
+	return out
+}
+
+func selectionFromHub(in *v1alpha1.SelectedResources) *SelectedResources {
+	if in == nil {
+		return nil
+	}
+	out := &SelectedResources{Selected: in.Selected}
+	if in.Rejected != nil {
+		out.Rejected = make([]RejectedResource, 0, len(in.Rejected))
+		for _, rejected := range in.Rejected {
+			out.Rejected = append(out.Rejected, RejectedResource(rejected))
+		}
+	}
+	return out
+}
+
+// stringToHub converts a required string to its v1alpha1 pointer.
+func stringToHub(s string) *string {
+	return &s
+}
+
+// stringFromHub converts a required v1alpha1 string, a missing one being
+// left empty so that it is rejected like in v1alpha1.
+func stringFromHub(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
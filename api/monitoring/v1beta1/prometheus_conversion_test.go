@@ -0,0 +1,133 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+
+	fuzz "github.com/google/gofuzz"
+	"k8s.io/apimachinery/pkg/api/apitesting/fuzzer"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	metafuzzer "k8s.io/apimachinery/pkg/apis/meta/fuzzer"
+	"k8s.io/apimachinery/pkg/runtime"
+	runtimeserializer "k8s.io/apimachinery/pkg/runtime/serializer"
+	"k8s.io/apimachinery/pkg/util/diff"
+
+	"github.com/marieroque/best-prometheus-operator-in-the-world/api/monitoring/v1alpha1"
+)
+
+const fuzzIterations = 1000
+
+func newFuzzer(t *testing.T) *fuzz.Fuzzer {
+	scheme := runtime.NewScheme()
+	if err := v1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	if err := AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	seed := time.Now().UnixNano()
+	t.Logf("fuzzer seed: %d", seed)
+	funcs := fuzzer.MergeFuzzerFuncs(metafuzzer.Funcs, hubFuzzerFuncs)
+	return fuzzer.FuzzerFor(funcs, rand.NewSource(seed), runtimeserializer.NewCodecFactory(scheme))
+}
+
+// hubFuzzerFuncs fill the required fields of the v1alpha1 types and the
+// elements of their lists, which the CRD schema doesn't let be null.
+func hubFuzzerFuncs(_ runtimeserializer.CodecFactory) []interface{} {
+	return []interface{}{
+		func(in *v1alpha1.PrometheusSpec, c fuzz.Continue) {
+			c.FuzzNoCustom(in)
+			in.Version = requiredString(in.Version, c)
+			if in.Thanos != nil {
+				in.Thanos.Version = requiredString(in.Thanos.Version, c)
+			}
+		},
+		func(in *v1alpha1.ScrapeConfigSpec, c fuzz.Continue) {
+			c.FuzzNoCustom(in)
+			in.JobName = requiredString(in.JobName, c)
+		},
+		func(in *v1alpha1.RemoteWriteConfig, c fuzz.Continue) {
+			c.FuzzNoCustom(in)
+			in.URL = requiredString(in.URL, c)
+		},
+		func(in *v1alpha1.K8SSDConfig, c fuzz.Continue) {
+			c.FuzzNoCustom(in)
+			in.Role = requiredString(in.Role, c)
+		},
+		func(in *v1alpha1.K8SSDSelector, c fuzz.Continue) {
+			c.FuzzNoCustom(in)
+			in.Role = requiredString(in.Role, c)
+		},
+		// The elements of the other lists are set by the functions above
+		func(in *v1alpha1.RelabelConfig, c fuzz.Continue) { c.FuzzNoCustom(in) },
+		func(in *v1alpha1.StaticConfig, c fuzz.Continue) { c.FuzzNoCustom(in) },
+		func(in *v1alpha1.FileSDConfig, c fuzz.Continue) { c.FuzzNoCustom(in) },
+		func(in *v1alpha1.DNSSDConfig, c fuzz.Continue) { c.FuzzNoCustom(in) },
+		func(in *v1alpha1.HTTPSDConfig, c fuzz.Continue) { c.FuzzNoCustom(in) },
+	}
+}
+
+func requiredString(s *string, c fuzz.Continue) *string {
+	if s == nil {
+		value := c.RandString()
+		return &value
+	}
+	return s
+}
+
+func TestPrometheusConversionHubRoundTrip(t *testing.T) {
+	f := newFuzzer(t)
+	for i := 0; i < fuzzIterations; i++ {
+		hub := &v1alpha1.Prometheus{}
+		f.Fuzz(hub)
+
+		spoke := &Prometheus{}
+		if err := spoke.ConvertFrom(hub.DeepCopy()); err != nil {
+			t.Fatalf("ConvertFrom: %v", err)
+		}
+		got := &v1alpha1.Prometheus{}
+		if err := spoke.ConvertTo(got); err != nil {
+			t.Fatalf("ConvertTo: %v", err)
+		}
+		if !apiequality.Semantic.DeepEqual(hub, got) {
+			t.Fatalf("v1alpha1 Prometheus changed by the round trip:\n%s", diff.ObjectReflectDiff(hub, got))
+		}
+	}
+}
+
+func TestPrometheusConversionSpokeRoundTrip(t *testing.T) {
+	f := newFuzzer(t)
+	for i := 0; i < fuzzIterations; i++ {
+		spoke := &Prometheus{}
+		f.Fuzz(spoke)
+
+		hub := &v1alpha1.Prometheus{}
+		if err := spoke.DeepCopy().ConvertTo(hub); err != nil {
+			t.Fatalf("ConvertTo: %v", err)
+		}
+		got := &Prometheus{}
+		if err := got.ConvertFrom(hub); err != nil {
+			t.Fatalf("ConvertFrom: %v", err)
+		}
+		if !apiequality.Semantic.DeepEqual(spoke, got) {
+			t.Fatalf("v1beta1 Prometheus changed by the round trip:\n%s", diff.ObjectReflectDiff(spoke, got))
+		}
+	}
+}
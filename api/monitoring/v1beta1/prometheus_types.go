@@ -0,0 +1,610 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// EDIT THIS FILE!  THIS IS SCAFFOLDING FOR YOU TO OWN!
+// NOTE: json tags are required.  Any new fields you add must have json tags for the fields to be serialized.
+
+// Prometheus defines a Prometheus deployment.
+// +genclient
+// +k8s:openapi-gen=true
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:shortName=prom,categories=monitoring
+// +kubebuilder:printcolumn:name="Version",type=string,JSONPath=`.status.version`,description="Prometheus version running in the pods"
+// +kubebuilder:printcolumn:name="Desired",type=integer,JSONPath=`.status.replicas`,description="Desired number of pods"
+// +kubebuilder:printcolumn:name="Ready",type=integer,JSONPath=`.status.readyReplicas`,description="Number of ready pods"
+// +kubebuilder:printcolumn:name="Reconciled",type=string,JSONPath=`.status.conditions[?(@.type=="Reconciled")].status`,description="Whether the managed objects match the spec"
+// +kubebuilder:printcolumn:name="Jobs",type=integer,JSONPath=`.status.scrapeJobs`,description="Number of scrape jobs"
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+type Prometheus struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	// Specification of the desired behavior of the Prometheus cluster. More info:
+	// https://github.com/kubernetes/community/blob/master/contributors/devel/sig-architecture/api-conventions.md#spec-and-status
+	Spec PrometheusSpec `json:"spec"`
+	// Most recent observed status of the Prometheus cluster. Read-only.
+	// More info:
+	// https://github.com/kubernetes/community/blob/master/contributors/devel/sig-architecture/api-conventions.md#spec-and-status
+	Status PrometheusStatus `json:"status,omitempty"`
+}
+
+// PrometheusList is a list of Prometheuses.
+// +k8s:openapi-gen=true
+// +kubebuilder:object:root=true
+type PrometheusList struct {
+	metav1.TypeMeta `json:",inline"`
+	// Standard list metadata
+	// More info: https://github.com/kubernetes/community/blob/master/contributors/devel/sig-architecture/api-conventions.md#metadata
+	metav1.ListMeta `json:"metadata,omitempty"`
+	// List of Prometheuses
+	Items []Prometheus `json:"items"`
+}
+
+// PrometheusSpec is a specification of the desired behavior of the Prometheus cluster. More info:
+// https://github.com/kubernetes/community/blob/master/contributors/devel/sig-architecture/api-conventions.md#spec-and-status
+// +k8s:openapi-gen=true
+type PrometheusSpec struct {
+	// Prometheus image version deployed
+	// +kubebuilder:validation:Pattern=^[0-9]+\.[0-9]+\.[0-9]+$
+	Version string `json:"version"`
+	// Repository of the Prometheus image, for instance on a mirror registry.
+	// Defaults to the image configured on the operator. The image is tagged
+	// with the version unless a digest is given.
+	// +optional
+	Image *string `json:"image,omitempty"`
+	// Digest pinning the Prometheus image, it takes precedence over the tag
+	// derived from the version. The version must still match the image since
+	// it drives the features enabled by the operator.
+	// +kubebuilder:validation:Pattern=`^sha256:[a-f0-9]{64}$`
+	// +optional
+	ImageDigest *string `json:"imageDigest,omitempty"`
+	// Pull policy of the images of the Prometheus pods, including the
+	// containers of the overrides that don't set their own.
+	// +kubebuilder:validation:Enum=Always;Never;IfNotPresent
+	// +optional
+	ImagePullPolicy corev1.PullPolicy `json:"imagePullPolicy,omitempty"`
+	// Secrets used to pull the images of the Prometheus pods.
+	// +optional
+	ImagePullSecrets []corev1.LocalObjectReference `json:"imagePullSecrets,omitempty"`
+	ScrapeConfigs    []ScrapeConfigSpec            `json:"scrapeConfigs"`
+	// Built-in scrape configs added to the configuration, each under a job
+	// named after the preset. The kubelet, cadvisor and apiserver presets
+	// authenticate with the service account of the Prometheus pods. The
+	// presets other than annotated-pods need cluster-wide permissions, which
+	// the operator only grants when it watches every namespace. They are
+	// skipped otherwise, and reported in the PresetsSupported condition.
+	// +listType=set
+	// +optional
+	Presets []ScrapePreset `json:"presets,omitempty"`
+	// When a Prometheus is paused, the operator stops making changes to the
+	// objects it manages so that they can be edited by hand. Drift is
+	// corrected again once the field is cleared.
+	// +optional
+	Paused bool `json:"paused,omitempty"`
+	// Number of shards to distribute the scraped targets onto. Each shard
+	// runs its own Prometheus workload and only scrapes the targets whose
+	// address hashes to its index. Defaults to 1.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	Shards *int32 `json:"shards,omitempty"`
+	// Mode Prometheus runs in. In agent mode, Prometheus only scrapes targets
+	// and forwards the samples to the remote write endpoints, it doesn't
+	// evaluate rules nor serve queries. Defaults to server.
+	// +kubebuilder:validation:Enum=server;agent
+	// +optional
+	Mode *string `json:"mode,omitempty"`
+	// DaemonSet runs one Prometheus agent per node instead of a Deployment,
+	// each one only scraping the pods and the node it runs on. Only
+	// supported in agent mode and with pod or node kubernetes service
+	// discovery.
+	// +optional
+	DaemonSet bool `json:"daemonSet,omitempty"`
+	// Remote write endpoints the samples are sent to.
+	// +optional
+	RemoteWrite []RemoteWriteConfig `json:"remoteWrite,omitempty"`
+	// Thanos runs a Thanos sidecar next to Prometheus, exposing its data to
+	// Thanos Query through the gRPC port of the managed Service and uploading
+	// the TSDB blocks to an object storage. Not supported in agent mode.
+	// +optional
+	Thanos *ThanosSpec `json:"thanos,omitempty"`
+	// TSDB configures the storage of the scraped samples.
+	// +optional
+	TSDB *TSDBSpec `json:"tsdb,omitempty"`
+	// Web secures the Prometheus web endpoint with TLS and basic
+	// authentication.
+	// +optional
+	Web *WebSpec `json:"web,omitempty"`
+	// Ingress exposes the Prometheus web endpoint through an Ingress. The
+	// external URL and route prefix of Prometheus are derived from it. Not
+	// supported with more than one shard.
+	// +optional
+	Ingress *IngressSpec `json:"ingress,omitempty"`
+	// Probes tunes the probes of the Prometheus container. The startup probe
+	// gives 15 minutes to replay the WAL by default.
+	// +optional
+	Probes *ProbesSpec `json:"probes,omitempty"`
+	// Number of pods of each shard. A PodDisruptionBudget keeps all but one
+	// of them running during voluntary disruptions when greater than 1.
+	// Defaults to 1, not supported when running as a DaemonSet.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	Replicas *int32 `json:"replicas,omitempty"`
+	// NetworkPolicy restricts the traffic of the Prometheus pods.
+	// +optional
+	NetworkPolicy *NetworkPolicySpec `json:"networkPolicy,omitempty"`
+	// Containers added to the Prometheus pods. A container named like one
+	// generated by the operator (prometheus, config-reloader,
+	// thanos-sidecar) is strategically merged into it instead.
+	// +optional
+	Containers []corev1.Container `json:"containers,omitempty"`
+	// Init containers added to the Prometheus pods, merged by name like
+	// containers.
+	// +optional
+	InitContainers []corev1.Container `json:"initContainers,omitempty"`
+	// Volumes added to the Prometheus pods. A volume named like one
+	// generated by the operator replaces it, e.g. prometheus-data to store
+	// the TSDB on a persistent volume.
+	// +optional
+	Volumes []corev1.Volume `json:"volumes,omitempty"`
+	// Volume mounts added to the Prometheus container.
+	// +optional
+	VolumeMounts []corev1.VolumeMount `json:"volumeMounts,omitempty"`
+	// Environment variables added to the Prometheus container.
+	// +optional
+	Env []corev1.EnvVar `json:"env,omitempty"`
+	// Command line arguments added to the Prometheus container, such as
+	// --enable-feature=... Flags managed by the operator are rejected.
+	// +optional
+	AdditionalArgs []string `json:"additionalArgs,omitempty"`
+	// Secret key holding raw scrape configs, as a YAML list, appended after
+	// scrapeConfigs for the options this API doesn't model. Their job names
+	// must be unique. An invalid Secret is reported in the status and leaves
+	// the running configuration untouched. Not supported as a DaemonSet, and
+	// ignored when the operator only watches some namespaces since the raw
+	// scrape configs can't be restricted to the node or the namespace.
+	// +optional
+	AdditionalScrapeConfigs *corev1.SecretKeySelector `json:"additionalScrapeConfigs,omitempty"`
+	// Labels of the ScrapeConfig objects whose jobs are added to the
+	// configuration. No ScrapeConfig is selected when omitted, an empty
+	// selector selects them all.
+	// +optional
+	ScrapeConfigSelector *metav1.LabelSelector `json:"scrapeConfigSelector,omitempty"`
+	// Labels of the namespaces ScrapeConfig objects are selected from. Only
+	// the namespace of the Prometheus is searched when omitted, an empty
+	// selector searches every namespace. Ignored when the operator only
+	// watches some namespaces, ScrapeConfigs are then taken from the
+	// namespace of the Prometheus.
+	// +optional
+	ScrapeConfigNamespaceSelector *metav1.LabelSelector `json:"scrapeConfigNamespaceSelector,omitempty"`
+	// Labels of the Probe objects checked through a blackbox exporter run
+	// by the operator next to the Prometheus. No Probe is selected, and no
+	// blackbox exporter is run, when omitted. An empty selector selects them
+	// all.
+	// +optional
+	ProbeSelector *metav1.LabelSelector `json:"probeSelector,omitempty"`
+	// Labels of the namespaces Probe objects are selected from, with the
+	// same semantics as scrapeConfigNamespaceSelector.
+	// +optional
+	ProbeNamespaceSelector *metav1.LabelSelector `json:"probeNamespaceSelector,omitempty"`
+}
+
+// ScrapePreset is the name of a built-in scrape config.
+// +kubebuilder:validation:Enum=kubelet;cadvisor;apiserver;coredns;annotated-pods
+type ScrapePreset string
+
+// Scrape presets, the kubelet and cadvisor ones scrape the kubelet of every
+// node, apiserver the Kubernetes API servers, coredns the CoreDNS pods
+// behind the kube-dns Service, and annotated-pods the pods annotated with
+// prometheus.io/scrape: "true", prometheus.io/port, prometheus.io/path and
+// prometheus.io/scheme.
+const (
+	PresetKubelet       ScrapePreset = "kubelet"
+	PresetCAdvisor      ScrapePreset = "cadvisor"
+	PresetAPIServer     ScrapePreset = "apiserver"
+	PresetCoreDNS       ScrapePreset = "coredns"
+	PresetAnnotatedPods ScrapePreset = "annotated-pods"
+)
+
+// NetworkPolicySpec define the NetworkPolicy isolating the Prometheus pods
+type NetworkPolicySpec struct {
+	// Peers allowed to reach the web port, and the gRPC port of the Thanos
+	// sidecar. No ingress traffic is allowed when empty.
+	// +optional
+	From []networkingv1.NetworkPolicyPeer `json:"from,omitempty"`
+	// Egress rules of the scrape and remote write targets. All egress
+	// traffic is allowed when omitted since the targets are discovered
+	// dynamically. DNS traffic to the kube-dns pods and traffic to the API
+	// server are always allowed.
+	// +optional
+	Egress []networkingv1.NetworkPolicyEgressRule `json:"egress,omitempty"`
+	// CIDRs of the API server, allowed on the ports 443 and 6443 when egress
+	// rules are set. Read from the endpoints of the default/kubernetes
+	// Service when empty, which requires the operator to watch all the
+	// namespaces.
+	// +optional
+	APIServerCIDRs []string `json:"apiServerCIDRs,omitempty"`
+}
+
+// ProbesSpec define the probes of the Prometheus container
+type ProbesSpec struct {
+	// Liveness probe, checking /-/healthy.
+	// +optional
+	Liveness *ProbeConfig `json:"liveness,omitempty"`
+	// Readiness probe, checking /-/ready.
+	// +optional
+	Readiness *ProbeConfig `json:"readiness,omitempty"`
+	// Startup probe, checking /-/ready until the WAL is replayed.
+	// +optional
+	Startup *ProbeConfig `json:"startup,omitempty"`
+}
+
+// ProbeConfig define the timings of a probe, the operator defaults are used
+// for the omitted fields
+type ProbeConfig struct {
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	InitialDelaySeconds *int32 `json:"initialDelaySeconds,omitempty"`
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	PeriodSeconds *int32 `json:"periodSeconds,omitempty"`
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	TimeoutSeconds *int32 `json:"timeoutSeconds,omitempty"`
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	FailureThreshold *int32 `json:"failureThreshold,omitempty"`
+}
+
+// IngressSpec define the Ingress exposing Prometheus
+type IngressSpec struct {
+	// Host Prometheus is served on.
+	Host string `json:"host"`
+	// Path prefix Prometheus is served under, also used as its route
+	// prefix. Defaults to /.
+	// +kubebuilder:validation:Pattern=^/
+	// +optional
+	Path *string `json:"path,omitempty"`
+	// Name of the Secret holding the certificate of the host. The external
+	// URL uses https when set.
+	// +optional
+	TLSSecretName *string `json:"tlsSecretName,omitempty"`
+	// Name of the IngressClass handling the Ingress.
+	// +optional
+	IngressClassName *string `json:"ingressClassName,omitempty"`
+	// Annotations added to the Ingress.
+	// +optional
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// WebSpec define the security settings of the Prometheus web endpoint
+type WebSpec struct {
+	// TLS serves the web endpoint over HTTPS.
+	// +optional
+	TLS *WebTLSConfig `json:"tls,omitempty"`
+	// Secret holding the users allowed to access the web endpoint, each key
+	// being a user name and its value the bcrypt hash of its password. The
+	// prometheus-operator user is reserved for the sidecars. A missing Secret
+	// or a Secret holding it is reported by the WebConfigValid condition.
+	// +optional
+	BasicAuthUsers *corev1.LocalObjectReference `json:"basicAuthUsers,omitempty"`
+}
+
+// WebTLSConfig define the certificates of the Prometheus web endpoint
+type WebTLSConfig struct {
+	// Secret key holding the PEM encoded certificate. The operator generates
+	// a self-signed certificate when cert and key are omitted.
+	// +optional
+	Cert *corev1.SecretKeySelector `json:"cert,omitempty"`
+	// Secret key holding the PEM encoded private key of the certificate.
+	// +optional
+	Key *corev1.SecretKeySelector `json:"key,omitempty"`
+	// Secret key holding the PEM encoded CA verifying the client
+	// certificates.
+	// +optional
+	ClientCA *corev1.SecretKeySelector `json:"clientCA,omitempty"`
+	// Policy applied to the client certificates. Defaults to
+	// VerifyClientCertIfGiven so that the sidecars and probes, which don't
+	// present certificates, keep working.
+	// +kubebuilder:validation:Enum=NoClientCert;RequestClientCert;RequireAnyClientCert;VerifyClientCertIfGiven;RequireAndVerifyClientCert
+	// +optional
+	ClientAuthType *string `json:"clientAuthType,omitempty"`
+}
+
+// TSDBSpec define the storage settings of Prometheus
+type TSDBSpec struct {
+	// How long samples are kept. Defaults to 15d.
+	// +kubebuilder:validation:Pattern=^(0|(([0-9]+)y)?(([0-9]+)w)?(([0-9]+)d)?(([0-9]+)h)?(([0-9]+)m)?(([0-9]+)s)?(([0-9]+)ms)?)$
+	// +optional
+	RetentionTime *string `json:"retentionTime,omitempty"`
+	// Maximum number of bytes of the blocks kept, e.g. 512MB.
+	// +kubebuilder:validation:Pattern=^(0|([0-9]+)(B|KB|MB|GB|TB|PB|EB))$
+	// +optional
+	RetentionSize *string `json:"retentionSize,omitempty"`
+	// Compress the write-ahead log. Enabled by default since Prometheus 2.20.
+	// +optional
+	WALCompression *bool `json:"walCompression,omitempty"`
+	// How old an out-of-order sample can be to still be ingested.
+	// +kubebuilder:validation:Pattern=^(0|(([0-9]+)y)?(([0-9]+)w)?(([0-9]+)d)?(([0-9]+)h)?(([0-9]+)m)?(([0-9]+)s)?(([0-9]+)ms)?)$
+	// +optional
+	OutOfOrderTimeWindow *string `json:"outOfOrderTimeWindow,omitempty"`
+	// Directory the data is stored in. Defaults to /prometheus/.
+	// +kubebuilder:validation:Pattern=^/
+	// +optional
+	Path *string `json:"path,omitempty"`
+	// Minimum duration of a block before it is persisted. Can't be set
+	// together with the Thanos sidecar, which requires 2h blocks.
+	// +kubebuilder:validation:Pattern=^(0|(([0-9]+)y)?(([0-9]+)w)?(([0-9]+)d)?(([0-9]+)h)?(([0-9]+)m)?(([0-9]+)s)?(([0-9]+)ms)?)$
+	// +optional
+	MinBlockDuration *string `json:"minBlockDuration,omitempty"`
+	// Maximum duration compacted blocks may span. Can't be set together with
+	// the Thanos sidecar.
+	// +kubebuilder:validation:Pattern=^(0|(([0-9]+)y)?(([0-9]+)w)?(([0-9]+)d)?(([0-9]+)h)?(([0-9]+)m)?(([0-9]+)s)?(([0-9]+)ms)?)$
+	// +optional
+	MaxBlockDuration *string `json:"maxBlockDuration,omitempty"`
+}
+
+// ThanosSpec define the Thanos sidecar injected next to Prometheus
+type ThanosSpec struct {
+	// Thanos image version of the sidecar
+	// +kubebuilder:validation:Pattern=^[0-9]+\.[0-9]+\.[0-9]+$
+	Version string `json:"version"`
+	// Secret key holding the object storage configuration the TSDB blocks
+	// are uploaded to. Blocks are only kept by Prometheus when omitted.
+	// +optional
+	ObjectStorageConfig *corev1.SecretKeySelector `json:"objectStorageConfig,omitempty"`
+}
+
+// RemoteWriteConfig define a remote write endpoint
+type RemoteWriteConfig struct {
+	// URL of the endpoint to send samples to.
+	URL string `json:"url"`
+	// Name of the remote write queue, must be unique among all queues.
+	// +optional
+	Name *string `json:"name,omitempty"`
+	// Timeout for requests to the remote write endpoint.
+	// +kubebuilder:validation:Pattern=^(0|(([0-9]+)y)?(([0-9]+)w)?(([0-9]+)d)?(([0-9]+)h)?(([0-9]+)m)?(([0-9]+)s)?(([0-9]+)ms)?)$
+	// +optional
+	RemoteTimeout *string `json:"remoteTimeout,omitempty"`
+	// Relabeling applied to the samples before sending them.
+	// +optional
+	WriteRelabelConfigs []RelabelConfig `json:"writeRelabelConfigs,omitempty"`
+}
+
+// ScrapeConfigSpec define a scrape configuration for the prometheus server
+type ScrapeConfigSpec struct {
+	JobName string `json:"jobName"`
+	// Path of the metrics on the targets. Defaults to /metrics.
+	// +optional
+	MetricsPath *string `json:"metricsPath,omitempty"`
+	// Protocol of the scrape requests. Defaults to http.
+	// +kubebuilder:validation:Enum=http;https
+	// +optional
+	Scheme *string `json:"scheme,omitempty"`
+	// TLS settings of the scrape requests.
+	// +optional
+	TLSConfig *TLSConfig `json:"tlsConfig,omitempty"`
+	// File of the Prometheus pods holding the bearer token sent to the
+	// targets, such as the token of their service account.
+	// +optional
+	BearerTokenFile *string `json:"bearerTokenFile,omitempty"`
+	// +optional
+	K8SSDConfigs []K8SSDConfig `json:"kubernetesSDConfigs,omitempty"`
+	// Targets listed statically.
+	// +optional
+	StaticConfigs []StaticConfig `json:"staticConfigs,omitempty"`
+	// Targets read from files of ConfigMaps mounted by the operator. Changes
+	// to the ConfigMaps are picked up without restarting Prometheus.
+	// +optional
+	FileSDConfigs []FileSDConfig `json:"fileSDConfigs,omitempty"`
+	// Targets discovered through DNS queries.
+	// +optional
+	DNSSDConfigs []DNSSDConfig `json:"dnsSDConfigs,omitempty"`
+	// Targets fetched from an HTTP endpoint.
+	// +optional
+	HTTPSDConfigs  []HTTPSDConfig  `json:"httpSDConfigs,omitempty"`
+	RelabelConfigs []RelabelConfig `json:"relabelConfigs,omitempty"`
+}
+
+// TLSConfig define the TLS settings of scrape requests
+type TLSConfig struct {
+	// File of the Prometheus pods holding the CA certificates the targets
+	// are verified with.
+	// +optional
+	CAFile *string `json:"caFile,omitempty"`
+	// Name the certificates of the targets are verified against.
+	// +optional
+	ServerName *string `json:"serverName,omitempty"`
+	// Disables the verification of the certificates of the targets.
+	// +optional
+	InsecureSkipVerify *bool `json:"insecureSkipVerify,omitempty"`
+}
+
+// StaticConfig define a group of targets listed statically
+type StaticConfig struct {
+	// Addresses of the targets, as host:port.
+	// +kubebuilder:validation:MinItems=1
+	Targets []string `json:"targets"`
+	// Labels added to the metrics of the targets.
+	// +optional
+	Labels map[string]string `json:"labels,omitempty"`
+}
+
+// FileSDConfig define a file service discovery reading the target groups from
+// the keys of a ConfigMap, in the JSON or YAML file_sd format
+type FileSDConfig struct {
+	// Name of the ConfigMap, in the namespace of the Prometheus.
+	ConfigMap string `json:"configMap"`
+	// Keys of the ConfigMap holding target groups. Every key ending in
+	// .json, .yml or .yaml is read when omitted.
+	// +optional
+	Keys []string `json:"keys,omitempty"`
+	// +kubebuilder:validation:Pattern=^(0|(([0-9]+)y)?(([0-9]+)w)?(([0-9]+)d)?(([0-9]+)h)?(([0-9]+)m)?(([0-9]+)s)?(([0-9]+)ms)?)$
+	// +optional
+	RefreshInterval *string `json:"refreshInterval,omitempty"`
+}
+
+// DNSSDConfig define a DNS service discovery config
+type DNSSDConfig struct {
+	// DNS names to query.
+	// +kubebuilder:validation:MinItems=1
+	Names []string `json:"names"`
+	// Type of the queried records. Defaults to SRV.
+	// +kubebuilder:validation:Enum=SRV;A;AAAA
+	// +optional
+	Type *string `json:"type,omitempty"`
+	// Port of the targets, required for A and AAAA records.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	// +optional
+	Port *int32 `json:"port,omitempty"`
+	// +kubebuilder:validation:Pattern=^(0|(([0-9]+)y)?(([0-9]+)w)?(([0-9]+)d)?(([0-9]+)h)?(([0-9]+)m)?(([0-9]+)s)?(([0-9]+)ms)?)$
+	// +optional
+	RefreshInterval *string `json:"refreshInterval,omitempty"`
+}
+
+// HTTPSDConfig define an HTTP service discovery config
+type HTTPSDConfig struct {
+	// URL returning the target groups.
+	// +kubebuilder:validation:Pattern=`^https?://`
+	URL string `json:"url"`
+	// +kubebuilder:validation:Pattern=^(0|(([0-9]+)y)?(([0-9]+)w)?(([0-9]+)d)?(([0-9]+)h)?(([0-9]+)m)?(([0-9]+)s)?(([0-9]+)ms)?)$
+	// +optional
+	RefreshInterval *string `json:"refreshInterval,omitempty"`
+}
+
+// K8SSDConfig define a kubernetes service discovery config
+type K8SSDConfig struct {
+	// +kubebuilder:validation:Enum=node;pod;service;ingress
+	Role string `json:"role"`
+	// Namespaces restricts the discovery to the given namespaces. Objects of
+	// all namespaces are discovered when omitted.
+	// +optional
+	Namespaces *K8SSDNamespaces `json:"namespaces,omitempty"`
+	// Selectors filter the discovered objects with label and field selectors.
+	// +optional
+	Selectors []K8SSDSelector `json:"selectors,omitempty"`
+}
+
+// K8SSDSelector define a label and field selector applied to the objects of a role
+type K8SSDSelector struct {
+	// +kubebuilder:validation:Enum=node;pod;service;endpoints;endpointslice;ingress
+	Role string `json:"role"`
+	// +optional
+	Label *string `json:"label,omitempty"`
+	// +optional
+	Field *string `json:"field,omitempty"`
+}
+
+// K8SSDNamespaces define the namespaces a kubernetes service discovery config looks into
+type K8SSDNamespaces struct {
+	Names []string `json:"names"`
+}
+
+type RelabelConfig struct {
+	// +optional
+	SourceLabels []string `json:"sourceLabels,omitempty"`
+	// +optional
+	Action *string `json:"action,omitempty"`
+	// +optional
+	Regex *string `json:"regex,omitempty"`
+	// +optional
+	TargetLabel *string `json:"targetLabel,omitempty"`
+	// Value written to the target label, where the regex groups can be
+	// referenced. Defaults to $1.
+	// +optional
+	Replacement *string `json:"replacement,omitempty"`
+	// +optional
+	Modulus *uint64 `json:"modulus,omitempty"`
+}
+
+// PrometheusStatus is the most recent observed status of the Prometheus cluster.
+// More info:
+// https://github.com/kubernetes/community/blob/master/contributors/devel/sig-architecture/api-conventions.md#spec-and-status
+// +k8s:openapi-gen=true
+type PrometheusStatus struct {
+	// Conditions describe the current state of the Prometheus.
+	// +optional
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+	// Shards is the status of each shard workload.
+	// +optional
+	Shards []ShardStatus `json:"shards,omitempty"`
+	// Version of Prometheus running in the ready pods, read from the tag of
+	// their image, which lags behind spec.version during a rollout. Lists
+	// every running version, comma separated, while pods of different
+	// versions coexist. Images pinned by digest are reported by digest.
+	// +optional
+	Version string `json:"version,omitempty"`
+	// ScrapeConfigs lists the ScrapeConfig objects matched by the selectors.
+	// +optional
+	ScrapeConfigs *SelectedResources `json:"scrapeConfigs,omitempty"`
+	// Probes lists the Probe objects matched by the selectors.
+	// +optional
+	Probes *SelectedResources `json:"probes,omitempty"`
+	// Replicas is the desired number of Prometheus pods over all the shards.
+	// +optional
+	Replicas int32 `json:"replicas"`
+	// ReadyReplicas is the number of ready Prometheus pods over all the
+	// shards.
+	// +optional
+	ReadyReplicas int32 `json:"readyReplicas"`
+	// ScrapeJobs is the number of scrape jobs in the configuration.
+	// +optional
+	ScrapeJobs int32 `json:"scrapeJobs"`
+}
+
+// SelectedResources reports the objects selected by a Prometheus.
+type SelectedResources struct {
+	// Selected objects added to the configuration, as namespace/name.
+	// +optional
+	Selected []string `json:"selected,omitempty"`
+	// Rejected objects left out of the configuration.
+	// +optional
+	Rejected []RejectedResource `json:"rejected,omitempty"`
+}
+
+// RejectedResource is an object left out of the configuration and why.
+type RejectedResource struct {
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	// Reason the object was rejected.
+	Reason string `json:"reason"`
+}
+
+// ShardStatus is the most recent observed status of a Prometheus shard.
+type ShardStatus struct {
+	// Index of the shard.
+	Shard int32 `json:"shard"`
+	// Name of the workload running the shard.
+	Workload string `json:"workload"`
+	// Total number of pods targeted by the shard workload.
+	Replicas int32 `json:"replicas"`
+	// Number of ready pods of the shard workload.
+	ReadyReplicas int32 `json:"readyReplicas"`
+}
+
+func init() {
+	SchemeBuilder.Register(&Prometheus{}, &PrometheusList{})
+}
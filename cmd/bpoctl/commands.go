@@ -0,0 +1,190 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+
+	"github.com/ghodss/yaml"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// redacted replaces the values of the Secrets generated by the operator,
+// such as passwords and private keys.
+const redacted = "<redacted>"
+
+// newFlagSet returns the flag set of a command, binding the shared options.
+func newFlagSet(name, description string, o *options) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: bpoctl %s -f FILE [flags]\n\n%s\n\nFlags:\n", name, description)
+		fs.PrintDefaults()
+	}
+	o.bind(fs)
+	return fs
+}
+
+// runRender prints the manifests created for the Prometheus resources.
+func runRender(args []string) error {
+	o := &options{}
+	fs := newFlagSet("render", "Print the manifests the operator creates for the Prometheus resources.", o)
+	showSecrets := fs.Bool("show-secrets", false, "Print the values of the generated Secrets instead of redacting them.")
+	_ = fs.Parse(args)
+
+	res, err := render(o)
+	if err != nil {
+		return err
+	}
+	if err := reportProblems(os.Stderr, res); err != nil {
+		return err
+	}
+	return writeManifests(os.Stdout, res, *showSecrets)
+}
+
+// writeManifests writes the objects created by the reconciliations as YAML
+// documents, sorted by kind, namespace and name.
+func writeManifests(w io.Writer, res *renderResult, showSecrets bool) error {
+	// The kinds of the objects managed by the operator
+	lists := []client.ObjectList{
+		&rbacv1.ClusterRoleList{},
+		&rbacv1.ClusterRoleBindingList{},
+		&rbacv1.RoleList{},
+		&rbacv1.RoleBindingList{},
+		&corev1.ServiceAccountList{},
+		&corev1.SecretList{},
+		&corev1.ConfigMapList{},
+		&corev1.ServiceList{},
+		&appsv1.DeploymentList{},
+		&appsv1.DaemonSetList{},
+		&policyv1.PodDisruptionBudgetList{},
+		&networkingv1.IngressList{},
+		&networkingv1.NetworkPolicyList{},
+	}
+	for _, list := range lists {
+		objs, err := res.outputObjects(list)
+		if err != nil {
+			return err
+		}
+		sort.Slice(objs, func(i, j int) bool {
+			if objs[i].GetNamespace() != objs[j].GetNamespace() {
+				return objs[i].GetNamespace() < objs[j].GetNamespace()
+			}
+			return objs[i].GetName() < objs[j].GetName()
+		})
+		for _, obj := range objs {
+			if secret, ok := obj.(*corev1.Secret); ok && !showSecrets {
+				redactSecret(secret)
+			}
+			data, err := yaml.Marshal(obj)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(w, "---\n%s", data)
+		}
+	}
+	return nil
+}
+
+// runConfig prints the prometheus.yml of every shard.
+func runConfig(args []string) error {
+	o := &options{}
+	fs := newFlagSet("config", "Print the prometheus.yml of each Prometheus shard.", o)
+	_ = fs.Parse(args)
+
+	res, err := render(o)
+	if err != nil {
+		return err
+	}
+	if err := reportProblems(os.Stderr, res); err != nil {
+		return err
+	}
+	return writeConfigs(os.Stdout, res)
+}
+
+// writeConfigs writes the prometheus.yml of every shard as YAML documents,
+// sorted by the namespace and name of their ConfigMap.
+func writeConfigs(w io.Writer, res *renderResult) error {
+	objs, err := res.outputObjects(&corev1.ConfigMapList{})
+	if err != nil {
+		return err
+	}
+	sort.Slice(objs, func(i, j int) bool {
+		if objs[i].GetNamespace() != objs[j].GetNamespace() {
+			return objs[i].GetNamespace() < objs[j].GetNamespace()
+		}
+		return objs[i].GetName() < objs[j].GetName()
+	})
+	for _, obj := range objs {
+		config, ok := obj.(*corev1.ConfigMap).Data["prometheus.yml"]
+		if !ok {
+			continue
+		}
+		fmt.Fprintf(w, "---\n# Source: ConfigMap %s/%s\n%s", obj.GetNamespace(), obj.GetName(), config)
+	}
+	return nil
+}
+
+// runLint reports the validation errors of the Prometheus resources, and
+// fails when there are some.
+func runLint(args []string) error {
+	o := &options{}
+	fs := newFlagSet("lint", "Report the validation errors of the Prometheus resources: unknown fields, the\n"+
+		"errors of the OpenAPI schemas and of the validating webhook, invalid referenced\n"+
+		"objects, rejected ScrapeConfigs and Probes, and the rendered prometheus.yml files\n"+
+		"Prometheus would refuse to load.", o)
+	_ = fs.Parse(args)
+
+	res, err := render(o)
+	if err != nil {
+		return err
+	}
+	if err := reportProblems(os.Stderr, res); err != nil {
+		return err
+	}
+	fmt.Println("No problems found")
+	return nil
+}
+
+// reportProblems writes the problems found while rendering, one per line,
+// and fails when there are some.
+func reportProblems(w io.Writer, res *renderResult) error {
+	if len(res.problems) == 0 {
+		return nil
+	}
+	for _, problem := range res.problems {
+		fmt.Fprintln(w, problem)
+	}
+	return errLintFailed
+}
+
+// redactSecret replaces the values of a Secret.
+func redactSecret(secret *corev1.Secret) {
+	secret.StringData = make(map[string]string, len(secret.Data))
+	for key := range secret.Data {
+		secret.StringData[key] = redacted
+	}
+	secret.Data = nil
+}
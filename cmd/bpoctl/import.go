@@ -0,0 +1,310 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+
+	"github.com/ghodss/yaml"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	monitoringv1alpha1 "github.com/marieroque/best-prometheus-operator-in-the-world/api/monitoring/v1alpha1"
+)
+
+// additionalScrapeConfigsKey is the key of the Secret holding the scrape
+// configs that can't be represented in the spec.
+const additionalScrapeConfigsKey = "scrape-configs.yaml"
+
+// importResult is a Prometheus converted from a prometheus.yml, along with
+// the parts of the configuration the conversion lost.
+type importResult struct {
+	prometheus *monitoringv1alpha1.Prometheus
+	// secret holds the raw scrape configs, nil when there are none
+	secret *corev1.Secret
+	// lossy lists the settings that were dropped or kept raw
+	lossy []string
+	// remoteWriteDropped is set when a remote_write was dropped, the
+	// samples it received would no longer be sent
+	remoteWriteDropped bool
+}
+
+// runImport converts a prometheus.yml into a Prometheus resource.
+func runImport(args []string) error {
+	fs := flag.NewFlagSet("import", flag.ExitOnError)
+	file := fs.String("f", "", "The prometheus.yml to import, - reads from stdin.")
+	name := fs.String("name", "prometheus", "Name of the Prometheus resource.")
+	namespace := fs.String("namespace", "", "Namespace of the Prometheus resource.")
+	version := fs.String("version", "", "Prometheus version to run, required.")
+	strict := fs.Bool("strict", false, "Fail when settings are dropped or kept as raw scrape configs.")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: bpoctl import -f FILE -version VERSION [flags]\n\n"+
+			"Print a Prometheus resource with the settings of a prometheus.yml. The scrape configs\n"+
+			"the spec can't represent are put in a Secret referenced by additionalScrapeConfigs.\n"+
+			"The settings that were dropped or kept raw are reported on stderr. Fails when a\n"+
+			"remote_write is dropped, even without -strict.\n\nFlags:\n")
+		fs.PrintDefaults()
+	}
+	_ = fs.Parse(args)
+	if *file == "" || *version == "" {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	data, err := readFile(*file)
+	if err != nil {
+		return err
+	}
+	res, err := importConfig(data, *name, *namespace, *version)
+	if err != nil {
+		return err
+	}
+
+	for _, obj := range []interface{}{res.prometheus, res.secret} {
+		if reflect.ValueOf(obj).IsNil() {
+			continue
+		}
+		manifest, err := marshalManifest(obj)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("---\n%s", manifest)
+	}
+	for _, msg := range res.lossy {
+		fmt.Fprintln(os.Stderr, "warning:", msg)
+	}
+	if err := res.prometheus.ValidateCreate(); err != nil {
+		fmt.Fprintln(os.Stderr, "warning:", err)
+	}
+	if res.remoteWriteDropped || (*strict && len(res.lossy) > 0) {
+		return errLintFailed
+	}
+	return nil
+}
+
+// importConfig maps the settings of a prometheus.yml onto a Prometheus.
+func importConfig(data []byte, name, namespace, version string) (*importResult, error) {
+	cfg := map[string]interface{}{}
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("invalid prometheus.yml: %w", err)
+	}
+
+	res := &importResult{
+		prometheus: &monitoringv1alpha1.Prometheus{
+			TypeMeta: metav1.TypeMeta{
+				APIVersion: monitoringv1alpha1.GroupVersion.String(),
+				Kind:       "Prometheus",
+			},
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+			Spec: monitoringv1alpha1.PrometheusSpec{
+				Version:       &version,
+				ScrapeConfigs: []*monitoringv1alpha1.ScrapeConfigSpec{},
+			},
+		},
+	}
+	spec := &res.prometheus.Spec
+
+	sections := make([]string, 0, len(cfg))
+	for section := range cfg {
+		sections = append(sections, section)
+	}
+	sort.Strings(sections)
+	for _, section := range sections {
+		switch section {
+		case "scrape_configs", "remote_write", "storage":
+		default:
+			// The global section, rules and alerting aren't managed by the
+			// operator
+			res.lossy = append(res.lossy, fmt.Sprintf("%s: dropped, not supported by the operator", section))
+		}
+	}
+
+	scrapeConfigs, _ := cfg["scrape_configs"].([]interface{})
+	var raw []interface{}
+	for i, item := range scrapeConfigs {
+		sc := &monitoringv1alpha1.ScrapeConfigSpec{}
+		lost, err := convert(item, sc)
+		if err != nil {
+			return nil, fmt.Errorf("scrape_configs[%d]: %w", i, err)
+		}
+		if len(lost) > 0 {
+			res.lossy = append(res.lossy, fmt.Sprintf("scrape_configs[%d] (%s): kept raw, %v can't be represented",
+				i, jobName(item), lost))
+			raw = append(raw, item)
+			continue
+		}
+		spec.ScrapeConfigs = append(spec.ScrapeConfigs, sc)
+	}
+
+	remoteWrites, _ := cfg["remote_write"].([]interface{})
+	for i, item := range remoteWrites {
+		rw := &monitoringv1alpha1.RemoteWriteConfig{}
+		lost, err := convert(item, rw)
+		if err != nil {
+			return nil, fmt.Errorf("remote_write[%d]: %w", i, err)
+		}
+		if len(lost) > 0 {
+			res.lossy = append(res.lossy, fmt.Sprintf("remote_write[%d]: dropped, %v can't be represented", i, lost))
+			res.remoteWriteDropped = true
+			continue
+		}
+		spec.RemoteWrite = append(spec.RemoteWrite, rw)
+	}
+
+	if storage, ok := cfg["storage"].(map[string]interface{}); ok {
+		tsdb, _ := storage["tsdb"].(map[string]interface{})
+		if window, ok := tsdb["out_of_order_time_window"].(string); ok {
+			spec.TSDB = &monitoringv1alpha1.TSDBSpec{OutOfOrderTimeWindow: &window}
+			delete(tsdb, "out_of_order_time_window")
+		}
+		delete(storage, "tsdb")
+		if len(tsdb) > 0 || len(storage) > 0 {
+			res.lossy = append(res.lossy, "storage: dropped, only tsdb.out_of_order_time_window is supported")
+		}
+	}
+
+	if len(raw) > 0 {
+		data, err := yaml.Marshal(raw)
+		if err != nil {
+			return nil, err
+		}
+		res.secret = &corev1.Secret{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "Secret"},
+			ObjectMeta: metav1.ObjectMeta{Name: name + "-additional-scrape-configs", Namespace: namespace},
+			StringData: map[string]string{additionalScrapeConfigsKey: string(data)},
+		}
+		spec.AdditionalScrapeConfigs = &corev1.SecretKeySelector{
+			LocalObjectReference: corev1.LocalObjectReference{Name: res.secret.Name},
+			Key:                  additionalScrapeConfigsKey,
+		}
+	}
+	return res, nil
+}
+
+// convert decodes a section of the configuration into a type of the API, and
+// returns the paths of the settings the type can't represent, found by
+// comparing the section with the encoded type.
+func convert(section interface{}, typed interface{}) ([]string, error) {
+	data, err := json.Marshal(section)
+	if err != nil {
+		return nil, err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	if err := decoder.Decode(typed); err != nil {
+		// A value of another type, for instance, can't be represented
+		return []string{err.Error()}, nil
+	}
+
+	encoded, err := json.Marshal(typed)
+	if err != nil {
+		return nil, err
+	}
+	var original, roundTrip interface{}
+	if err := json.Unmarshal(data, &original); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(encoded, &roundTrip); err != nil {
+		return nil, err
+	}
+	return lostPaths(original, roundTrip, ""), nil
+}
+
+// lostPaths returns the paths of the values of the original section that
+// differ in the round trip.
+func lostPaths(original, roundTrip interface{}, path string) []string {
+	switch o := original.(type) {
+	case map[string]interface{}:
+		r, ok := roundTrip.(map[string]interface{})
+		if !ok {
+			return []string{path}
+		}
+		keys := make([]string, 0, len(o))
+		for key := range o {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		var lost []string
+		for _, key := range keys {
+			child := key
+			if path != "" {
+				child = path + "." + key
+			}
+			if _, ok := r[key]; !ok {
+				lost = append(lost, child)
+				continue
+			}
+			lost = append(lost, lostPaths(o[key], r[key], child)...)
+		}
+		return lost
+	case []interface{}:
+		r, ok := roundTrip.([]interface{})
+		if !ok || len(r) != len(o) {
+			return []string{path}
+		}
+		var lost []string
+		for i := range o {
+			lost = append(lost, lostPaths(o[i], r[i], path+"["+strconv.Itoa(i)+"]")...)
+		}
+		return lost
+	default:
+		if !reflect.DeepEqual(original, roundTrip) {
+			return []string{path}
+		}
+		return nil
+	}
+}
+
+// jobName returns the job name of a raw scrape config.
+func jobName(sc interface{}) string {
+	m, _ := sc.(map[string]interface{})
+	name, _ := m["job_name"].(string)
+	return name
+}
+
+// readFile reads a file, or stdin for -.
+func readFile(file string) ([]byte, error) {
+	if file == "-" {
+		return io.ReadAll(os.Stdin)
+	}
+	return os.ReadFile(file)
+}
+
+// marshalManifest encodes an object as YAML, without the empty status and
+// creation timestamp of objects that weren't created yet.
+func marshalManifest(obj interface{}) ([]byte, error) {
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return nil, err
+	}
+	manifest := map[string]interface{}{}
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, err
+	}
+	delete(manifest, "status")
+	if metadata, ok := manifest["metadata"].(map[string]interface{}); ok {
+		delete(metadata, "creationTimestamp")
+	}
+	return yaml.Marshal(manifest)
+}
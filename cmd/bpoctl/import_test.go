@@ -0,0 +1,153 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	monitoringv1alpha1 "github.com/marieroque/best-prometheus-operator-in-the-world/api/monitoring/v1alpha1"
+)
+
+func TestLostPaths(t *testing.T) {
+	tests := []struct {
+		name      string
+		original  interface{}
+		roundTrip interface{}
+		want      []string
+	}{{
+		name:      "identical",
+		original:  map[string]interface{}{"a": "x", "b": []interface{}{"y"}},
+		roundTrip: map[string]interface{}{"a": "x", "b": []interface{}{"y"}},
+	}, {
+		name:      "missing keys",
+		original:  map[string]interface{}{"b": 1.0, "a": map[string]interface{}{"c": true, "d": "z"}},
+		roundTrip: map[string]interface{}{"a": map[string]interface{}{"c": true}},
+		want:      []string{"a.d", "b"},
+	}, {
+		name:      "list items",
+		original:  map[string]interface{}{"l": []interface{}{map[string]interface{}{"k": "v"}, "x"}},
+		roundTrip: map[string]interface{}{"l": []interface{}{map[string]interface{}{}, "x"}},
+		want:      []string{"l[0].k"},
+	}, {
+		name:      "list length",
+		original:  map[string]interface{}{"l": []interface{}{"x", "y"}},
+		roundTrip: map[string]interface{}{"l": []interface{}{"x"}},
+		want:      []string{"l"},
+	}, {
+		name:      "changed value",
+		original:  map[string]interface{}{"v": "1"},
+		roundTrip: map[string]interface{}{"v": 1.0},
+		want:      []string{"v"},
+	}, {
+		name:      "changed type",
+		original:  map[string]interface{}{"m": map[string]interface{}{"k": "v"}},
+		roundTrip: map[string]interface{}{"m": "v"},
+		want:      []string{"m"},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := lostPaths(tt.original, tt.roundTrip, "")
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConvert(t *testing.T) {
+	tests := []struct {
+		name    string
+		section map[string]interface{}
+		want    []string
+	}{{
+		name: "represented",
+		section: map[string]interface{}{
+			"url":            "https://remote.example.com/write",
+			"remote_timeout": "30s",
+		},
+	}, {
+		name: "unsupported field",
+		section: map[string]interface{}{
+			"url":        "https://remote.example.com/write",
+			"basic_auth": map[string]interface{}{"username": "user"},
+		},
+		want: []string{"basic_auth"},
+	}, {
+		name: "unsupported nested field",
+		section: map[string]interface{}{
+			"url": "https://remote.example.com/write",
+			"write_relabel_configs": []interface{}{
+				map[string]interface{}{"action": "drop", "unknown": "x"},
+			},
+		},
+		want: []string{"write_relabel_configs[0].unknown"},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := convert(tt.section, &monitoringv1alpha1.RemoteWriteConfig{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	// A value of another type is lost as a whole
+	got, err := convert(map[string]interface{}{"url": 1}, &monitoringv1alpha1.RemoteWriteConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || !strings.Contains(got[0], "url") {
+		t.Errorf("got %v, want the decoding error of url", got)
+	}
+}
+
+func TestImportConfigRemoteWrite(t *testing.T) {
+	data := []byte(`remote_write:
+- url: https://kept.example.com/write
+- url: https://dropped.example.com/write
+  basic_auth:
+    username: user
+`)
+	res, err := importConfig(data, "imported", "default", "2.40.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !res.remoteWriteDropped {
+		t.Error("the dropped remote_write isn't reported")
+	}
+	remoteWrite := res.prometheus.Spec.RemoteWrite
+	if len(remoteWrite) != 1 || *remoteWrite[0].URL != "https://kept.example.com/write" {
+		t.Errorf("got remote writes %+v, want only the first one", remoteWrite)
+	}
+	want := []string{"remote_write[1]: dropped, [basic_auth] can't be represented"}
+	if !reflect.DeepEqual(res.lossy, want) {
+		t.Errorf("got %v, want %v", res.lossy, want)
+	}
+
+	res, err = importConfig([]byte("remote_write:\n- url: https://kept.example.com/write\n"), "imported", "default", "2.40.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.remoteWriteDropped {
+		t.Error("no remote_write was dropped")
+	}
+}
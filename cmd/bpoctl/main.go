@@ -0,0 +1,72 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Command bpoctl works on Prometheus resources read from files, without a
+// cluster. It renders the objects the operator would create for them, or
+// lints them, for instance to review changes in pull requests. It also
+// converts existing prometheus.yml files into Prometheus resources.
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+)
+
+const usage = `Usage: bpoctl <command> [flags]
+
+Commands:
+  render   Print the manifests the operator creates for the Prometheus resources
+  config   Print the prometheus.yml of each Prometheus shard
+  lint     Report the validation errors of the Prometheus resources
+  import   Convert a prometheus.yml into a Prometheus resource
+
+Run bpoctl <command> -h for the flags of a command.
+`
+
+// errLintFailed is returned once the problems found have been reported.
+var errLintFailed = errors.New("validation failed")
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	var err error
+	switch os.Args[1] {
+	case "render":
+		err = runRender(os.Args[2:])
+	case "config":
+		err = runConfig(os.Args[2:])
+	case "lint":
+		err = runLint(os.Args[2:])
+	case "import":
+		err = runImport(os.Args[2:])
+	case "help", "-h", "--help":
+		fmt.Print(usage)
+		return
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n%s", os.Args[1], usage)
+		os.Exit(2)
+	}
+	if err != nil {
+		if err != errLintFailed {
+			fmt.Fprintln(os.Stderr, "Error:", err)
+		}
+		os.Exit(1)
+	}
+}
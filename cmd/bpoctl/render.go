@@ -0,0 +1,427 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	promconfig "github.com/prometheus/prometheus/config"
+	// Register the service discoveries for the configurations to be loaded
+	_ "github.com/prometheus/prometheus/discovery/install"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
+	"k8s.io/apimachinery/pkg/types"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/conversion"
+
+	monitoringv1alpha1 "github.com/marieroque/best-prometheus-operator-in-the-world/api/monitoring/v1alpha1"
+	monitoringv1beta1 "github.com/marieroque/best-prometheus-operator-in-the-world/api/monitoring/v1beta1"
+	"github.com/marieroque/best-prometheus-operator-in-the-world/controllers"
+)
+
+// maxReconciles bounds the reconciliations of a Prometheus, each one
+// requeued after creating a workload.
+const maxReconciles = 20
+
+// fileList is a flag that can be repeated.
+type fileList []string
+
+func (f *fileList) String() string {
+	return strings.Join(*f, ",")
+}
+
+func (f *fileList) Set(value string) error {
+	*f = append(*f, value)
+	return nil
+}
+
+// options are the flags shared by the commands.
+type options struct {
+	files           fileList
+	namespace       string
+	namespaceScoped bool
+}
+
+func (o *options) bind(fs *flag.FlagSet) {
+	fs.Var(&o.files, "f", "File of YAML documents holding the Prometheus resources and the objects they reference, "+
+		"such as ScrapeConfigs, Probes, Secrets and Namespaces. Can be repeated, - reads from stdin.")
+	fs.StringVar(&o.namespace, "namespace", "default", "Namespace of the resources that don't set one.")
+	fs.BoolVar(&o.namespaceScoped, "namespace-scoped", false,
+		"Render as an operator started with --namespaces, which doesn't manage cluster-scoped objects.")
+}
+
+// renderResult holds the objects of a fake cluster after the Prometheus
+// resources were reconciled.
+type renderResult struct {
+	scheme *runtime.Scheme
+	client client.Client
+	// inputs are the objects read from the files, left out of the output
+	inputs map[objectKey]bool
+	// problems are the validation errors, as reported by lint
+	problems []string
+}
+
+// objectKey identifies an object of the fake cluster.
+type objectKey struct {
+	gvk schema.GroupVersionKind
+	types.NamespacedName
+}
+
+// render reconciles the Prometheus resources of the files against a fake
+// cluster holding the other objects of the files. The Prometheus resources
+// refused by the validating webhook aren't reconciled.
+func render(o *options) (*renderResult, error) {
+	if len(o.files) == 0 {
+		return nil, fmt.Errorf("no file given, use -f")
+	}
+
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		return nil, err
+	}
+	if err := monitoringv1alpha1.AddToScheme(scheme); err != nil {
+		return nil, err
+	}
+	if err := monitoringv1beta1.AddToScheme(scheme); err != nil {
+		return nil, err
+	}
+
+	objs, problems, err := loadObjects(scheme, o.files, o.namespace)
+	if err != nil {
+		return nil, err
+	}
+	result := &renderResult{
+		scheme:   scheme,
+		inputs:   map[objectKey]bool{},
+		problems: problems,
+	}
+	var prometheuses []*monitoringv1alpha1.Prometheus
+	for _, obj := range objs {
+		key, err := keyForObject(scheme, obj)
+		if err != nil {
+			return nil, err
+		}
+		if result.inputs[key] {
+			return nil, fmt.Errorf("%s %s is defined twice", key.gvk.Kind, key.NamespacedName)
+		}
+		result.inputs[key] = true
+		if cr, ok := obj.(*monitoringv1alpha1.Prometheus); ok {
+			prometheuses = append(prometheuses, cr)
+		}
+	}
+	if len(prometheuses) == 0 {
+		if len(result.problems) > 0 {
+			// The invalid Prometheus resources were left out
+			return result, nil
+		}
+		return nil, fmt.Errorf("no Prometheus resource found in the files")
+	}
+
+	result.client = fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).Build()
+	r := &controllers.PrometheusReconciler{
+		Client:          result.client,
+		Scheme:          scheme,
+		NamespaceScoped: o.namespaceScoped,
+	}
+
+	ctx := context.Background()
+	for _, cr := range prometheuses {
+		name := cr.Namespace + "/" + cr.Name
+		if err := cr.ValidateCreate(); err != nil {
+			result.problems = append(result.problems, fmt.Sprintf("Prometheus %s: %v", name, err))
+			continue
+		}
+		if err := reconcile(ctx, r, cr); err != nil {
+			return nil, fmt.Errorf("failed to reconcile Prometheus %s: %w", name, err)
+		}
+
+		reconciled := &monitoringv1alpha1.Prometheus{}
+		if err := result.client.Get(ctx, client.ObjectKeyFromObject(cr), reconciled); err != nil {
+			return nil, err
+		}
+		result.problems = append(result.problems, statusProblems(reconciled)...)
+	}
+	configProblems, err := result.configProblems()
+	if err != nil {
+		return nil, err
+	}
+	result.problems = append(result.problems, configProblems...)
+	return result, nil
+}
+
+// configProblems loads the rendered prometheus.yml of every shard the way
+// Prometheus does, and returns the ones it would refuse.
+func (res *renderResult) configProblems() ([]string, error) {
+	objs, err := res.outputObjects(&corev1.ConfigMapList{})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(objs, func(i, j int) bool {
+		if objs[i].GetNamespace() != objs[j].GetNamespace() {
+			return objs[i].GetNamespace() < objs[j].GetNamespace()
+		}
+		return objs[i].GetName() < objs[j].GetName()
+	})
+	var problems []string
+	for _, obj := range objs {
+		config, ok := obj.(*corev1.ConfigMap).Data["prometheus.yml"]
+		if !ok {
+			continue
+		}
+		config, err := withoutTSDBSettings(config)
+		if err != nil {
+			return nil, err
+		}
+		// The logger is only used to expand the external labels
+		if _, err := promconfig.Load(config, false, nil); err != nil {
+			problems = append(problems, fmt.Sprintf("ConfigMap %s/%s: invalid prometheus.yml: %v",
+				obj.GetNamespace(), obj.GetName(), err))
+		}
+	}
+	return problems, nil
+}
+
+// withoutTSDBSettings removes the storage.tsdb section of a prometheus.yml.
+// The configuration loader predates that section of Prometheus 2.39, whose
+// settings are checked by the validating webhook instead.
+func withoutTSDBSettings(config string) (string, error) {
+	var cfg map[string]interface{}
+	if err := yaml.Unmarshal([]byte(config), &cfg); err != nil {
+		return "", err
+	}
+	storage, ok := cfg["storage"].(map[string]interface{})
+	if !ok {
+		return config, nil
+	}
+	delete(storage, "tsdb")
+	if len(storage) == 0 {
+		delete(cfg, "storage")
+	}
+	data, err := yaml.Marshal(cfg)
+	return string(data), err
+}
+
+// reconcile runs the reconciliations of a Prometheus until it is no longer
+// requeued.
+func reconcile(ctx context.Context, r *controllers.PrometheusReconciler, cr *monitoringv1alpha1.Prometheus) error {
+	req := ctrl.Request{NamespacedName: client.ObjectKeyFromObject(cr)}
+	for i := 0; i < maxReconciles; i++ {
+		result, err := r.Reconcile(ctx, req)
+		if err != nil {
+			return err
+		}
+		// Periodic requeues, such as the renewal of certificates, are
+		// ignored
+		if !result.Requeue {
+			return nil
+		}
+	}
+	return fmt.Errorf("still requeued after %d reconciliations", maxReconciles)
+}
+
+// statusProblems returns the problems reported in the status of a
+// reconciled Prometheus: invalid inputs and rejected objects.
+func statusProblems(cr *monitoringv1alpha1.Prometheus) []string {
+	var problems []string
+	name := cr.Namespace + "/" + cr.Name
+	for _, cond := range cr.Status.Conditions {
+		invalidInput := cond.Type == monitoringv1alpha1.ConditionTypeAdditionalScrapeConfigsValid ||
+			cond.Type == monitoringv1alpha1.ConditionTypeWebConfigValid
+		if invalidInput && cond.Status == "False" {
+			problems = append(problems, fmt.Sprintf("Prometheus %s: %s: %s", name, cond.Reason, cond.Message))
+		}
+	}
+	selections := []struct {
+		kind      string
+		selection *monitoringv1alpha1.SelectedResources
+	}{
+		{"ScrapeConfig", cr.Status.ScrapeConfigs},
+		{"Probe", cr.Status.Probes},
+	}
+	for _, s := range selections {
+		if s.selection == nil {
+			continue
+		}
+		for _, rejected := range s.selection.Rejected {
+			problems = append(problems, fmt.Sprintf("Prometheus %s: %s %s/%s rejected: %s",
+				name, s.kind, rejected.Namespace, rejected.Name, rejected.Reason))
+		}
+	}
+	return problems
+}
+
+// loadObjects decodes the objects of the YAML documents of the files. The
+// namespaced objects without a namespace are put in the given one, and the
+// Prometheus resources of other versions are converted to v1alpha1 like the
+// API server does. The objects the API server would refuse, because of
+// unknown fields or of their OpenAPI schema, are left out and returned as
+// problems.
+func loadObjects(scheme *runtime.Scheme, files []string, namespace string) ([]client.Object, []string, error) {
+	decoder := serializer.NewCodecFactory(scheme, serializer.EnableStrict).UniversalDeserializer()
+	validators, err := loadSchemaValidators()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var objs []client.Object
+	var problems []string
+	for _, file := range files {
+		docs, err := readDocuments(file)
+		if err != nil {
+			return nil, nil, err
+		}
+		for i, doc := range docs {
+			decoded, gvk, err := decoder.Decode(doc, nil, nil)
+			strictErr := err
+			if err != nil && !runtime.IsStrictDecodingError(err) {
+				return nil, nil, fmt.Errorf("%s: document %d: %w", file, i+1, err)
+			}
+			obj, ok := decoded.(client.Object)
+			if !ok {
+				return nil, nil, fmt.Errorf("%s: document %d: unsupported kind %s", file, i+1, gvk.Kind)
+			}
+			if _, ok := obj.(*corev1.Namespace); !ok && obj.GetNamespace() == "" {
+				obj.SetNamespace(namespace)
+			}
+
+			name := gvk.Kind + " " + obj.GetName()
+			if obj.GetNamespace() != "" {
+				name = gvk.Kind + " " + obj.GetNamespace() + "/" + obj.GetName()
+			}
+			schemaErrs, err := validators.validate(*gvk, doc)
+			if err != nil {
+				return nil, nil, fmt.Errorf("%s: document %d: %w", file, i+1, err)
+			}
+			if strictErr != nil || len(schemaErrs) > 0 {
+				if strictErr != nil {
+					problems = append(problems, fmt.Sprintf("%s: %v", name, strictErr))
+				}
+				// The errors of the schema validation come in no particular
+				// order
+				sorted := make([]string, 0, len(schemaErrs))
+				for _, schemaErr := range schemaErrs {
+					sorted = append(sorted, fmt.Sprintf("%s: %v", name, schemaErr))
+				}
+				sort.Strings(sorted)
+				problems = append(problems, sorted...)
+				continue
+			}
+
+			if spoke, ok := obj.(conversion.Convertible); ok {
+				hub := &monitoringv1alpha1.Prometheus{}
+				if err := spoke.ConvertTo(hub); err != nil {
+					return nil, nil, fmt.Errorf("%s: document %d: %w", file, i+1, err)
+				}
+				obj = hub
+			}
+			objs = append(objs, obj)
+		}
+	}
+	return objs, problems, nil
+}
+
+// readDocuments returns the non-empty YAML documents of a file.
+func readDocuments(file string) ([][]byte, error) {
+	var in io.Reader = os.Stdin
+	if file != "-" {
+		f, err := os.Open(file)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		in = f
+	}
+
+	reader := utilyaml.NewYAMLReader(bufio.NewReader(in))
+	var docs [][]byte
+	for {
+		doc, err := reader.Read()
+		if err == io.EOF {
+			return docs, nil
+		} else if err != nil {
+			return nil, fmt.Errorf("%s: %w", file, err)
+		}
+		if len(bytes.TrimSpace(doc)) > 0 && !isComment(doc) {
+			docs = append(docs, doc)
+		}
+	}
+}
+
+// isComment reports whether a YAML document only holds comments.
+func isComment(doc []byte) bool {
+	for _, line := range strings.Split(string(doc), "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" && !strings.HasPrefix(line, "#") {
+			return false
+		}
+	}
+	return true
+}
+
+// keyForObject returns the key of an object of the fake cluster.
+func keyForObject(scheme *runtime.Scheme, obj client.Object) (objectKey, error) {
+	gvk, err := apiutil.GVKForObject(obj, scheme)
+	if err != nil {
+		return objectKey{}, err
+	}
+	return objectKey{gvk: gvk, NamespacedName: client.ObjectKeyFromObject(obj)}, nil
+}
+
+// outputObjects returns the objects of the given list type created by the
+// reconciliations, without the fields set by the fake cluster.
+func (res *renderResult) outputObjects(list client.ObjectList) ([]client.Object, error) {
+	if err := res.client.List(context.Background(), list); err != nil {
+		return nil, err
+	}
+	items, err := meta.ExtractList(list)
+	if err != nil {
+		return nil, err
+	}
+
+	var objs []client.Object
+	for _, item := range items {
+		obj := item.(client.Object)
+		key, err := keyForObject(res.scheme, obj)
+		if err != nil {
+			return nil, err
+		}
+		if res.inputs[key] {
+			continue
+		}
+		obj.GetObjectKind().SetGroupVersionKind(key.gvk)
+		obj.SetResourceVersion("")
+		objs = append(objs, obj)
+	}
+	return objs, nil
+}
@@ -0,0 +1,277 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// simplePrometheus is a Prometheus with a scrape config and raw scrape
+// configs in a Secret, holding the job raw scraping raw:9090.
+const simplePrometheus = `apiVersion: monitoring.mroque/v1alpha1
+kind: Prometheus
+metadata:
+  name: simple
+spec:
+  version: 2.40.0
+  additionalScrapeConfigs:
+    name: raw
+    key: scrape.yml
+  scrape_configs:
+  - job_name: nodes
+    static_configs:
+    - targets: [node-exporter:9100]
+---
+apiVersion: v1
+kind: Secret
+metadata:
+  name: raw
+data:
+  scrape.yml: LSBqb2JfbmFtZTogcmF3CiAgc3RhdGljX2NvbmZpZ3M6CiAgLSB0YXJnZXRzOiBbcmF3OjkwOTBdCg==
+`
+
+// renderFile renders the given YAML documents.
+func renderFile(t *testing.T, docs string) *renderResult {
+	t.Helper()
+	file := filepath.Join(t.TempDir(), "prometheus.yaml")
+	if err := os.WriteFile(file, []byte(docs), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	res, err := render(&options{files: fileList{file}, namespace: "default"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return res
+}
+
+func TestWriteManifests(t *testing.T) {
+	res := renderFile(t, simplePrometheus)
+	var out bytes.Buffer
+	if err := writeManifests(&out, res, false); err != nil {
+		t.Fatal(err)
+	}
+
+	var kinds []string
+	for _, line := range strings.Split(out.String(), "\n") {
+		if strings.HasPrefix(line, "kind: ") {
+			kinds = append(kinds, strings.TrimPrefix(line, "kind: "))
+		}
+	}
+	// The input Secret is left out
+	want := []string{"ClusterRole", "ClusterRoleBinding", "ServiceAccount", "ConfigMap", "Service", "Deployment"}
+	if strings.Join(kinds, ",") != strings.Join(want, ",") {
+		t.Errorf("got kinds %v, want %v", kinds, want)
+	}
+}
+
+func TestWriteConfigs(t *testing.T) {
+	res := renderFile(t, simplePrometheus)
+	var out bytes.Buffer
+	if err := writeConfigs(&out, res); err != nil {
+		t.Fatal(err)
+	}
+
+	want := `---
+# Source: ConfigMap default/simple-configmap
+scrape_configs:
+- job_name: nodes
+  static_configs:
+  - targets:
+    - node-exporter:9100
+- job_name: raw
+  static_configs:
+  - targets:
+    - raw:9090
+`
+	if out.String() != want {
+		t.Errorf("got\n%s\nwant\n%s", out.String(), want)
+	}
+}
+
+func TestReportProblems(t *testing.T) {
+	tests := []struct {
+		name string
+		docs string
+		want []string
+	}{{
+		name: "valid",
+		docs: simplePrometheus,
+	}, {
+		name: "unknown field",
+		docs: `apiVersion: monitoring.mroque/v1alpha1
+kind: Prometheus
+metadata:
+  name: unknown
+spec:
+  version: 2.40.0
+  scrape_configs: []
+  scrape_configz: []
+`,
+		want: []string{`Prometheus default/unknown: strict decoding error: unknown field "scrape_configz"`},
+	}, {
+		name: "schema",
+		docs: `apiVersion: monitoring.mroque/v1alpha1
+kind: Prometheus
+metadata:
+  name: schema
+spec:
+  version: 2.40.0
+  presets: [bogus]
+  scrape_configs:
+  - job_name: pods
+    kubernetes_sd_configs:
+    - role: foo
+`,
+		want: []string{
+			`Prometheus default/schema: spec.presets: Unsupported value: "bogus"`,
+			`Prometheus default/schema: spec.scrape_configs.kubernetes_sd_configs.role: Unsupported value: "foo"`,
+		},
+	}, {
+		name: "webhook",
+		docs: `apiVersion: monitoring.mroque/v1alpha1
+kind: Prometheus
+metadata:
+  name: webhook
+spec:
+  version: 2.40.0
+  scrape_configs:
+  - job_name: twice
+  - job_name: twice
+`,
+		want: []string{`Prometheus default/webhook: `},
+	}, {
+		name: "invalid input",
+		docs: `apiVersion: monitoring.mroque/v1alpha1
+kind: Prometheus
+metadata:
+  name: input
+spec:
+  version: 2.40.0
+  scrape_configs: []
+  additionalScrapeConfigs:
+    name: missing
+    key: scrape.yml
+`,
+		want: []string{`Prometheus default/input: SecretNotFound: Secret missing not found`},
+	}, {
+		name: "invalid raw scrape config",
+		docs: `apiVersion: monitoring.mroque/v1alpha1
+kind: Prometheus
+metadata:
+  name: raw
+spec:
+  version: 2.40.0
+  scrape_configs: []
+  additionalScrapeConfigs:
+    name: raw
+    key: scrape.yml
+---
+apiVersion: v1
+kind: Secret
+metadata:
+  name: raw
+data:
+  scrape.yml: LSBqb2JfbmFtZTogcmF3CiAgc2NyYXBlX2ludGVydmFsOiBvZnRlbgo=
+`,
+		want: []string{`Prometheus default/raw: InvalidScrapeConfig: scrape config "raw" of Secret raw is invalid: `},
+	}, {
+		name: "missing basic auth users",
+		docs: `apiVersion: monitoring.mroque/v1alpha1
+kind: Prometheus
+metadata:
+  name: web
+spec:
+  version: 2.40.0
+  scrape_configs: []
+  web:
+    basicAuthUsers:
+      name: users
+`,
+		want: []string{`Prometheus default/web: SecretNotFound: Secret users not found`},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res := renderFile(t, tt.docs)
+			var out bytes.Buffer
+			err := reportProblems(&out, res)
+			if len(tt.want) == 0 {
+				if err != nil {
+					t.Errorf("unexpected problems:\n%s", out.String())
+				}
+				return
+			}
+			if err != errLintFailed {
+				t.Errorf("got error %v, want %v", err, errLintFailed)
+			}
+			lines := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
+			if len(lines) != len(tt.want) {
+				t.Fatalf("got problems\n%s\nwant %d", out.String(), len(tt.want))
+			}
+			for i, line := range lines {
+				if !strings.HasPrefix(line, tt.want[i]) {
+					t.Errorf("got problem %q, want it to start with %q", line, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestConfigProblems(t *testing.T) {
+	// The TSDB settings are left to the validating webhook
+	res := renderFile(t, strings.Replace(simplePrometheus, "  version: 2.40.0\n",
+		"  version: 2.40.0\n  tsdb:\n    outOfOrderTimeWindow: 1h\n", 1))
+	if problems, err := res.configProblems(); err != nil || len(problems) != 0 {
+		t.Fatalf("got problems %v and error %v, want none", problems, err)
+	}
+
+	// A configuration Prometheus refuses, such as two selectors of the same
+	// role, is reported
+	cm := &corev1.ConfigMap{}
+	key := types.NamespacedName{Name: "simple-configmap", Namespace: "default"}
+	if err := res.client.Get(context.Background(), key, cm); err != nil {
+		t.Fatal(err)
+	}
+	cm.Data["prometheus.yml"] = `scrape_configs:
+- job_name: pods
+  kubernetes_sd_configs:
+  - role: pod
+    selectors:
+    - role: pod
+      label: app=a
+    - role: pod
+      label: app=b
+`
+	if err := res.client.Update(context.Background(), cm); err != nil {
+		t.Fatal(err)
+	}
+	problems, err := res.configProblems()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "ConfigMap default/simple-configmap: invalid prometheus.yml: "
+	if len(problems) != 1 || !strings.HasPrefix(problems[0], want) || !strings.Contains(problems[0], "duplicated selector role") {
+		t.Errorf("got problems %q, want one starting with %q", problems, want)
+	}
+}
@@ -0,0 +1,88 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"io/fs"
+
+	"github.com/ghodss/yaml"
+	"k8s.io/apiextensions-apiserver/pkg/apis/apiextensions"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apiextensions-apiserver/pkg/apiserver/validation"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/kube-openapi/pkg/validation/validate"
+
+	"github.com/marieroque/best-prometheus-operator-in-the-world/config/crd"
+)
+
+// schemaValidators validate the custom resources against the OpenAPI schemas
+// of their CustomResourceDefinition, as the API server does.
+type schemaValidators map[schema.GroupVersionKind]*validate.SchemaValidator
+
+// loadSchemaValidators returns the validators of every version of the
+// CustomResourceDefinitions generated in config/crd/bases.
+func loadSchemaValidators() (schemaValidators, error) {
+	files, err := fs.Glob(crd.Bases, "bases/*.yaml")
+	if err != nil {
+		return nil, err
+	}
+
+	validators := schemaValidators{}
+	for _, file := range files {
+		data, err := crd.Bases.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		definition := &apiextensionsv1.CustomResourceDefinition{}
+		if err := yaml.Unmarshal(data, definition); err != nil {
+			return nil, fmt.Errorf("%s: %w", file, err)
+		}
+		for _, version := range definition.Spec.Versions {
+			if version.Schema == nil {
+				continue
+			}
+			internal := &apiextensions.CustomResourceValidation{}
+			err := apiextensionsv1.Convert_v1_CustomResourceValidation_To_apiextensions_CustomResourceValidation(version.Schema, internal, nil)
+			if err != nil {
+				return nil, fmt.Errorf("%s: version %s: %w", file, version.Name, err)
+			}
+			validator, _, err := validation.NewSchemaValidator(internal)
+			if err != nil {
+				return nil, fmt.Errorf("%s: version %s: %w", file, version.Name, err)
+			}
+			gvk := schema.GroupVersionKind{Group: definition.Spec.Group, Version: version.Name, Kind: definition.Spec.Names.Kind}
+			validators[gvk] = validator
+		}
+	}
+	return validators, nil
+}
+
+// validate returns the errors of a YAML document against the schema of its
+// kind. Documents of other kinds aren't validated.
+func (v schemaValidators) validate(gvk schema.GroupVersionKind, doc []byte) (field.ErrorList, error) {
+	validator, ok := v[gvk]
+	if !ok {
+		return nil, nil
+	}
+	obj := map[string]interface{}{}
+	if err := yaml.Unmarshal(doc, &obj); err != nil {
+		return nil, err
+	}
+	return validation.ValidateCustomResource(nil, obj, validator), nil
+}
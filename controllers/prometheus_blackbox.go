@@ -0,0 +1,442 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/ghodss/yaml"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	ctrllog "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	monitoringv1alpha1 "github.com/marieroque/best-prometheus-operator-in-the-world/api/monitoring/v1alpha1"
+)
+
+// DefaultBlackboxExporterImage is the default image of the blackbox exporter
+// run for the Prometheuses selecting Probes.
+const DefaultBlackboxExporterImage = "quay.io/prometheus/blackbox-exporter:v0.22.0"
+
+const (
+	blackboxPort       = 9115
+	blackboxConfigDir  = "/etc/blackbox_exporter/"
+	blackboxConfigFile = "blackbox.yml"
+	// configHashAnnotation rolls the blackbox exporter pods out when their
+	// configuration changes, the exporter doesn't watch its file
+	configHashAnnotation = "monitoring.mroque/config-hash"
+)
+
+// blackboxConfig is the content of the blackbox.yml generated for a
+// Prometheus, with a module per selected Probe.
+type blackboxConfig struct {
+	Modules map[string]monitoringv1alpha1.ProbeModule `json:"modules"`
+}
+
+// loadProbes returns the Probe objects selected by a Prometheus along with
+// the selection reported in the status. Like ScrapeConfigs, the objects
+// that can't be added to the configuration are rejected.
+func (r *PrometheusReconciler) loadProbes(ctx context.Context, cr *monitoringv1alpha1.Prometheus, inputs *configInputs) ([]*monitoringv1alpha1.Probe, *monitoringv1alpha1.SelectedResources, error) {
+	log := ctrllog.FromContext(ctx)
+
+	if cr.Spec.ProbeSelector == nil {
+		return nil, nil, nil
+	}
+	selector, err := metav1.LabelSelectorAsSelector(cr.Spec.ProbeSelector)
+	if err != nil {
+		log.Error(err, "Invalid Probe selector", "Prometheus.Namespace", cr.Namespace, "Prometheus.Name", cr.Name)
+		return nil, nil, err
+	}
+	namespaces, err := r.selectedNamespaces(ctx, cr, cr.Spec.ProbeNamespaceSelector)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var items []monitoringv1alpha1.Probe
+	for _, ns := range namespaces {
+		list := &monitoringv1alpha1.ProbeList{}
+		err := r.List(ctx, list, client.InNamespace(ns), client.MatchingLabelsSelector{Selector: selector})
+		if err != nil {
+			log.Error(err, "Failed to list Probes", "Probe.Namespace", ns)
+			return nil, nil, err
+		}
+		items = append(items, list.Items...)
+	}
+	sort.Slice(items, func(i, j int) bool {
+		if items[i].Namespace != items[j].Namespace {
+			return items[i].Namespace < items[j].Namespace
+		}
+		return items[i].Name < items[j].Name
+	})
+
+	jobNames := inputs.jobNames(cr)
+	var probes []*monitoringv1alpha1.Probe
+	selection := &monitoringv1alpha1.SelectedResources{}
+	for i := range items {
+		probe := &items[i]
+		if r.namespaceDenied(probe.Namespace) {
+			continue
+		}
+		reject := func(reason string) {
+			selection.Rejected = append(selection.Rejected, monitoringv1alpha1.RejectedResource{
+				Namespace: probe.Namespace,
+				Name:      probe.Name,
+				Reason:    reason,
+			})
+		}
+		if err := cr.ValidateProbe(probe); err != nil {
+			reject(err.Error())
+			continue
+		}
+		if jobNames[probe.JobName()] {
+			reject(fmt.Sprintf("job name %q is already used", probe.JobName()))
+			continue
+		}
+		jobNames[probe.JobName()] = true
+
+		probes = append(probes, probe)
+		selection.Selected = append(selection.Selected, probe.Namespace+"/"+probe.Name)
+	}
+	return probes, selection, nil
+}
+
+// probeScrapeConfig returns the scrape config of a Probe for a shard. The
+// targets are passed to the blackbox exporter as the target parameter and
+// kept as the instance label. Sharding hashes the probed targets rather
+// than the address of the exporter.
+func probeScrapeConfig(cr *monitoringv1alpha1.Prometheus, probe *monitoringv1alpha1.Probe, shard, shards int32) *monitoringv1alpha1.ScrapeConfigSpec {
+	jobName := probe.JobName()
+	sc := &monitoringv1alpha1.ScrapeConfigSpec{
+		JobName: &jobName,
+		StaticConfigs: []*monitoringv1alpha1.StaticConfig{{
+			Targets: probe.Spec.Targets,
+			Labels:  probe.Spec.Labels,
+		}},
+	}
+	for _, rc := range probe.Spec.RelabelConfigs {
+		sc.RelabelConfigs = append(sc.RelabelConfigs, rc.DeepCopy())
+	}
+	if shards > 1 {
+		sc.RelabelConfigs = append(sc.RelabelConfigs, shardRelabelConfigs(shard, shards)...)
+	}
+
+	addressLabel := "__address__"
+	targetParam := "__param_target"
+	instanceLabel := "instance"
+	moduleParam := "__param_module"
+	module := probe.ModuleName()
+	metricsPathLabel := "__metrics_path__"
+	metricsPath := "/probe"
+	blackboxAddress := fmt.Sprintf("%s.%s.svc:%d", blackboxNameForPrometheus(cr), cr.Namespace, blackboxPort)
+	sc.RelabelConfigs = append(sc.RelabelConfigs, []*monitoringv1alpha1.RelabelConfig{{
+		SourceLabels: []string{addressLabel},
+		TargetLabel:  &targetParam,
+	}, {
+		SourceLabels: []string{targetParam},
+		TargetLabel:  &instanceLabel,
+	}, {
+		TargetLabel: &moduleParam,
+		Replacement: &module,
+	}, {
+		TargetLabel: &metricsPathLabel,
+		Replacement: &metricsPath,
+	}, {
+		TargetLabel: &addressLabel,
+		Replacement: &blackboxAddress,
+	}}...)
+	return sc
+}
+
+// reconcileBlackboxExporter makes sure the blackbox exporter of a Prometheus
+// selecting Probes exists and runs their modules, and removes it once the
+// Prometheus no longer selects Probes.
+func (r *PrometheusReconciler) reconcileBlackboxExporter(ctx context.Context, cr *monitoringv1alpha1.Prometheus, inputs *configInputs) (ctrl.Result, error) {
+	log := ctrllog.FromContext(ctx)
+
+	if cr.Spec.ProbeSelector == nil {
+		return ctrl.Result{}, r.deleteBlackboxExporter(ctx, cr)
+	}
+
+	cm, err := blackboxConfigMapForPrometheus(cr, inputs.probes)
+	if err != nil {
+		log.Error(err, "Failed to render the blackbox exporter configuration")
+		return ctrl.Result{}, err
+	}
+	foundConfigMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      cm.Name,
+			Namespace: cm.Namespace,
+		},
+	}
+	op, err := controllerutil.CreateOrUpdate(ctx, r.Client, foundConfigMap, func() error {
+		foundConfigMap.Labels = cm.Labels
+		foundConfigMap.Data = cm.Data
+		// Set Prometheus instance as the owner and controller
+		return ctrl.SetControllerReference(cr, foundConfigMap, r.Scheme)
+	})
+	if err != nil {
+		log.Error(err, "Failed to reconcile Configmap", "Configmap.Namespace", cm.Namespace, "Configmap.Name", cm.Name)
+		return ctrl.Result{}, err
+	}
+	if op != controllerutil.OperationResultNone {
+		log.Info("Configmap reconciled", "Configmap.Namespace", cm.Namespace, "Configmap.Name", cm.Name, "operation", op)
+	}
+
+	svc := blackboxServiceForPrometheus(cr)
+	foundService := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      svc.Name,
+			Namespace: svc.Namespace,
+		},
+	}
+	op, err = controllerutil.CreateOrUpdate(ctx, r.Client, foundService, func() error {
+		foundService.Labels = svc.Labels
+		foundService.Spec.Selector = svc.Spec.Selector
+		foundService.Spec.Ports = svc.Spec.Ports
+		// Set Prometheus instance as the owner and controller
+		return ctrl.SetControllerReference(cr, foundService, r.Scheme)
+	})
+	if err != nil {
+		log.Error(err, "Failed to reconcile Service", "Service.Namespace", svc.Namespace, "Service.Name", svc.Name)
+		return ctrl.Result{}, err
+	}
+	if op != controllerutil.OperationResultNone {
+		log.Info("Service reconciled", "Service.Namespace", svc.Namespace, "Service.Name", svc.Name, "operation", op)
+	}
+
+	dep := r.blackboxDeploymentForPrometheus(cr, cm, inputs.probes)
+	err = ctrl.SetControllerReference(cr, dep, r.Scheme)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	foundDeployment := &appsv1.Deployment{}
+	err = r.Get(ctx, types.NamespacedName{Name: dep.Name, Namespace: dep.Namespace}, foundDeployment)
+	if err != nil && errors.IsNotFound(err) {
+		log.Info("Creating a new Deployment", "Deployment.Namespace", dep.Namespace, "Deployment.Name", dep.Name)
+		err = r.Create(ctx, dep)
+		if err != nil {
+			log.Error(err, "Failed to create new Deployment", "Deployment.Namespace", dep.Namespace, "Deployment.Name", dep.Name)
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{Requeue: true}, nil
+	} else if err != nil {
+		log.Error(err, "Failed to get Deployment")
+		return ctrl.Result{}, err
+	}
+
+	if podTemplateChanged(&dep.Spec.Template, &foundDeployment.Spec.Template) {
+		foundDeployment.Spec.Template = dep.Spec.Template
+		log.Info("Updating Deployment", "Deployment.Namespace", foundDeployment.Namespace, "Deployment.Name", foundDeployment.Name)
+		err = r.Update(ctx, foundDeployment)
+		if err != nil {
+			log.Error(err, "Failed to update Deployment", "Deployment.Namespace", foundDeployment.Namespace, "Deployment.Name", foundDeployment.Name)
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{Requeue: true}, nil
+	}
+	return ctrl.Result{}, nil
+}
+
+// deleteBlackboxExporter removes the objects of the blackbox exporter of a
+// Prometheus, leaving alone the objects of the same name created by the user.
+func (r *PrometheusReconciler) deleteBlackboxExporter(ctx context.Context, cr *monitoringv1alpha1.Prometheus) error {
+	log := ctrllog.FromContext(ctx)
+
+	key := types.NamespacedName{Name: blackboxNameForPrometheus(cr), Namespace: cr.Namespace}
+	for _, obj := range []client.Object{
+		&appsv1.Deployment{},
+		&corev1.Service{},
+		&corev1.ConfigMap{},
+	} {
+		err := r.Get(ctx, key, obj)
+		if err != nil {
+			if errors.IsNotFound(err) {
+				continue
+			}
+			log.Error(err, "Failed to get blackbox exporter object", "Object.Namespace", key.Namespace, "Object.Name", key.Name)
+			return err
+		}
+		if !metav1.IsControlledBy(obj, cr) {
+			continue
+		}
+		err = r.Delete(ctx, obj)
+		if err != nil && !errors.IsNotFound(err) {
+			log.Error(err, "Failed to delete blackbox exporter object", "Object.Namespace", key.Namespace, "Object.Name", key.Name)
+			return err
+		}
+	}
+	return nil
+}
+
+// blackboxConfigMapForPrometheus returns the ConfigMap holding the blackbox
+// exporter configuration of a Prometheus
+func blackboxConfigMapForPrometheus(cr *monitoringv1alpha1.Prometheus, probes []*monitoringv1alpha1.Probe) (*corev1.ConfigMap, error) {
+	cfg := blackboxConfig{Modules: map[string]monitoringv1alpha1.ProbeModule{}}
+	for _, probe := range probes {
+		cfg.Modules[probe.ModuleName()] = probe.Spec.Module
+	}
+	dataJson, err := json.Marshal(&cfg)
+	if err != nil {
+		return nil, err
+	}
+	data, err := yaml.JSONToYAML(dataJson)
+	if err != nil {
+		return nil, err
+	}
+
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      blackboxNameForPrometheus(cr),
+			Namespace: cr.Namespace,
+			Labels:    labelsForBlackboxExporter(cr.Name),
+		},
+		Data: map[string]string{
+			blackboxConfigFile: string(data),
+		},
+	}, nil
+}
+
+// blackboxServiceForPrometheus returns the Service the Prometheus pods reach
+// the blackbox exporter through
+func blackboxServiceForPrometheus(cr *monitoringv1alpha1.Prometheus) *corev1.Service {
+	labels := labelsForBlackboxExporter(cr.Name)
+	return &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      blackboxNameForPrometheus(cr),
+			Namespace: cr.Namespace,
+			Labels:    labels,
+		},
+		Spec: corev1.ServiceSpec{
+			Selector: labels,
+			Ports: []corev1.ServicePort{{
+				Name:       "http",
+				Port:       blackboxPort,
+				TargetPort: intstr.FromString("http"),
+				Protocol:   corev1.ProtocolTCP,
+			}},
+		},
+	}
+}
+
+// blackboxDeploymentForPrometheus returns the blackbox exporter Deployment of
+// a Prometheus. The pods are given the NET_RAW capability when a Probe uses
+// the icmp prober.
+func (r *PrometheusReconciler) blackboxDeploymentForPrometheus(cr *monitoringv1alpha1.Prometheus, cm *corev1.ConfigMap, probes []*monitoringv1alpha1.Probe) *appsv1.Deployment {
+	labels := labelsForBlackboxExporter(cr.Name)
+	replicas := int32(1)
+	hash := sha256.Sum256([]byte(cm.Data[blackboxConfigFile]))
+
+	container := corev1.Container{
+		Name:            "blackbox-exporter",
+		Image:           r.blackboxExporterImage(),
+		ImagePullPolicy: cr.Spec.ImagePullPolicy,
+		Args:            []string{"--config.file=" + blackboxConfigDir + blackboxConfigFile},
+		Ports: []corev1.ContainerPort{{
+			Name:          "http",
+			ContainerPort: blackboxPort,
+			Protocol:      corev1.ProtocolTCP,
+		}},
+		VolumeMounts: []corev1.VolumeMount{{
+			Name:      "config",
+			MountPath: blackboxConfigDir,
+			ReadOnly:  true,
+		}},
+	}
+	for _, probe := range probes {
+		if probe.Spec.Module.Prober == "icmp" {
+			container.SecurityContext = &corev1.SecurityContext{
+				Capabilities: &corev1.Capabilities{Add: []corev1.Capability{"NET_RAW"}},
+			}
+			break
+		}
+	}
+
+	return &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      blackboxNameForPrometheus(cr),
+			Namespace: cr.Namespace,
+			Labels:    labels,
+		},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: &replicas,
+			Selector: &metav1.LabelSelector{
+				MatchLabels: labels,
+			},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: labels,
+					Annotations: map[string]string{
+						configHashAnnotation: hex.EncodeToString(hash[:]),
+					},
+				},
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{container},
+					Volumes: []corev1.Volume{{
+						Name: "config",
+						VolumeSource: corev1.VolumeSource{
+							ConfigMap: &corev1.ConfigMapVolumeSource{
+								LocalObjectReference: corev1.LocalObjectReference{Name: cm.Name},
+							},
+						},
+					}},
+					ImagePullSecrets: cr.Spec.ImagePullSecrets,
+				},
+			},
+		},
+	}
+}
+
+// blackboxExporterImage returns the image of the blackbox exporter containers
+func (r *PrometheusReconciler) blackboxExporterImage() string {
+	if r.BlackboxExporterImage == "" {
+		return DefaultBlackboxExporterImage
+	}
+	return r.BlackboxExporterImage
+}
+
+// blackboxNameForPrometheus returns the name of the blackbox exporter objects
+// of a Prometheus
+func blackboxNameForPrometheus(cr *monitoringv1alpha1.Prometheus) string {
+	return cr.Name + "-blackbox-exporter"
+}
+
+// labelsForBlackboxExporter returns the labels of the blackbox exporter
+// objects of the given prometheus CR name. They don't select the Prometheus
+// pods.
+func labelsForBlackboxExporter(name string) map[string]string {
+	return map[string]string{"app": "blackbox-exporter", "prometheus_cr": name}
+}
+
+// prometheusesSelectingProbes is a mapping function enqueuing the
+// Prometheuses that select Probes.
+func (r *PrometheusReconciler) prometheusesSelectingProbes(obj client.Object) []reconcile.Request {
+	return r.prometheusesMatching(func(cr *monitoringv1alpha1.Prometheus) bool {
+		return cr.Spec.ProbeSelector != nil
+	})
+}
@@ -0,0 +1,167 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	monitoringv1alpha1 "github.com/marieroque/best-prometheus-operator-in-the-world/api/monitoring/v1alpha1"
+)
+
+// newTestProbe returns a Probe of the given prober with the given labels.
+func newTestProbe(namespace, name, prober string, labels map[string]string) *monitoringv1alpha1.Probe {
+	return &monitoringv1alpha1.Probe{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace, Labels: labels},
+		Spec: monitoringv1alpha1.ProbeSpec{
+			Module:  monitoringv1alpha1.ProbeModule{Prober: prober},
+			Targets: []string{"example.com"},
+		},
+	}
+}
+
+func TestLoadProbes(t *testing.T) {
+	cr := newTestPrometheus("probes")
+	cr.Spec.ProbeSelector = &metav1.LabelSelector{MatchLabels: map[string]string{"team": "a"}}
+	team := map[string]string{"team": "a"}
+	invalid := newTestProbe(cr.Namespace, "invalid", "http", team)
+	invalid.Spec.Module.TCP = &monitoringv1alpha1.TCPProbe{}
+	r := newTestReconciler(t,
+		cr,
+		newTestProbe(cr.Namespace, "selected", "http", team),
+		newTestProbe(cr.Namespace, "other-team", "http", map[string]string{"team": "b"}),
+		invalid,
+	)
+
+	probes, selection, err := r.loadProbes(context.Background(), cr, &configInputs{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(probes) != 1 || probes[0].Name != "selected" {
+		t.Errorf("got probes %+v, want the selected one", probes)
+	}
+	if want := []string{cr.Namespace + "/selected"}; !reflect.DeepEqual(selection.Selected, want) {
+		t.Errorf("got selected %v, want %v", selection.Selected, want)
+	}
+	if len(selection.Rejected) != 1 || selection.Rejected[0].Name != "invalid" {
+		t.Errorf("got rejected %+v, want the invalid one", selection.Rejected)
+	}
+}
+
+func TestProbeScrapeConfig(t *testing.T) {
+	cr := newTestPrometheus("probes")
+	probe := newTestProbe("team-a", "site", "http", nil)
+
+	sc := probeScrapeConfig(cr, probe, 1, 2)
+	if *sc.JobName != "probe/team-a/site" {
+		t.Errorf("got job name %s, want probe/team-a/site", *sc.JobName)
+	}
+	if !reflect.DeepEqual(sc.StaticConfigs[0].Targets, probe.Spec.Targets) {
+		t.Errorf("got targets %v, want %v", sc.StaticConfigs[0].Targets, probe.Spec.Targets)
+	}
+	replacements := map[string]string{}
+	for _, rc := range sc.RelabelConfigs {
+		if rc.TargetLabel != nil && rc.Replacement != nil {
+			replacements[*rc.TargetLabel] = *rc.Replacement
+		}
+	}
+	for label, want := range map[string]string{
+		"__param_module":   "team-a_site",
+		"__metrics_path__": "/probe",
+		"__address__":      "probes-blackbox-exporter.default.svc:9115",
+	} {
+		if replacements[label] != want {
+			t.Errorf("got %s replaced by %q, want %q", label, replacements[label], want)
+		}
+	}
+	// The probed targets are sharded before being redirected to the
+	// exporter
+	shard := shardRelabelConfigs(1, 2)
+	if !reflect.DeepEqual(sc.RelabelConfigs[:len(shard)], shard) {
+		t.Errorf("got relabel configs %+v, want the shard ones first", sc.RelabelConfigs)
+	}
+}
+
+func TestBlackboxExporter(t *testing.T) {
+	ctx := context.Background()
+	cr := newTestPrometheus("probes")
+	cr.Spec.ProbeSelector = &metav1.LabelSelector{}
+	r := newTestReconciler(t, cr, newTestProbe(cr.Namespace, "ping", "icmp", nil))
+	crKey := types.NamespacedName{Name: cr.Name, Namespace: cr.Namespace}
+	key := types.NamespacedName{Name: blackboxNameForPrometheus(cr), Namespace: cr.Namespace}
+
+	if err := reconcileUntilDone(t, r, cr); err != nil {
+		t.Fatal(err)
+	}
+	cm := &corev1.ConfigMap{}
+	if err := r.Get(ctx, key, cm); err != nil {
+		t.Fatal(err)
+	}
+	if config := cm.Data[blackboxConfigFile]; !strings.Contains(config, cr.Namespace+"_ping:") {
+		t.Errorf("the module of the Probe is missing from the configuration:\n%s", config)
+	}
+	if err := r.Get(ctx, key, &corev1.Service{}); err != nil {
+		t.Errorf("the Service wasn't created: %v", err)
+	}
+	dep := &appsv1.Deployment{}
+	if err := r.Get(ctx, key, dep); err != nil {
+		t.Fatal(err)
+	}
+	container := dep.Spec.Template.Spec.Containers[0]
+	if container.SecurityContext == nil || !reflect.DeepEqual(container.SecurityContext.Capabilities.Add, []corev1.Capability{"NET_RAW"}) {
+		t.Errorf("got security context %+v, want NET_RAW for the icmp prober", container.SecurityContext)
+	}
+
+	// The exporter is removed once no Probe is selected, except for the
+	// objects of the user with the same name
+	found := &monitoringv1alpha1.Prometheus{}
+	if err := r.Get(ctx, crKey, found); err != nil {
+		t.Fatal(err)
+	}
+	found.Spec.ProbeSelector = nil
+	if err := r.Update(ctx, found); err != nil {
+		t.Fatal(err)
+	}
+	if err := reconcileUntilDone(t, r, cr); err != nil {
+		t.Fatal(err)
+	}
+	for _, obj := range []client.Object{&appsv1.Deployment{}, &corev1.Service{}, &corev1.ConfigMap{}} {
+		if err := r.Get(ctx, key, obj); !errors.IsNotFound(err) {
+			t.Errorf("the %T wasn't deleted: %v", obj, err)
+		}
+	}
+
+	user := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: key.Name, Namespace: key.Namespace}}
+	if err := r.Create(ctx, user); err != nil {
+		t.Fatal(err)
+	}
+	if err := reconcileUntilDone(t, r, cr); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Get(ctx, key, &corev1.ConfigMap{}); err != nil {
+		t.Errorf("the ConfigMap of the user was deleted: %v", err)
+	}
+}
@@ -0,0 +1,291 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"encoding/json"
+	"strconv"
+
+	"github.com/ghodss/yaml"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	monitoringv1alpha1 "github.com/marieroque/best-prometheus-operator-in-the-world/api/monitoring/v1alpha1"
+)
+
+// prometheusConfig is the content of the prometheus.yml generated for a
+// Prometheus shard. Agent mode only supports these sections, sections added
+// for rules or alerting must be left out of agent configurations.
+type prometheusConfig struct {
+	Global *globalConfig `json:"global,omitempty"`
+	// ScrapeConfigs holds the typed scrape configs followed by the raw ones
+	ScrapeConfigs []interface{}                           `json:"scrape_configs"`
+	RemoteWrite   []*monitoringv1alpha1.RemoteWriteConfig `json:"remote_write,omitempty"`
+	Storage       *storageConfig                          `json:"storage,omitempty"`
+}
+
+// storageConfig is the storage section of the generated prometheus.yml.
+type storageConfig struct {
+	TSDB *tsdbConfig `json:"tsdb,omitempty"`
+}
+
+// tsdbConfig holds the TSDB settings that are reloadable, and therefore set
+// in the configuration file rather than as flags.
+type tsdbConfig struct {
+	OutOfOrderTimeWindow *string `json:"out_of_order_time_window,omitempty"`
+}
+
+// globalConfig is the global section of the generated prometheus.yml.
+type globalConfig struct {
+	ExternalLabels map[string]string `json:"external_labels,omitempty"`
+}
+
+// shardLabel is the external label and pod label identifying a shard.
+const shardLabel = "shard"
+
+// configmapForPrometheus returns the prometheus ConfigMap object of a shard
+func (r *PrometheusReconciler) configmapForPrometheus(cr *monitoringv1alpha1.Prometheus, shard int32, inputs *configInputs) (*corev1.ConfigMap, error) {
+	labels := labelsForPrometheusShard(cr.Name, shard)
+	labels["app"] = configmapNameForShard(cr, shard)
+
+	data, err := r.renderConfig(cr, shard, inputs)
+	if err != nil {
+		return nil, err
+	}
+
+	cf := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      configmapNameForShard(cr, shard),
+			Namespace: cr.Namespace,
+			Labels:    labels,
+		},
+		Data: map[string]string{
+			"prometheus.yml": string(data),
+		},
+	}
+	return cf, nil
+}
+
+// renderConfig generates the prometheus.yml of a shard
+func (r *PrometheusReconciler) renderConfig(cr *monitoringv1alpha1.Prometheus, shard int32, inputs *configInputs) ([]byte, error) {
+	cfg := prometheusConfig{
+		RemoteWrite: cr.Spec.RemoteWrite,
+	}
+	scrapeConfigs := make([]*monitoringv1alpha1.ScrapeConfigSpec, 0, len(cr.Spec.ScrapeConfigs)+len(cr.Spec.Presets)+len(inputs.scrapeConfigs))
+	scrapeConfigs = append(scrapeConfigs, cr.Spec.ScrapeConfigs...)
+	scrapeConfigs = append(scrapeConfigs, r.presetScrapeConfigs(cr)...)
+	scrapeConfigs = append(scrapeConfigs, inputs.scrapeConfigs...)
+	if r.NamespaceScoped {
+		scrapeConfigs = scrapeConfigsForNamespace(scrapeConfigs, cr.Namespace)
+	}
+	if cr.Spec.DaemonSet {
+		scrapeConfigs = scrapeConfigsForLocalNode(scrapeConfigs)
+	}
+	rawScrapeConfigs := inputs.additionalScrapeConfigs
+	externalLabels := map[string]string{}
+	if shards := shardsForPrometheus(cr); shards > 1 {
+		scrapeConfigs = scrapeConfigsForShard(scrapeConfigs, shard, shards)
+		rawScrapeConfigs = rawScrapeConfigsForShard(rawScrapeConfigs, shard, shards)
+		externalLabels[shardLabel] = strconv.Itoa(int(shard))
+	}
+	for _, probe := range inputs.probes {
+		scrapeConfigs = append(scrapeConfigs, probeScrapeConfig(cr, probe, shard, shardsForPrometheus(cr)))
+	}
+	cfg.ScrapeConfigs = make([]interface{}, 0, len(scrapeConfigs)+len(rawScrapeConfigs))
+	for _, sc := range scrapeConfigs {
+		rendered, err := renderScrapeConfig(sc)
+		if err != nil {
+			return nil, err
+		}
+		cfg.ScrapeConfigs = append(cfg.ScrapeConfigs, rendered)
+	}
+	for _, sc := range rawScrapeConfigs {
+		cfg.ScrapeConfigs = append(cfg.ScrapeConfigs, sc)
+	}
+	if cr.Spec.Thanos != nil {
+		// Thanos Query deduplicates the series of the replicas of a
+		// Prometheus through these labels
+		externalLabels["prometheus"] = cr.Namespace + "/" + cr.Name
+		externalLabels["prometheus_replica"] = "$(POD_NAME)"
+	}
+	if cr.Spec.TSDB != nil && cr.Spec.TSDB.OutOfOrderTimeWindow != nil {
+		cfg.Storage = &storageConfig{
+			TSDB: &tsdbConfig{OutOfOrderTimeWindow: cr.Spec.TSDB.OutOfOrderTimeWindow},
+		}
+	}
+	if len(externalLabels) > 0 {
+		cfg.Global = &globalConfig{ExternalLabels: externalLabels}
+	}
+
+	dataJson, err := json.Marshal(&cfg)
+	if err != nil {
+		return nil, err
+	}
+	return yaml.JSONToYAML(dataJson)
+}
+
+// renderScrapeConfig returns a typed scrape config in the format of the
+// Prometheus configuration. The file service discoveries reference the
+// ConfigMaps mounted in the pods, and are replaced with their files.
+func renderScrapeConfig(sc *monitoringv1alpha1.ScrapeConfigSpec) (interface{}, error) {
+	if len(sc.FileSDConfigs) == 0 {
+		return sc, nil
+	}
+	dataJson, err := json.Marshal(sc)
+	if err != nil {
+		return nil, err
+	}
+	rendered := map[string]interface{}{}
+	if err := json.Unmarshal(dataJson, &rendered); err != nil {
+		return nil, err
+	}
+	fileSDConfigs := make([]map[string]interface{}, 0, len(sc.FileSDConfigs))
+	for _, sd := range sc.FileSDConfigs {
+		fileSD := map[string]interface{}{"files": fileSDFiles(sd)}
+		if sd.RefreshInterval != nil {
+			fileSD["refresh_interval"] = *sd.RefreshInterval
+		}
+		fileSDConfigs = append(fileSDConfigs, fileSD)
+	}
+	rendered["file_sd_configs"] = fileSDConfigs
+	return rendered, nil
+}
+
+// fileSDFiles returns the paths of the files a file service discovery reads
+// from its mounted ConfigMap
+func fileSDFiles(sd *monitoringv1alpha1.FileSDConfig) []string {
+	dir := fileSDDir + sd.ConfigMap + "/"
+	if len(sd.Keys) == 0 {
+		return []string{dir + "*.json", dir + "*.yml", dir + "*.yaml"}
+	}
+	files := make([]string, 0, len(sd.Keys))
+	for _, key := range sd.Keys {
+		files = append(files, dir+key)
+	}
+	return files
+}
+
+// scrapeConfigsForNamespace returns a copy of the scrape configs where every
+// kubernetes service discovery is restricted to the given namespace.
+func scrapeConfigsForNamespace(scrapeConfigs []*monitoringv1alpha1.ScrapeConfigSpec, namespace string) []*monitoringv1alpha1.ScrapeConfigSpec {
+	restricted := make([]*monitoringv1alpha1.ScrapeConfigSpec, 0, len(scrapeConfigs))
+	for _, sc := range scrapeConfigs {
+		sc = sc.DeepCopy()
+		for _, sd := range sc.K8SSDConfigs {
+			sd.Namespaces = &monitoringv1alpha1.K8SSDNamespaces{Names: []string{namespace}}
+		}
+		restricted = append(restricted, sc)
+	}
+	return restricted
+}
+
+// scrapeConfigsForLocalNode returns a copy of the scrape configs where the pod
+// and node kubernetes service discoveries only select the objects of the node
+// Prometheus runs on. The node name is expanded by the config reloader. The
+// field selector is merged into the selector of the role when there is one,
+// Prometheus rejecting several selectors of the same role.
+func scrapeConfigsForLocalNode(scrapeConfigs []*monitoringv1alpha1.ScrapeConfigSpec) []*monitoringv1alpha1.ScrapeConfigSpec {
+	local := make([]*monitoringv1alpha1.ScrapeConfigSpec, 0, len(scrapeConfigs))
+	for _, sc := range scrapeConfigs {
+		sc = sc.DeepCopy()
+		for _, sd := range sc.K8SSDConfigs {
+			if sd.Role == nil {
+				continue
+			}
+			var field string
+			switch *sd.Role {
+			case "pod":
+				field = "spec.nodeName=$(NODE_NAME)"
+			case "node":
+				field = "metadata.name=$(NODE_NAME)"
+			default:
+				continue
+			}
+			selector := roleSelector(sd, *sd.Role)
+			if selector.Field != nil && *selector.Field != "" {
+				field = *selector.Field + "," + field
+			}
+			selector.Field = &field
+		}
+		local = append(local, sc)
+	}
+	return local
+}
+
+// roleSelector returns the selector of a role of a kubernetes service
+// discovery, and adds it when there is none.
+func roleSelector(sd *monitoringv1alpha1.K8SSDConfig, role string) *monitoringv1alpha1.K8SSDSelector {
+	for _, selector := range sd.Selectors {
+		if selector.Role != nil && *selector.Role == role {
+			return selector
+		}
+	}
+	selector := &monitoringv1alpha1.K8SSDSelector{Role: &role}
+	sd.Selectors = append(sd.Selectors, selector)
+	return selector
+}
+
+// scrapeConfigsForShard returns a copy of the scrape configs that only keeps
+// the targets whose address hashes to the given shard.
+func scrapeConfigsForShard(scrapeConfigs []*monitoringv1alpha1.ScrapeConfigSpec, shard, shards int32) []*monitoringv1alpha1.ScrapeConfigSpec {
+	sharded := make([]*monitoringv1alpha1.ScrapeConfigSpec, 0, len(scrapeConfigs))
+	for _, sc := range scrapeConfigs {
+		sc = sc.DeepCopy()
+		sc.RelabelConfigs = append(sc.RelabelConfigs, shardRelabelConfigs(shard, shards)...)
+		sharded = append(sharded, sc)
+	}
+	return sharded
+}
+
+// rawScrapeConfigsForShard is the scrapeConfigsForShard of the raw scrape
+// configs.
+func rawScrapeConfigsForShard(scrapeConfigs []map[string]interface{}, shard, shards int32) []map[string]interface{} {
+	sharded := make([]map[string]interface{}, 0, len(scrapeConfigs))
+	for _, sc := range scrapeConfigs {
+		sc = runtime.DeepCopyJSON(sc)
+		relabelConfigs, _ := sc["relabel_configs"].([]interface{})
+		for _, rc := range shardRelabelConfigs(shard, shards) {
+			relabelConfigs = append(relabelConfigs, rc)
+		}
+		sc["relabel_configs"] = relabelConfigs
+		sharded = append(sharded, sc)
+	}
+	return sharded
+}
+
+// shardRelabelConfigs returns the relabelings dropping the targets whose
+// address doesn't hash to the given shard.
+func shardRelabelConfigs(shard, shards int32) []*monitoringv1alpha1.RelabelConfig {
+	modulus := uint64(shards)
+	hashAction := "hashmod"
+	keepAction := "keep"
+	addressLabel := "__address__"
+	hashLabel := "__tmp_hash"
+	regex := strconv.Itoa(int(shard))
+
+	return []*monitoringv1alpha1.RelabelConfig{{
+		SourceLabels: []string{addressLabel},
+		Modulus:      &modulus,
+		TargetLabel:  &hashLabel,
+		Action:       &hashAction,
+	}, {
+		SourceLabels: []string{hashLabel},
+		Regex:        &regex,
+		Action:       &keepAction,
+	}}
+}
@@ -0,0 +1,109 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"testing"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+
+	monitoringv1alpha1 "github.com/marieroque/best-prometheus-operator-in-the-world/api/monitoring/v1alpha1"
+)
+
+func TestShardRelabelConfigs(t *testing.T) {
+	tests := []struct {
+		shard, shards int32
+		regex         string
+	}{
+		{shard: 0, shards: 1, regex: "0"},
+		{shard: 0, shards: 3, regex: "0"},
+		{shard: 2, shards: 3, regex: "2"},
+	}
+	for _, tt := range tests {
+		rcs := shardRelabelConfigs(tt.shard, tt.shards)
+		if len(rcs) != 2 {
+			t.Fatalf("shard %d/%d: got %d relabel configs, want 2", tt.shard, tt.shards, len(rcs))
+		}
+		hash, keep := rcs[0], rcs[1]
+		if *hash.Action != "hashmod" || *hash.Modulus != uint64(tt.shards) ||
+			len(hash.SourceLabels) != 1 || hash.SourceLabels[0] != "__address__" {
+			t.Errorf("shard %d/%d: unexpected hash relabeling %+v", tt.shard, tt.shards, hash)
+		}
+		if *keep.Action != "keep" || *keep.Regex != tt.regex ||
+			len(keep.SourceLabels) != 1 || keep.SourceLabels[0] != *hash.TargetLabel {
+			t.Errorf("shard %d/%d: unexpected keep relabeling %+v", tt.shard, tt.shards, keep)
+		}
+	}
+}
+
+func TestScrapeConfigsForShard(t *testing.T) {
+	jobA, jobB := "a", "b"
+	dropAction := "drop"
+	scrapeConfigs := []*monitoringv1alpha1.ScrapeConfigSpec{
+		{JobName: &jobA},
+		{JobName: &jobB, RelabelConfigs: []*monitoringv1alpha1.RelabelConfig{{Action: &dropAction}}},
+	}
+	original := []*monitoringv1alpha1.ScrapeConfigSpec{scrapeConfigs[0].DeepCopy(), scrapeConfigs[1].DeepCopy()}
+
+	sharded := scrapeConfigsForShard(scrapeConfigs, 1, 2)
+
+	if !equality.Semantic.DeepEqual(scrapeConfigs, original) {
+		t.Errorf("the scrape configs given were modified")
+	}
+	if len(sharded) != len(scrapeConfigs) {
+		t.Fatalf("got %d scrape configs, want %d", len(sharded), len(scrapeConfigs))
+	}
+	shardRelabelings := shardRelabelConfigs(1, 2)
+	for i, sc := range sharded {
+		want := append(original[i].DeepCopy().RelabelConfigs, shardRelabelings...)
+		if *sc.JobName != *original[i].JobName || !equality.Semantic.DeepEqual(sc.RelabelConfigs, want) {
+			t.Errorf("scrape config %d: got relabel configs %+v, want %+v", i, sc.RelabelConfigs, want)
+		}
+	}
+}
+
+func TestScrapeConfigsForLocalNode(t *testing.T) {
+	pod, node, service := "pod", "node", "service"
+	label, field := "app=web", "status.phase=Running"
+	scrapeConfigs := []*monitoringv1alpha1.ScrapeConfigSpec{{
+		K8SSDConfigs: []*monitoringv1alpha1.K8SSDConfig{
+			{Role: &pod, Selectors: []*monitoringv1alpha1.K8SSDSelector{{Role: &pod, Label: &label, Field: &field}}},
+			{Role: &node, Selectors: []*monitoringv1alpha1.K8SSDSelector{{Role: &node, Label: &label}}},
+			{Role: &pod},
+			{Role: &service, Selectors: []*monitoringv1alpha1.K8SSDSelector{{Role: &service, Label: &label}}},
+		},
+	}}
+	original := scrapeConfigs[0].DeepCopy()
+
+	local := scrapeConfigsForLocalNode(scrapeConfigs)
+
+	if !equality.Semantic.DeepEqual(scrapeConfigs[0], original) {
+		t.Errorf("the scrape configs given were modified")
+	}
+	want := []*monitoringv1alpha1.K8SSDSelector{
+		{Role: &pod, Label: &label, Field: stringPtr("status.phase=Running,spec.nodeName=$(NODE_NAME)")},
+		{Role: &node, Label: &label, Field: stringPtr("metadata.name=$(NODE_NAME)")},
+		{Role: &pod, Field: stringPtr("spec.nodeName=$(NODE_NAME)")},
+		{Role: &service, Label: &label},
+	}
+	for i, sd := range local[0].K8SSDConfigs {
+		// Prometheus rejects several selectors of the same role
+		if len(sd.Selectors) != 1 || !equality.Semantic.DeepEqual(sd.Selectors[0], want[i]) {
+			t.Errorf("service discovery %d: got selectors %+v, want %+v", i, sd.Selectors, want[i])
+		}
+	}
+}
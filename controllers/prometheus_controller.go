@@ -18,36 +18,84 @@ package controllers
 
 import (
 	"context"
-	"encoding/json"
-	"github.com/ghodss/yaml"
+	goerrors "errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/version"
 
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	ctrllog "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 
-	monitoringv1alpha1 "github.com/marieroque/best-prometheus-operator-in-the-world/api/v1alpha1"
+	monitoringv1alpha1 "github.com/marieroque/best-prometheus-operator-in-the-world/api/monitoring/v1alpha1"
 )
 
 // PrometheusReconciler reconciles a Prometheus object
 type PrometheusReconciler struct {
 	client.Client
 	Scheme *runtime.Scheme
+	// NamespaceScoped is set when the operator only watches some namespaces
+	// and isn't allowed to manage cluster-scoped objects. Prometheus is then
+	// given a Role and only discovers targets in its own namespace.
+	NamespaceScoped bool
+	// DenyNamespaces lists the namespaces whose Prometheus resources are
+	// ignored by the operator.
+	DenyNamespaces []string
+	// PrometheusImage, ReloaderImage and ThanosImage override the default
+	// images, for instance to pull them from a mirror registry. The
+	// Prometheus and Thanos images are repositories tagged with the version
+	// requested by the spec.
+	PrometheusImage string
+	ReloaderImage   string
+	ThanosImage     string
+	// BlackboxExporterImage overrides the default image of the blackbox
+	// exporter checking the Probes.
+	BlackboxExporterImage string
+	// DryRun makes the reconciler report the changes it would make in the
+	// logs and in the DryRunChangesAnnotation of the Prometheus instead of
+	// applying them. The finalizer of a deleted Prometheus is still removed,
+	// leaving its cluster-scoped objects in place.
+	DryRun bool
 }
 
-const container_image = "quay.io/prometheus/prometheus"
+// prometheusFinalizer lets the operator clean up the cluster-scoped objects
+// of a Prometheus before it is removed.
+const prometheusFinalizer = "monitoring.mroque/finalizer"
 
 //+kubebuilder:rbac:groups=monitoring.mroque,resources=prometheuses,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=monitoring.mroque,resources=prometheuses/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=monitoring.mroque,resources=prometheuses/finalizers,verbs=update
+//+kubebuilder:rbac:groups=monitoring.mroque,resources=scrapeconfigs,verbs=get;list;watch
+//+kubebuilder:rbac:groups=monitoring.mroque,resources=probes,verbs=get;list;watch
 //+kubebuilder:rbac:groups=apps,resources=deployments,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=apps,resources=daemonsets,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=core,resources=configmaps,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=core,resources=pods,verbs=get;list;watch
+//+kubebuilder:rbac:groups=core,resources=namespaces,verbs=get;list;watch
+//+kubebuilder:rbac:groups=core,resources=services,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=core,resources=secrets,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=networking.k8s.io,resources=ingresses,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=networking.k8s.io,resources=networkpolicies,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=policy,resources=poddisruptionbudgets,verbs=get;list;watch;create;update;patch;delete
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
@@ -77,31 +125,227 @@ func (r *PrometheusReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 		return ctrl.Result{}, err
 	}
 
-	// Check if the configmap already exists, if not create a new one
-	foundConfigMap := &corev1.ConfigMap{}
-	err = r.Get(ctx, types.NamespacedName{Name: prometheus.Name + "-configmap", Namespace: prometheus.Namespace}, foundConfigMap)
-	if err != nil && errors.IsNotFound(err) {
-		// Define a new configmap
-		cfm := r.configmapForPrometheus(prometheus)
-		log.Info("Creating a new Configmap", "Configmap.Namespace", cfm.Namespace, "Configmap.Name", cfm.Name)
-		err = r.Create(ctx, cfm)
+	// Check if the Prometheus instance is marked to be deleted, which is
+	// indicated by the deletion timestamp being set.
+	if prometheus.GetDeletionTimestamp() != nil {
+		if controllerutil.ContainsFinalizer(prometheus, prometheusFinalizer) {
+			// Run finalization logic for prometheusFinalizer. If the
+			// finalization logic fails, don't remove the finalizer so
+			// that we can retry during the next reconciliation.
+			if err := r.finalizePrometheus(ctx, prometheus); err != nil {
+				return ctrl.Result{}, err
+			}
+
+			// Remove prometheusFinalizer. Once all finalizers have been
+			// removed, the object will be deleted.
+			controllerutil.RemoveFinalizer(prometheus, prometheusFinalizer)
+			err = r.Update(ctx, prometheus)
+			if err != nil {
+				log.Error(err, "Failed to remove finalizer")
+				return ctrl.Result{}, err
+			}
+		}
+		return ctrl.Result{}, nil
+	}
+
+	// Leave the managed objects alone while the instance is paused, only
+	// report it in the status
+	if prometheus.Spec.Paused {
+		log.Info("Prometheus is paused, skipping reconciliation")
+		err = r.setCondition(ctx, prometheus, metav1.Condition{
+			Type:    monitoringv1alpha1.ConditionTypePaused,
+			Status:  metav1.ConditionTrue,
+			Reason:  "Paused",
+			Message: "Reconciliation is paused by spec.paused",
+		})
+		return ctrl.Result{}, err
+	}
+	err = r.setCondition(ctx, prometheus, metav1.Condition{
+		Type:    monitoringv1alpha1.ConditionTypePaused,
+		Status:  metav1.ConditionFalse,
+		Reason:  "Reconciling",
+		Message: "Managed objects are reconciled against the spec",
+	})
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	// Add finalizer for this CR
+	if !controllerutil.ContainsFinalizer(prometheus, prometheusFinalizer) {
+		controllerutil.AddFinalizer(prometheus, prometheusFinalizer)
+		err = r.Update(ctx, prometheus)
 		if err != nil {
-			log.Error(err, "Failed to create new Configmap", "Configmap.Namespace", cfm.Namespace, "Configmap.Name", cfm.Name)
+			log.Error(err, "Failed to add finalizer")
 			return ctrl.Result{}, err
 		}
-		// Configmap created successfully - return and requeue
-		return ctrl.Result{Requeue: true}, nil
+	}
+
+	// Reconcile the managed objects and report the outcome in the status.
+	// Invalid inputs are not retried, the watch on the objects triggers a
+	// new reconciliation once they are fixed.
+	result, err := r.reconcileObjects(ctx, prometheus)
+	var invalid *invalidInputError
+	if goerrors.As(err, &invalid) {
+		log.Info("Invalid configuration input", "reason", invalid.reason, "message", invalid.message)
+		err = r.setCondition(ctx, prometheus, metav1.Condition{
+			Type:    invalid.conditionType,
+			Status:  metav1.ConditionFalse,
+			Reason:  invalid.reason,
+			Message: invalid.message,
+		})
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{}, r.setCondition(ctx, prometheus, metav1.Condition{
+			Type:    monitoringv1alpha1.ConditionTypeReconciled,
+			Status:  metav1.ConditionFalse,
+			Reason:  invalid.reason,
+			Message: invalid.message,
+		})
 	} else if err != nil {
-		log.Error(err, "Failed to get Configmap")
+		// The error is retried whether the status is updated or not
+		_ = r.setCondition(ctx, prometheus, metav1.Condition{
+			Type:    monitoringv1alpha1.ConditionTypeReconciled,
+			Status:  metav1.ConditionFalse,
+			Reason:  "ReconcileFailed",
+			Message: err.Error(),
+		})
+		return ctrl.Result{}, err
+	}
+	if result.Requeue {
+		return result, nil
+	}
+	return ctrl.Result{}, r.setCondition(ctx, prometheus, metav1.Condition{
+		Type:    monitoringv1alpha1.ConditionTypeReconciled,
+		Status:  metav1.ConditionTrue,
+		Reason:  "ReconcileSucceeded",
+		Message: "Managed objects match the spec",
+	})
+}
+
+// reconcileObjects makes sure the objects managed for a Prometheus exist and
+// match the spec, and records their state in the status.
+func (r *PrometheusReconciler) reconcileObjects(ctx context.Context, cr *monitoringv1alpha1.Prometheus) (ctrl.Result, error) {
+	// Ensure the service account and permissions used by the Prometheus pods
+	err := r.reconcileRBAC(ctx, cr)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	// Ensure the Service exposing the Prometheus pods
+	err = r.reconcileService(ctx, cr)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	// Ensure the Ingress exposing the Service
+	err = r.reconcileIngress(ctx, cr)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	// Ensure the NetworkPolicy isolating the pods
+	err = r.reconcileNetworkPolicy(ctx, cr)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	// Ensure the configuration securing the web endpoint. An invalid Secret
+	// of basic auth users keeps the last rendered users, and is reported
+	// once the workloads are reconciled.
+	var webInvalid *invalidInputError
+	err = r.reconcileWeb(ctx, cr)
+	if err != nil && !goerrors.As(err, &webInvalid) {
+		return ctrl.Result{}, err
+	}
+
+	// Load the objects the configuration is rendered from. An invalid Secret
+	// of additional scrape configs leaves the running configuration
+	// untouched, and is reported once the workloads are reconciled.
+	inputs, err := r.loadConfigInputs(ctx, cr)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	err = r.updateInputsStatus(ctx, cr, inputs)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	err = r.updatePresetsStatus(ctx, cr)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	result, err := r.reconcileBlackboxExporter(ctx, cr, inputs)
+	if err != nil || result.Requeue {
+		return result, err
+	}
+
+	// Reconcile the workloads, either a DaemonSet or a Deployment per shard
+	shards := shardsForPrometheus(cr)
+	if cr.Spec.DaemonSet {
+		result, err := r.reconcileDaemonSet(ctx, cr, inputs)
+		if err != nil || result.Requeue {
+			return result, err
+		}
+	} else {
+		for shard := int32(0); shard < shards; shard++ {
+			result, err := r.reconcileShard(ctx, cr, shard, inputs)
+			if err != nil || result.Requeue {
+				return result, err
+			}
+		}
+	}
+
+	// Remove the workloads that are no longer requested. The remaining shards
+	// keep running and only reload their configuration.
+	err = r.deleteStaleWorkloads(ctx, cr, shards)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	err = r.updateWorkloadStatus(ctx, cr, shards)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	err = r.updateThanosStatus(ctx, cr)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	if inputs.invalid != nil {
+		return ctrl.Result{}, inputs.invalid
+	}
+	if webInvalid != nil {
+		return ctrl.Result{}, webInvalid
+	}
+	return ctrl.Result{}, nil
+}
+
+// reconcileShard makes sure the ConfigMap and the Deployment of a shard exist
+// and match the spec.
+func (r *PrometheusReconciler) reconcileShard(ctx context.Context, cr *monitoringv1alpha1.Prometheus, shard int32, inputs *configInputs) (ctrl.Result, error) {
+	log := ctrllog.FromContext(ctx)
+
+	err := r.reconcileConfigMap(ctx, cr, shard, inputs)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	err = r.reconcilePodDisruptionBudget(ctx, cr, shard)
+	if err != nil {
 		return ctrl.Result{}, err
 	}
 
 	// Check if the deployment already exists, if not create a new one
+	dep, err := r.deploymentForPrometheus(cr, shard)
+	if err != nil {
+		log.Error(err, "Failed to merge the pod overrides", "Shard", shard)
+		return ctrl.Result{}, err
+	}
 	foundDeployment := &appsv1.Deployment{}
-	err = r.Get(ctx, types.NamespacedName{Name: prometheus.Name, Namespace: prometheus.Namespace}, foundDeployment)
+	err = r.Get(ctx, types.NamespacedName{Name: dep.Name, Namespace: dep.Namespace}, foundDeployment)
 	if err != nil && errors.IsNotFound(err) {
-		// Define a new deployment
-		dep := r.deploymentForPrometheus(prometheus)
 		log.Info("Creating a new Deployment", "Deployment.Namespace", dep.Namespace, "Deployment.Name", dep.Name)
 		err = r.Create(ctx, dep)
 		if err != nil {
@@ -115,11 +359,32 @@ func (r *PrometheusReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 		return ctrl.Result{}, err
 	}
 
+	// The selector of a deployment is immutable, a different one can only be
+	// applied by recreating the deployment
+	if !equality.Semantic.DeepEqual(foundDeployment.Spec.Selector, dep.Spec.Selector) {
+		log.Info("Recreating Deployment with a new selector", "Deployment.Namespace", foundDeployment.Namespace, "Deployment.Name", foundDeployment.Name)
+		err = r.Delete(ctx, foundDeployment)
+		if err != nil {
+			log.Error(err, "Failed to delete Deployment", "Deployment.Namespace", foundDeployment.Namespace, "Deployment.Name", foundDeployment.Name)
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{Requeue: true}, nil
+	}
+
 	// This point, we have the deployment object created
-	// Ensure the image version is same as the spec
-	img := container_image + ":v" + *prometheus.Spec.Version
-	if foundDeployment.Spec.Template.Spec.Containers[0].Image != img {
-		foundDeployment.Spec.Template.Spec.Containers[0].Image = img
+	// Ensure the labels, the replicas and the pod template are same as the
+	// spec. Deployments created before sharding lack the shard label.
+	if !equality.Semantic.DeepDerivative(dep.Labels, foundDeployment.Labels) ||
+		*foundDeployment.Spec.Replicas != *dep.Spec.Replicas ||
+		podTemplateChanged(&dep.Spec.Template, &foundDeployment.Spec.Template) {
+		if foundDeployment.Labels == nil {
+			foundDeployment.Labels = map[string]string{}
+		}
+		for k, v := range dep.Labels {
+			foundDeployment.Labels[k] = v
+		}
+		foundDeployment.Spec.Replicas = dep.Spec.Replicas
+		foundDeployment.Spec.Template = dep.Spec.Template
 		log.Info("Updating Deployment", "Deployment.Namespace", foundDeployment.Namespace, "Deployment.Name", foundDeployment.Name)
 		err = r.Update(ctx, foundDeployment)
 		if err != nil {
@@ -134,96 +399,414 @@ func (r *PrometheusReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 	return ctrl.Result{}, nil
 }
 
-// deploymentForPrometheus returns a prometheus Deployment object
-func (r *PrometheusReconciler) deploymentForPrometheus(cr *monitoringv1alpha1.Prometheus) *appsv1.Deployment {
-	ls := labelsForPrometheus(cr.Name)
+// reconcileConfigMap creates or updates the ConfigMap holding the
+// configuration of a shard. Changes are picked up by the config reloader
+// without restarting Prometheus.
+func (r *PrometheusReconciler) reconcileConfigMap(ctx context.Context, cr *monitoringv1alpha1.Prometheus, shard int32, inputs *configInputs) error {
+	log := ctrllog.FromContext(ctx)
 
-	dep := &appsv1.Deployment{
+	cfm, err := r.configmapForPrometheus(cr, shard, inputs)
+	if err != nil {
+		log.Error(err, "Failed to render Prometheus configuration", "Shard", shard)
+		return err
+	}
+	foundConfigMap := &corev1.ConfigMap{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      cr.Name,
-			Namespace: cr.Namespace,
-		},
-		Spec: appsv1.DeploymentSpec{
-			Selector: &metav1.LabelSelector{
-				MatchLabels: ls,
-			},
-			Template: corev1.PodTemplateSpec{
-				ObjectMeta: metav1.ObjectMeta{
-					Labels: ls,
-				},
-				Spec: corev1.PodSpec{
-					Containers: []corev1.Container{{
-						Name:  "prometheus",
-						Image: container_image + ":v" + *cr.Spec.Version,
-						Args:  []string{"--config.file=/etc/prometheus/prometheus.yml"},
-						VolumeMounts: []corev1.VolumeMount{{
-							MountPath: "/etc/prometheus/",
-							Name:      "prometheus-config-volume",
-						}},
-					}},
-					Volumes: []corev1.Volume{{
-						Name: "prometheus-config-volume",
-						VolumeSource: corev1.VolumeSource{
-							ConfigMap: &corev1.ConfigMapVolumeSource{
-								LocalObjectReference: corev1.LocalObjectReference{
-									Name: cr.Name + "-configmap",
-								},
-							},
-						},
-					}},
-					ServiceAccountName: cr.Namespace + "-controller-manager",
-				},
-			},
+			Name:      cfm.Name,
+			Namespace: cfm.Namespace,
 		},
 	}
-	// Set Prometheus instance as the owner and controller
-	ctrl.SetControllerReference(cr, dep, r.Scheme)
-	return dep
+	op, err := controllerutil.CreateOrUpdate(ctx, r.Client, foundConfigMap, func() error {
+		foundConfigMap.Labels = cfm.Labels
+		// Keep the last good configuration while an input is invalid
+		if inputs.invalid == nil || foundConfigMap.ResourceVersion == "" {
+			foundConfigMap.Data = cfm.Data
+		}
+		// Set Prometheus instance as the owner and controller
+		return ctrl.SetControllerReference(cr, foundConfigMap, r.Scheme)
+	})
+	if err != nil {
+		log.Error(err, "Failed to reconcile Configmap", "Configmap.Namespace", cfm.Namespace, "Configmap.Name", cfm.Name)
+		return err
+	}
+	if op != controllerutil.OperationResultNone {
+		log.Info("Configmap reconciled", "Configmap.Namespace", cfm.Namespace, "Configmap.Name", cfm.Name, "operation", op)
+	}
+	return nil
 }
 
-// labelsForPrometheus returns the labels for selecting the resources
-// belonging to the given prometheus CR name.
-func labelsForPrometheus(name string) map[string]string {
-	return map[string]string{"app": "prometheus", "prometheus_cr": name}
+// reconcileDaemonSet makes sure the ConfigMap and the DaemonSet of a
+// Prometheus agent running on every node exist and match the spec.
+func (r *PrometheusReconciler) reconcileDaemonSet(ctx context.Context, cr *monitoringv1alpha1.Prometheus, inputs *configInputs) (ctrl.Result, error) {
+	log := ctrllog.FromContext(ctx)
+
+	err := r.reconcileConfigMap(ctx, cr, 0, inputs)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	// Check if the daemonset already exists, if not create a new one
+	ds, err := r.daemonSetForPrometheus(cr)
+	if err != nil {
+		log.Error(err, "Failed to merge the pod overrides")
+		return ctrl.Result{}, err
+	}
+	foundDaemonSet := &appsv1.DaemonSet{}
+	err = r.Get(ctx, types.NamespacedName{Name: ds.Name, Namespace: ds.Namespace}, foundDaemonSet)
+	if err != nil && errors.IsNotFound(err) {
+		log.Info("Creating a new DaemonSet", "DaemonSet.Namespace", ds.Namespace, "DaemonSet.Name", ds.Name)
+		err = r.Create(ctx, ds)
+		if err != nil {
+			log.Error(err, "Failed to create new DaemonSet", "DaemonSet.Namespace", ds.Namespace, "DaemonSet.Name", ds.Name)
+			return ctrl.Result{}, err
+		}
+		// DaemonSet created successfully - return and requeue
+		return ctrl.Result{Requeue: true}, nil
+	} else if err != nil {
+		log.Error(err, "Failed to get DaemonSet")
+		return ctrl.Result{}, err
+	}
+
+	// Ensure the pod template is same as the spec
+	if podTemplateChanged(&ds.Spec.Template, &foundDaemonSet.Spec.Template) {
+		foundDaemonSet.Spec.Template = ds.Spec.Template
+		log.Info("Updating DaemonSet", "DaemonSet.Namespace", foundDaemonSet.Namespace, "DaemonSet.Name", foundDaemonSet.Name)
+		err = r.Update(ctx, foundDaemonSet)
+		if err != nil {
+			log.Error(err, "Failed to update DaemonSet", "DaemonSet.Namespace", foundDaemonSet.Namespace, "DaemonSet.Name", foundDaemonSet.Name)
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{Requeue: true}, nil
+	}
+
+	return ctrl.Result{}, nil
 }
 
-// configmapForPrometheus returns a prometheus ConfigMap object
-func (r *PrometheusReconciler) configmapForPrometheus(cr *monitoringv1alpha1.Prometheus) *corev1.ConfigMap {
-	labels := map[string]string{
-		"app": cr.Name + "-configmap",
+// deleteStaleWorkloads removes the workloads returned by staleWorkloads.
+func (r *PrometheusReconciler) deleteStaleWorkloads(ctx context.Context, cr *monitoringv1alpha1.Prometheus, shards int32) error {
+	log := ctrllog.FromContext(ctx)
+
+	stale, err := r.staleWorkloads(ctx, cr, shards)
+	if err != nil {
+		return err
+	}
+	for _, obj := range stale {
+		kind := reflect.TypeOf(obj).Elem().Name()
+		log.Info("Deleting stale "+kind, kind+".Namespace", obj.GetNamespace(), kind+".Name", obj.GetName())
+		err = r.Delete(ctx, obj)
+		if err != nil && !errors.IsNotFound(err) {
+			log.Error(err, "Failed to delete "+kind, kind+".Namespace", obj.GetNamespace(), kind+".Name", obj.GetName())
+			return err
+		}
+	}
+	return nil
+}
+
+// staleWorkloads returns the Deployments, PodDisruptionBudgets and
+// ConfigMaps controlled by a Prometheus for the shards whose index is beyond
+// the requested number of shards, and the workloads of the kind that is no
+// longer used when switching to or from a DaemonSet.
+func (r *PrometheusReconciler) staleWorkloads(ctx context.Context, cr *monitoringv1alpha1.Prometheus, shards int32) ([]client.Object, error) {
+	log := ctrllog.FromContext(ctx)
+	opts := []client.ListOption{
+		client.InNamespace(cr.Namespace),
+		client.MatchingLabels{"prometheus_cr": cr.Name},
+		client.HasLabels{shardLabel},
+	}
+	var stale []client.Object
+
+	deployments := &appsv1.DeploymentList{}
+	err := r.List(ctx, deployments, opts...)
+	if err != nil {
+		log.Error(err, "Failed to list Deployments")
+		return nil, err
+	}
+	for i := range deployments.Items {
+		dep := &deployments.Items[i]
+		if metav1.IsControlledBy(dep, cr) && (cr.Spec.DaemonSet || isExtraShard(dep, shards)) {
+			stale = append(stale, dep)
+		}
+	}
+
+	pdbs := &policyv1.PodDisruptionBudgetList{}
+	err = r.List(ctx, pdbs, opts...)
+	if err != nil {
+		log.Error(err, "Failed to list PodDisruptionBudgets")
+		return nil, err
+	}
+	for i := range pdbs.Items {
+		pdb := &pdbs.Items[i]
+		if metav1.IsControlledBy(pdb, cr) && (cr.Spec.DaemonSet || isExtraShard(pdb, shards)) {
+			stale = append(stale, pdb)
+		}
+	}
+
+	configmaps := &corev1.ConfigMapList{}
+	err = r.List(ctx, configmaps, opts...)
+	if err != nil {
+		log.Error(err, "Failed to list Configmaps")
+		return nil, err
+	}
+	for i := range configmaps.Items {
+		cfm := &configmaps.Items[i]
+		if metav1.IsControlledBy(cfm, cr) && isExtraShard(cfm, shards) {
+			stale = append(stale, cfm)
+		}
+	}
+
+	if cr.Spec.DaemonSet {
+		return stale, nil
+	}
+	ds := &appsv1.DaemonSet{}
+	err = r.Get(ctx, types.NamespacedName{Name: cr.Name, Namespace: cr.Namespace}, ds)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return stale, nil
+		}
+		log.Error(err, "Failed to get DaemonSet")
+		return nil, err
+	}
+	if metav1.IsControlledBy(ds, cr) {
+		stale = append(stale, ds)
+	}
+	return stale, nil
+}
+
+// isExtraShard reports whether the object belongs to a shard whose index is
+// beyond the requested number of shards.
+func isExtraShard(obj client.Object, shards int32) bool {
+	shard, err := strconv.Atoi(obj.GetLabels()[shardLabel])
+	return err == nil && int32(shard) >= shards
+}
+
+// updateWorkloadStatus records the state of the shard workloads, the desired
+// and ready pods and the Prometheus version running in them in the status.
+func (r *PrometheusReconciler) updateWorkloadStatus(ctx context.Context, cr *monitoringv1alpha1.Prometheus, shards int32) error {
+	log := ctrllog.FromContext(ctx)
+
+	status := make([]monitoringv1alpha1.ShardStatus, 0, shards)
+	if cr.Spec.DaemonSet {
+		ds := &appsv1.DaemonSet{}
+		err := r.Get(ctx, types.NamespacedName{Name: cr.Name, Namespace: cr.Namespace}, ds)
+		if err != nil && !errors.IsNotFound(err) {
+			log.Error(err, "Failed to get DaemonSet")
+			return err
+		}
+		status = append(status, monitoringv1alpha1.ShardStatus{
+			Shard:         0,
+			Workload:      cr.Name,
+			Replicas:      ds.Status.DesiredNumberScheduled,
+			ReadyReplicas: ds.Status.NumberReady,
+		})
+		shards = 0
+	}
+	for shard := int32(0); shard < shards; shard++ {
+		dep := &appsv1.Deployment{}
+		err := r.Get(ctx, types.NamespacedName{Name: deploymentNameForShard(cr, shard), Namespace: cr.Namespace}, dep)
+		if err != nil && !errors.IsNotFound(err) {
+			log.Error(err, "Failed to get Deployment")
+			return err
+		}
+		status = append(status, monitoringv1alpha1.ShardStatus{
+			Shard:         shard,
+			Workload:      deploymentNameForShard(cr, shard),
+			Replicas:      dep.Status.Replicas,
+			ReadyReplicas: dep.Status.ReadyReplicas,
+		})
+	}
+
+	runningVersion, err := r.runningVersion(ctx, cr)
+	if err != nil {
+		return err
+	}
+
+	// A DaemonSet runs a pod per eligible node, only known from its status
+	replicas := replicasForPrometheus(cr) * shards
+	if cr.Spec.DaemonSet {
+		replicas = status[0].Replicas
+	}
+	readyReplicas := int32(0)
+	for _, shard := range status {
+		readyReplicas += shard.ReadyReplicas
 	}
 
-	dataJson, err := json.Marshal(&cr.Spec.ScrapeConfigs)
+	if equality.Semantic.DeepEqual(cr.Status.Shards, status) && cr.Status.Version == runningVersion &&
+		cr.Status.Replicas == replicas && cr.Status.ReadyReplicas == readyReplicas {
+		return nil
+	}
+	cr.Status.Shards = status
+	cr.Status.Version = runningVersion
+	cr.Status.Replicas = replicas
+	cr.Status.ReadyReplicas = readyReplicas
+	err = r.Status().Update(ctx, cr)
 	if err != nil {
+		log.Error(err, "Failed to update Prometheus status")
+		return err
+	}
+	return nil
+}
+
+// runningVersion returns the sorted, comma separated, Prometheus versions
+// run by the ready pods that aren't being deleted.
+func (r *PrometheusReconciler) runningVersion(ctx context.Context, cr *monitoringv1alpha1.Prometheus) (string, error) {
+	log := ctrllog.FromContext(ctx)
+
+	podList := &corev1.PodList{}
+	err := r.List(ctx, podList, client.InNamespace(cr.Namespace), client.MatchingLabels(labelsForPrometheus(cr.Name)))
+	if err != nil {
+		log.Error(err, "Failed to list pods", "Prometheus.Namespace", cr.Namespace, "Prometheus.Name", cr.Name)
+		return "", err
+	}
+	versions := map[string]bool{}
+	for i := range podList.Items {
+		pod := &podList.Items[i]
+		if pod.DeletionTimestamp != nil {
+			continue
+		}
+		if version := podVersion(pod); version != "" {
+			versions[version] = true
+		}
+	}
+	running := make([]string, 0, len(versions))
+	for v := range versions {
+		running = append(running, v)
+	}
+	sort.Strings(running)
+	return strings.Join(running, ","), nil
+}
+
+// podVersion returns the Prometheus version run by a pod whose Prometheus
+// container is ready, read from the tag of the image reported by the
+// kubelet. Images pinned by digest carry no version, their digest is
+// returned instead.
+func podVersion(pod *corev1.Pod) string {
+	for _, cs := range pod.Status.ContainerStatuses {
+		if cs.Name != "prometheus" || !cs.Ready {
+			continue
+		}
+		image := cs.Image
+		if i := strings.Index(image, "@"); i >= 0 {
+			image = image[:i]
+		}
+		if i := strings.LastIndex(image, ":"); i > strings.LastIndex(image, "/") {
+			if v, err := version.ParseSemantic(strings.TrimPrefix(image[i+1:], "v")); err == nil {
+				return v.String()
+			}
+		}
+		if i := strings.LastIndex(cs.ImageID, "@"); i >= 0 {
+			return cs.ImageID[i+1:]
+		}
+		return cs.ImageID
+	}
+	return ""
+}
+
+// setCondition sets the given condition on the Prometheus status, and only
+// writes the status when the condition actually changed.
+func (r *PrometheusReconciler) setCondition(ctx context.Context, cr *monitoringv1alpha1.Prometheus, condition metav1.Condition) error {
+	log := ctrllog.FromContext(ctx)
+
+	condition.ObservedGeneration = cr.Generation
+	current := meta.FindStatusCondition(cr.Status.Conditions, condition.Type)
+	if current != nil && current.Status == condition.Status && current.Reason == condition.Reason &&
+		current.Message == condition.Message && current.ObservedGeneration == condition.ObservedGeneration {
 		return nil
 	}
 
-	dataYaml, err := yaml.JSONToYAML(dataJson)
+	meta.SetStatusCondition(&cr.Status.Conditions, condition)
+	err := r.Status().Update(ctx, cr)
 	if err != nil {
+		log.Error(err, "Failed to update Prometheus status", "Condition.Type", condition.Type)
+		return err
+	}
+	return nil
+}
+
+// removeCondition removes a condition from the Prometheus status, and only
+// writes the status when it was set.
+func (r *PrometheusReconciler) removeCondition(ctx context.Context, cr *monitoringv1alpha1.Prometheus, conditionType string) error {
+	log := ctrllog.FromContext(ctx)
+
+	if meta.FindStatusCondition(cr.Status.Conditions, conditionType) == nil {
 		return nil
 	}
+	meta.RemoveStatusCondition(&cr.Status.Conditions, conditionType)
+	err := r.Status().Update(ctx, cr)
+	if err != nil {
+		log.Error(err, "Failed to update Prometheus status", "Condition.Type", conditionType)
+		return err
+	}
+	return nil
+}
 
-	cf := &corev1.ConfigMap{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      cr.Name + "-configmap",
-			Namespace: cr.Namespace,
-			Labels:    labels,
-		},
-		Data: map[string]string{
-			"prometheus.yml": `scrape_configs:
-` + string(dataYaml),
-		},
+// labelsForPrometheus returns the labels for selecting the resources
+// belonging to the given prometheus CR name.
+func labelsForPrometheus(name string) map[string]string {
+	return map[string]string{"app": "prometheus", "prometheus_cr": name}
+}
+
+// labelsForPrometheusShard returns the labels for selecting the resources
+// belonging to a shard of the given prometheus CR name.
+func labelsForPrometheusShard(name string, shard int32) map[string]string {
+	ls := labelsForPrometheus(name)
+	ls[shardLabel] = strconv.Itoa(int(shard))
+	return ls
+}
+
+// shardsForPrometheus returns the number of shards of a Prometheus.
+func shardsForPrometheus(cr *monitoringv1alpha1.Prometheus) int32 {
+	if cr.Spec.Shards == nil || *cr.Spec.Shards < 1 {
+		return 1
+	}
+	return *cr.Spec.Shards
+}
+
+// deploymentNameForShard returns the name of the Deployment of a shard. The
+// first shard keeps the name of the Prometheus.
+func deploymentNameForShard(cr *monitoringv1alpha1.Prometheus, shard int32) string {
+	if shard == 0 {
+		return cr.Name
 	}
-	// Set Prometheus instance as the owner and controller
-	ctrl.SetControllerReference(cr, cf, r.Scheme)
-	return cf
+	return fmt.Sprintf("%s-shard-%d", cr.Name, shard)
+}
+
+// configmapNameForShard returns the name of the ConfigMap of a shard.
+func configmapNameForShard(cr *monitoringv1alpha1.Prometheus, shard int32) string {
+	return deploymentNameForShard(cr, shard) + "-configmap"
 }
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *PrometheusReconciler) SetupWithManager(mgr ctrl.Manager) error {
-	return ctrl.NewControllerManagedBy(mgr).
+	denied := make(map[string]bool, len(r.DenyNamespaces))
+	for _, ns := range r.DenyNamespaces {
+		denied[ns] = true
+	}
+
+	b := ctrl.NewControllerManagedBy(mgr).
 		For(&monitoringv1alpha1.Prometheus{}).
 		Owns(&appsv1.Deployment{}).
+		Owns(&appsv1.DaemonSet{}).
 		Owns(&corev1.ConfigMap{}).
-		Complete(r)
+		Owns(&corev1.ServiceAccount{}).
+		Owns(&corev1.Service{}).
+		Owns(&corev1.Secret{}).
+		Owns(&networkingv1.Ingress{}).
+		Owns(&networkingv1.NetworkPolicy{}).
+		Owns(&policyv1.PodDisruptionBudget{}).
+		Watches(&source.Kind{Type: &corev1.Secret{}}, handler.EnqueueRequestsFromMapFunc(r.prometheusesForSecret)).
+		Watches(&source.Kind{Type: &monitoringv1alpha1.ScrapeConfig{}}, handler.EnqueueRequestsFromMapFunc(r.prometheusesSelectingScrapeConfigs)).
+		Watches(&source.Kind{Type: &monitoringv1alpha1.Probe{}}, handler.EnqueueRequestsFromMapFunc(r.prometheusesSelectingProbes)).
+		WithEventFilter(predicate.NewPredicateFuncs(func(obj client.Object) bool {
+			return !denied[obj.GetNamespace()]
+		}))
+	if r.NamespaceScoped {
+		b = b.Owns(&rbacv1.Role{}).
+			Owns(&rbacv1.RoleBinding{})
+	} else {
+		b = b.Watches(&source.Kind{Type: &corev1.Namespace{}}, handler.EnqueueRequestsFromMapFunc(r.prometheusesSelectingNamespaces))
+	}
+	if r.DryRun {
+		return b.Complete(&dryRunReconciler{r})
+	}
+	return b.Complete(r)
 }
@@ -0,0 +1,326 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	monitoringv1alpha1 "github.com/marieroque/best-prometheus-operator-in-the-world/api/monitoring/v1alpha1"
+)
+
+var _ = Describe("Prometheus controller", func() {
+	const name = "finalized"
+
+	ctx := context.Background()
+	key := types.NamespacedName{Name: name, Namespace: "default"}
+
+	It("adds the finalizer and removes the cluster RBAC on deletion", func() {
+		version := "2.40.0"
+		prometheus := &monitoringv1alpha1.Prometheus{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Spec:       monitoringv1alpha1.PrometheusSpec{Version: &version},
+		}
+		Expect(k8sClient.Create(ctx, prometheus)).To(Succeed())
+		clusterKey := types.NamespacedName{Name: clusterRoleNameForPrometheus(prometheus)}
+
+		reconciler := &PrometheusReconciler{Client: k8sClient, Scheme: scheme.Scheme}
+		_, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: key})
+		Expect(err).NotTo(HaveOccurred())
+
+		By("adding the finalizer")
+		Expect(k8sClient.Get(ctx, key, prometheus)).To(Succeed())
+		Expect(controllerutil.ContainsFinalizer(prometheus, prometheusFinalizer)).To(BeTrue())
+
+		By("creating the ClusterRole and ClusterRoleBinding")
+		Expect(k8sClient.Get(ctx, clusterKey, &rbacv1.ClusterRole{})).To(Succeed())
+		Expect(k8sClient.Get(ctx, clusterKey, &rbacv1.ClusterRoleBinding{})).To(Succeed())
+
+		By("removing them once the Prometheus is deleted")
+		Expect(k8sClient.Delete(ctx, prometheus)).To(Succeed())
+		_, err = reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: key})
+		Expect(err).NotTo(HaveOccurred())
+
+		err = k8sClient.Get(ctx, clusterKey, &rbacv1.ClusterRole{})
+		Expect(errors.IsNotFound(err)).To(BeTrue())
+		err = k8sClient.Get(ctx, clusterKey, &rbacv1.ClusterRoleBinding{})
+		Expect(errors.IsNotFound(err)).To(BeTrue())
+		err = k8sClient.Get(ctx, key, &monitoringv1alpha1.Prometheus{})
+		Expect(errors.IsNotFound(err)).To(BeTrue())
+	})
+})
+
+// newTestPrometheus returns a Prometheus with the fields required by the
+// reconciler set.
+func newTestPrometheus(name string) *monitoringv1alpha1.Prometheus {
+	version := "2.40.0"
+	return &monitoringv1alpha1.Prometheus{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", UID: types.UID(name)},
+		Spec:       monitoringv1alpha1.PrometheusSpec{Version: &version},
+	}
+}
+
+// newTestScheme returns a scheme holding the objects managed by the
+// reconciler.
+func newTestScheme(t *testing.T) *runtime.Scheme {
+	s := runtime.NewScheme()
+	if err := scheme.AddToScheme(s); err != nil {
+		t.Fatal(err)
+	}
+	if err := monitoringv1alpha1.AddToScheme(s); err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+// newTestReconciler returns a reconciler backed by a fake client holding the
+// given objects.
+func newTestReconciler(t *testing.T, objs ...client.Object) *PrometheusReconciler {
+	s := newTestScheme(t)
+	return &PrometheusReconciler{
+		Client: fake.NewClientBuilder().WithScheme(s).WithObjects(objs...).Build(),
+		Scheme: s,
+	}
+}
+
+func TestDeploymentSelector(t *testing.T) {
+	cr := newTestPrometheus("selector")
+	r := newTestReconciler(t)
+
+	for _, shard := range []int32{0, 1} {
+		dep, err := r.deploymentForPrometheus(cr, shard)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := labelsForPrometheusShard(cr.Name, shard)
+		if !equality.Semantic.DeepEqual(dep.Spec.Selector.MatchLabels, want) {
+			t.Errorf("shard %d: got selector %v, want %v", shard, dep.Spec.Selector.MatchLabels, want)
+		}
+		if dep.Spec.Template.Labels[shardLabel] != strconv.Itoa(int(shard)) {
+			t.Errorf("shard %d: got pod labels %v without the shard label", shard, dep.Spec.Template.Labels)
+		}
+	}
+}
+
+func TestDeploymentSelectorMigration(t *testing.T) {
+	ctx := context.Background()
+	cr := newTestPrometheus("migrated")
+	s := newTestScheme(t)
+	// Deployment created before sharding, selecting the pods of every shard
+	dep := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: cr.Name, Namespace: cr.Namespace, Labels: labelsForPrometheus(cr.Name)},
+		Spec: appsv1.DeploymentSpec{
+			Selector: &metav1.LabelSelector{MatchLabels: labelsForPrometheus(cr.Name)},
+			Template: corev1.PodTemplateSpec{ObjectMeta: metav1.ObjectMeta{Labels: labelsForPrometheus(cr.Name)}},
+		},
+	}
+	if err := controllerutil.SetControllerReference(cr, dep, s); err != nil {
+		t.Fatal(err)
+	}
+	r := newTestReconciler(t, cr, dep)
+
+	if err := reconcileUntilDone(t, r, cr); err != nil {
+		t.Fatal(err)
+	}
+	found := &appsv1.Deployment{}
+	if err := r.Get(ctx, types.NamespacedName{Name: cr.Name, Namespace: cr.Namespace}, found); err != nil {
+		t.Fatal(err)
+	}
+	want := labelsForPrometheusShard(cr.Name, 0)
+	if !equality.Semantic.DeepEqual(found.Spec.Selector.MatchLabels, want) {
+		t.Errorf("got selector %v, want %v", found.Spec.Selector.MatchLabels, want)
+	}
+}
+
+func TestFileSDVolumes(t *testing.T) {
+	cr := newTestPrometheus("file-sd")
+	cr.Spec.ScrapeConfigs = []*monitoringv1alpha1.ScrapeConfigSpec{{
+		FileSDConfigs: []*monitoringv1alpha1.FileSDConfig{{ConfigMap: "targets.v1"}, {ConfigMap: "targets"}},
+	}}
+	spec := &corev1.PodSpec{Containers: []corev1.Container{{Name: "prometheus"}}}
+	addFileSDVolumes(cr, spec)
+
+	if len(spec.Volumes) != 2 {
+		t.Fatalf("got %d volumes, want 2", len(spec.Volumes))
+	}
+	for i, volume := range spec.Volumes {
+		if errs := validation.IsDNS1123Label(volume.Name); len(errs) > 0 {
+			t.Errorf("invalid volume name %q: %v", volume.Name, errs)
+		}
+		mount := spec.Containers[0].VolumeMounts[i]
+		if mount.Name != volume.Name || mount.MountPath != fileSDDir+volume.ConfigMap.Name {
+			t.Errorf("volume %s of ConfigMap %s mounted as %+v", volume.Name, volume.ConfigMap.Name, mount)
+		}
+	}
+}
+
+func TestDeleteStaleWorkloads(t *testing.T) {
+	ctx := context.Background()
+	cr := newTestPrometheus("stale")
+	other := newTestPrometheus("other")
+
+	s := newTestScheme(t)
+	var objs []client.Object
+	for _, shard := range []int32{0, 1, 2, 3} {
+		dep := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{
+			Name:      deploymentNameForShard(cr, shard),
+			Namespace: cr.Namespace,
+			Labels:    labelsForPrometheusShard(cr.Name, shard),
+		}}
+		cfm := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{
+			Name:      configmapNameForShard(cr, shard),
+			Namespace: cr.Namespace,
+			Labels:    labelsForPrometheusShard(cr.Name, shard),
+		}}
+		owner := cr
+		if shard == 3 {
+			// Objects not controlled by the Prometheus are left alone
+			owner = other
+		}
+		for _, obj := range []client.Object{dep, cfm} {
+			if err := controllerutil.SetControllerReference(owner, obj, s); err != nil {
+				t.Fatal(err)
+			}
+			objs = append(objs, obj)
+		}
+	}
+	r := newTestReconciler(t, objs...)
+
+	if err := r.deleteStaleWorkloads(ctx, cr, 2); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, shard := range []int32{0, 1, 2, 3} {
+		wantFound := shard != 2
+		err := r.Get(ctx, types.NamespacedName{Name: deploymentNameForShard(cr, shard), Namespace: cr.Namespace}, &appsv1.Deployment{})
+		if found := !errors.IsNotFound(err); found != wantFound {
+			t.Errorf("shard %d: Deployment found %t, want %t (%v)", shard, found, wantFound, err)
+		}
+		err = r.Get(ctx, types.NamespacedName{Name: configmapNameForShard(cr, shard), Namespace: cr.Namespace}, &corev1.ConfigMap{})
+		if found := !errors.IsNotFound(err); found != wantFound {
+			t.Errorf("shard %d: ConfigMap found %t, want %t (%v)", shard, found, wantFound, err)
+		}
+	}
+}
+
+func TestPodVersion(t *testing.T) {
+	digest := "sha256:" + strings.Repeat("a", 64)
+	tests := []struct {
+		name   string
+		status corev1.ContainerStatus
+		want   string
+	}{{
+		name:   "tagged image",
+		status: corev1.ContainerStatus{Name: "prometheus", Ready: true, Image: "quay.io/prometheus/prometheus:v2.40.0"},
+		want:   "2.40.0",
+	}, {
+		name:   "mirror with a port",
+		status: corev1.ContainerStatus{Name: "prometheus", Ready: true, Image: "mirror:5000/prometheus:v2.41.0"},
+		want:   "2.41.0",
+	}, {
+		name: "image pinned by digest",
+		status: corev1.ContainerStatus{Name: "prometheus", Ready: true, Image: "mirror:5000/prometheus@" + digest,
+			ImageID: "mirror:5000/prometheus@" + digest},
+		want: digest,
+	}, {
+		name:   "container not ready",
+		status: corev1.ContainerStatus{Name: "prometheus", Image: "quay.io/prometheus/prometheus:v2.40.0"},
+		want:   "",
+	}, {
+		name:   "other container",
+		status: corev1.ContainerStatus{Name: "config-reloader", Ready: true, Image: "quay.io/prometheus-operator/prometheus-config-reloader:v0.60.1"},
+		want:   "",
+	}}
+	for _, tt := range tests {
+		pod := &corev1.Pod{Status: corev1.PodStatus{ContainerStatuses: []corev1.ContainerStatus{tt.status}}}
+		if got := podVersion(pod); got != tt.want {
+			t.Errorf("%s: got version %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestPausedReconciliation(t *testing.T) {
+	ctx := context.Background()
+	cr := newTestPrometheus("paused")
+	cr.Spec.Paused = true
+	r := newTestReconciler(t, cr)
+	crKey := types.NamespacedName{Name: cr.Name, Namespace: cr.Namespace}
+
+	// paused returns the Paused condition and whether the Deployment exists
+	paused := func() (*metav1.Condition, bool) {
+		t.Helper()
+		found := &monitoringv1alpha1.Prometheus{}
+		if err := r.Get(ctx, crKey, found); err != nil {
+			t.Fatal(err)
+		}
+		err := r.Get(ctx, crKey, &appsv1.Deployment{})
+		if err != nil && !errors.IsNotFound(err) {
+			t.Fatal(err)
+		}
+		return meta.FindStatusCondition(found.Status.Conditions, monitoringv1alpha1.ConditionTypePaused), err == nil
+	}
+
+	if err := reconcileUntilDone(t, r, cr); err != nil {
+		t.Fatal(err)
+	}
+	condition, deployed := paused()
+	if condition == nil || condition.Status != metav1.ConditionTrue {
+		t.Errorf("got Paused condition %+v while paused, want True", condition)
+	}
+	if deployed {
+		t.Error("the Deployment was created while paused")
+	}
+
+	found := &monitoringv1alpha1.Prometheus{}
+	if err := r.Get(ctx, crKey, found); err != nil {
+		t.Fatal(err)
+	}
+	found.Spec.Paused = false
+	if err := r.Update(ctx, found); err != nil {
+		t.Fatal(err)
+	}
+	if err := reconcileUntilDone(t, r, cr); err != nil {
+		t.Fatal(err)
+	}
+	condition, deployed = paused()
+	if condition == nil || condition.Status != metav1.ConditionFalse {
+		t.Errorf("got Paused condition %+v once resumed, want False", condition)
+	}
+	if !deployed {
+		t.Error("the Deployment wasn't created once resumed")
+	}
+}
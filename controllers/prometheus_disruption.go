@@ -0,0 +1,110 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+
+	policyv1 "k8s.io/api/policy/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	ctrllog "sigs.k8s.io/controller-runtime/pkg/log"
+
+	monitoringv1alpha1 "github.com/marieroque/best-prometheus-operator-in-the-world/api/monitoring/v1alpha1"
+)
+
+// replicasForPrometheus returns the number of pods of each shard.
+func replicasForPrometheus(cr *monitoringv1alpha1.Prometheus) int32 {
+	if cr.Spec.Replicas == nil || *cr.Spec.Replicas < 1 {
+		return 1
+	}
+	return *cr.Spec.Replicas
+}
+
+// podDisruptionBudgetForPrometheus returns the PodDisruptionBudget keeping
+// all but one pod of a shard running during voluntary disruptions.
+func podDisruptionBudgetForPrometheus(cr *monitoringv1alpha1.Prometheus, shard int32) *policyv1.PodDisruptionBudget {
+	maxUnavailable := intstr.FromInt(1)
+	return &policyv1.PodDisruptionBudget{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      deploymentNameForShard(cr, shard),
+			Namespace: cr.Namespace,
+			Labels:    labelsForPrometheusShard(cr.Name, shard),
+		},
+		Spec: policyv1.PodDisruptionBudgetSpec{
+			MaxUnavailable: &maxUnavailable,
+			Selector: &metav1.LabelSelector{
+				MatchLabels: labelsForPrometheusShard(cr.Name, shard),
+			},
+		},
+	}
+}
+
+// reconcilePodDisruptionBudget makes sure the PodDisruptionBudget of a shard
+// exists when it has several replicas, and deletes it otherwise.
+func (r *PrometheusReconciler) reconcilePodDisruptionBudget(ctx context.Context, cr *monitoringv1alpha1.Prometheus, shard int32) error {
+	log := ctrllog.FromContext(ctx)
+
+	pdb := &policyv1.PodDisruptionBudget{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      deploymentNameForShard(cr, shard),
+			Namespace: cr.Namespace,
+		},
+	}
+	if replicasForPrometheus(cr) < 2 {
+		// Only delete the PodDisruptionBudget created for the shard, not one
+		// of the user with the same name
+		err := r.Get(ctx, types.NamespacedName{Name: pdb.Name, Namespace: pdb.Namespace}, pdb)
+		if err != nil {
+			if errors.IsNotFound(err) {
+				return nil
+			}
+			log.Error(err, "Failed to get PodDisruptionBudget", "PodDisruptionBudget.Namespace", pdb.Namespace, "PodDisruptionBudget.Name", pdb.Name)
+			return err
+		}
+		if !metav1.IsControlledBy(pdb, cr) {
+			return nil
+		}
+		err = r.Delete(ctx, pdb)
+		if err != nil && !errors.IsNotFound(err) {
+			log.Error(err, "Failed to delete PodDisruptionBudget", "PodDisruptionBudget.Namespace", pdb.Namespace, "PodDisruptionBudget.Name", pdb.Name)
+			return err
+		}
+		return nil
+	}
+
+	desired := podDisruptionBudgetForPrometheus(cr, shard)
+	op, err := controllerutil.CreateOrUpdate(ctx, r.Client, pdb, func() error {
+		pdb.Labels = desired.Labels
+		pdb.Spec.MaxUnavailable = desired.Spec.MaxUnavailable
+		pdb.Spec.Selector = desired.Spec.Selector
+		// Set Prometheus instance as the owner and controller
+		return ctrl.SetControllerReference(cr, pdb, r.Scheme)
+	})
+	if err != nil {
+		log.Error(err, "Failed to reconcile PodDisruptionBudget", "PodDisruptionBudget.Namespace", pdb.Namespace, "PodDisruptionBudget.Name", pdb.Name)
+		return err
+	}
+	if op != controllerutil.OperationResultNone {
+		log.Info("PodDisruptionBudget reconciled", "PodDisruptionBudget.Namespace", pdb.Namespace, "PodDisruptionBudget.Name", pdb.Name, "operation", op)
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	policyv1 "k8s.io/api/policy/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestDeletePodDisruptionBudget(t *testing.T) {
+	ctx := context.Background()
+	cr := newTestPrometheus("ha")
+	replicas := int32(2)
+	cr.Spec.Replicas = &replicas
+	r := newTestReconciler(t, cr)
+	key := types.NamespacedName{Name: deploymentNameForShard(cr, 0), Namespace: cr.Namespace}
+
+	if err := r.reconcilePodDisruptionBudget(ctx, cr, 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Get(ctx, key, &policyv1.PodDisruptionBudget{}); err != nil {
+		t.Fatalf("the PodDisruptionBudget wasn't created: %v", err)
+	}
+
+	// The PodDisruptionBudget of the operator is deleted with a single
+	// replica
+	replicas = 1
+	if err := r.reconcilePodDisruptionBudget(ctx, cr, 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Get(ctx, key, &policyv1.PodDisruptionBudget{}); !errors.IsNotFound(err) {
+		t.Errorf("the PodDisruptionBudget wasn't deleted: %v", err)
+	}
+
+	// A PodDisruptionBudget of the user with the same name is kept
+	user := &policyv1.PodDisruptionBudget{ObjectMeta: metav1.ObjectMeta{Name: key.Name, Namespace: key.Namespace}}
+	if err := r.Create(ctx, user); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.reconcilePodDisruptionBudget(ctx, cr, 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Get(ctx, key, &policyv1.PodDisruptionBudget{}); err != nil {
+		t.Errorf("the PodDisruptionBudget of the user was deleted: %v", err)
+	}
+}
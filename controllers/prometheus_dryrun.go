@@ -0,0 +1,517 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	goerrors "errors"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	ctrllog "sigs.k8s.io/controller-runtime/pkg/log"
+
+	monitoringv1alpha1 "github.com/marieroque/best-prometheus-operator-in-the-world/api/monitoring/v1alpha1"
+)
+
+// objectChange is a change the reconciliation of a Prometheus would make.
+type objectChange struct {
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace,omitempty"`
+	Name      string `json:"name"`
+	// Operation is create, update or delete
+	Operation string `json:"operation"`
+	// Paths are the fields an update changes, the values are left out
+	// since they may be secret
+	Paths []string `json:"paths,omitempty"`
+}
+
+// plannedObject is an object the reconciliation of a Prometheus writes.
+type plannedObject struct {
+	// obj is the desired state of the object, or only its key when deleted
+	obj client.Object
+	// fields are the paths of the fields the reconciliation updates, every
+	// field of obj when empty
+	fields []string
+	// deleted is set when the object is deleted if it exists and is
+	// controlled by the Prometheus
+	deleted bool
+}
+
+// dryRunReconciler builds the objects of a Prometheus with the builders of
+// the reconciliation, diffs them against the live objects read from the
+// cache, and reports the changes instead of applying them. The only writes
+// to the API server are the DryRunChangesAnnotation of the Prometheus and
+// the removal of its finalizer.
+type dryRunReconciler struct {
+	*PrometheusReconciler
+}
+
+// Reconcile implements reconcile.Reconciler
+func (r *dryRunReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := ctrllog.FromContext(ctx)
+
+	prometheus := &monitoringv1alpha1.Prometheus{}
+	err := r.Get(ctx, req.NamespacedName, prometheus)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return ctrl.Result{}, nil
+		}
+		log.Error(err, "Failed to get Prometheus")
+		return ctrl.Result{}, err
+	}
+
+	if prometheus.GetDeletionTimestamp() != nil {
+		return ctrl.Result{}, r.finalize(ctx, prometheus)
+	}
+
+	// A paused Prometheus isn't reconciled, so nothing would change
+	var plan []plannedObject
+	if !prometheus.Spec.Paused {
+		plan, err = r.planObjects(ctx, prometheus)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+	changes, err := r.diffObjects(ctx, prometheus, plan)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	for _, change := range changes {
+		log.Info("Dry run change", "Kind", change.Kind, "Namespace", change.Namespace, "Name", change.Name,
+			"operation", change.Operation, "paths", change.Paths)
+	}
+
+	// Annotating the Prometheus triggers a new reconciliation, which finds
+	// the same changes
+	data, err := json.Marshal(changes)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if prometheus.Annotations[monitoringv1alpha1.DryRunChangesAnnotation] != string(data) {
+		patch := client.MergeFrom(prometheus.DeepCopy())
+		if prometheus.Annotations == nil {
+			prometheus.Annotations = map[string]string{}
+		}
+		prometheus.Annotations[monitoringv1alpha1.DryRunChangesAnnotation] = string(data)
+		err = r.Patch(ctx, prometheus, patch)
+		if err != nil {
+			log.Error(err, "Failed to annotate Prometheus with the dry run changes",
+				"Prometheus.Namespace", prometheus.Namespace, "Prometheus.Name", prometheus.Name)
+			return ctrl.Result{}, err
+		}
+	}
+	return ctrl.Result{}, nil
+}
+
+// finalize removes the finalizer of a deleted Prometheus, added by an
+// operator applying the changes, so that its deletion isn't blocked. The
+// cluster-scoped objects the finalizer would delete are only reported, and
+// are left in place.
+func (r *dryRunReconciler) finalize(ctx context.Context, cr *monitoringv1alpha1.Prometheus) error {
+	log := ctrllog.FromContext(ctx)
+
+	if !controllerutil.ContainsFinalizer(cr, prometheusFinalizer) {
+		return nil
+	}
+	if !r.NamespaceScoped {
+		for _, obj := range []client.Object{clusterRoleBindingForPrometheus(cr), clusterRoleForPrometheus(cr)} {
+			kind := reflect.TypeOf(obj).Elem().Name()
+			err := r.Get(ctx, client.ObjectKeyFromObject(obj), obj)
+			if errors.IsNotFound(err) {
+				continue
+			} else if err != nil {
+				log.Error(err, "Failed to get "+kind, kind+".Name", obj.GetName())
+				return err
+			}
+			log.Info("Dry run change", "Kind", kind, "Name", obj.GetName(), "operation", "delete")
+		}
+	}
+
+	controllerutil.RemoveFinalizer(cr, prometheusFinalizer)
+	err := r.Update(ctx, cr)
+	if err != nil {
+		log.Error(err, "Failed to remove finalizer")
+		return err
+	}
+	return nil
+}
+
+// planObjects returns the objects the reconciliation of a Prometheus would
+// create, update or delete, in the order it writes them.
+func (r *dryRunReconciler) planObjects(ctx context.Context, cr *monitoringv1alpha1.Prometheus) ([]plannedObject, error) {
+	metadata := []string{"metadata.labels", "metadata.ownerReferences"}
+	withMetadata := func(fields ...string) []string {
+		return append(append([]string{}, metadata...), fields...)
+	}
+
+	plan := []plannedObject{{obj: serviceAccountForPrometheus(cr), fields: metadata}}
+	if r.NamespaceScoped {
+		plan = append(plan,
+			plannedObject{obj: roleForPrometheus(cr), fields: withMetadata("rules")},
+			plannedObject{obj: roleBindingForPrometheus(cr), fields: withMetadata("roleRef", "subjects")})
+	} else {
+		plan = append(plan,
+			plannedObject{obj: clusterRoleForPrometheus(cr), fields: []string{"metadata.labels", "rules"}},
+			plannedObject{obj: clusterRoleBindingForPrometheus(cr), fields: []string{"metadata.labels", "roleRef", "subjects"}})
+	}
+
+	// The cluster IP of the Service is only set on creation
+	plan = append(plan, plannedObject{obj: serviceForPrometheus(cr), fields: withMetadata("spec.selector", "spec.ports")})
+
+	key := metav1.ObjectMeta{Name: cr.Name, Namespace: cr.Namespace}
+	if cr.Spec.Ingress == nil {
+		plan = append(plan, plannedObject{obj: &networkingv1.Ingress{ObjectMeta: key}, deleted: true})
+	} else {
+		plan = append(plan, plannedObject{obj: ingressForPrometheus(cr), fields: withMetadata("metadata.annotations", "spec")})
+	}
+	if cr.Spec.NetworkPolicy == nil {
+		plan = append(plan, plannedObject{obj: &networkingv1.NetworkPolicy{ObjectMeta: key}, deleted: true})
+	} else {
+		var cidrs []string
+		if len(cr.Spec.NetworkPolicy.Egress) > 0 {
+			var err error
+			cidrs, err = r.apiServerCIDRs(ctx, cr)
+			if err != nil {
+				return nil, err
+			}
+		}
+		plan = append(plan, plannedObject{obj: networkPolicyForPrometheus(cr, cidrs), fields: withMetadata("spec")})
+	}
+
+	web, err := r.planWebSecrets(ctx, cr)
+	if err != nil {
+		return nil, err
+	}
+	plan = append(plan, web...)
+
+	inputs, err := r.loadConfigInputs(ctx, cr)
+	if err != nil {
+		return nil, err
+	}
+
+	blackboxKey := metav1.ObjectMeta{Name: blackboxNameForPrometheus(cr), Namespace: cr.Namespace}
+	if cr.Spec.ProbeSelector == nil {
+		plan = append(plan,
+			plannedObject{obj: &appsv1.Deployment{ObjectMeta: blackboxKey}, deleted: true},
+			plannedObject{obj: &corev1.Service{ObjectMeta: blackboxKey}, deleted: true},
+			plannedObject{obj: &corev1.ConfigMap{ObjectMeta: blackboxKey}, deleted: true})
+	} else {
+		cm, err := blackboxConfigMapForPrometheus(cr, inputs.probes)
+		if err != nil {
+			return nil, err
+		}
+		plan = append(plan,
+			plannedObject{obj: cm, fields: withMetadata("data")},
+			plannedObject{obj: blackboxServiceForPrometheus(cr), fields: withMetadata("spec.selector", "spec.ports")},
+			plannedObject{obj: r.blackboxDeploymentForPrometheus(cr, cm, inputs.probes), fields: []string{"spec.template"}})
+	}
+
+	// The last good configuration is kept while an input is invalid
+	configFields := withMetadata("data")
+	if inputs.invalid != nil {
+		configFields = metadata
+	}
+	shards := shardsForPrometheus(cr)
+	if cr.Spec.DaemonSet {
+		cfm, err := r.configmapForPrometheus(cr, 0, inputs)
+		if err != nil {
+			return nil, err
+		}
+		ds, err := r.daemonSetForPrometheus(cr)
+		if err != nil {
+			return nil, err
+		}
+		plan = append(plan,
+			plannedObject{obj: cfm, fields: configFields},
+			plannedObject{obj: ds, fields: []string{"spec.template"}})
+	} else {
+		for shard := int32(0); shard < shards; shard++ {
+			cfm, err := r.configmapForPrometheus(cr, shard, inputs)
+			if err != nil {
+				return nil, err
+			}
+			plan = append(plan, plannedObject{obj: cfm, fields: configFields})
+
+			pdb := podDisruptionBudgetForPrometheus(cr, shard)
+			if replicasForPrometheus(cr) < 2 {
+				plan = append(plan, plannedObject{obj: pdb, deleted: true})
+			} else {
+				plan = append(plan, plannedObject{obj: pdb, fields: withMetadata("spec.maxUnavailable", "spec.selector")})
+			}
+
+			// A different selector recreates the Deployment
+			dep, err := r.deploymentForPrometheus(cr, shard)
+			if err != nil {
+				return nil, err
+			}
+			plan = append(plan, plannedObject{obj: dep, fields: []string{"metadata.labels", "spec.replicas", "spec.selector", "spec.template"}})
+		}
+	}
+
+	stale, err := r.staleWorkloads(ctx, cr, shards)
+	if err != nil {
+		return nil, err
+	}
+	for _, obj := range stale {
+		plan = append(plan, plannedObject{obj: obj, deleted: true})
+	}
+	return plan, nil
+}
+
+// planWebSecrets returns the web configuration and the self-signed
+// certificate Secrets of a Prometheus. Their generated content is only
+// changed when missing, or when the certificate is about to expire, and an
+// unusable Secret of basic auth users leaves the web configuration as is.
+func (r *dryRunReconciler) planWebSecrets(ctx context.Context, cr *monitoringv1alpha1.Prometheus) ([]plannedObject, error) {
+	log := ctrllog.FromContext(ctx)
+	metadata := []string{"metadata.labels", "metadata.ownerReferences"}
+	key := metav1.ObjectMeta{Name: webTLSSecretName(cr), Namespace: cr.Namespace}
+
+	var plan []plannedObject
+	if !webSelfSigned(cr) {
+		plan = append(plan, plannedObject{obj: &corev1.Secret{ObjectMeta: key}, deleted: true})
+	} else {
+		found := &corev1.Secret{}
+		err := r.Get(ctx, client.ObjectKey{Name: key.Name, Namespace: key.Namespace}, found)
+		if err != nil && !errors.IsNotFound(err) {
+			log.Error(err, "Failed to get Secret", "Secret.Namespace", key.Namespace, "Secret.Name", key.Name)
+			return nil, err
+		}
+		key.Labels = labelsForPrometheus(cr.Name)
+		secret := &corev1.Secret{ObjectMeta: key, Type: corev1.SecretTypeTLS}
+		fields := append(metadata, "type")
+		if certificateExpiring(found.Data[corev1.TLSCertKey]) {
+			cert, privateKey, err := generateSelfSignedCert(cr)
+			if err != nil {
+				return nil, err
+			}
+			secret.Data = map[string][]byte{
+				corev1.TLSCertKey:       cert,
+				corev1.TLSPrivateKeyKey: privateKey,
+			}
+			fields = append(fields, "data")
+		}
+		plan = append(plan, plannedObject{obj: secret, fields: fields})
+	}
+
+	key = metav1.ObjectMeta{Name: webConfigSecretName(cr), Namespace: cr.Namespace}
+	if cr.Spec.Web == nil {
+		return append(plan, plannedObject{obj: &corev1.Secret{ObjectMeta: key}, deleted: true}), nil
+	}
+	found := &corev1.Secret{}
+	err := r.Get(ctx, client.ObjectKey{Name: key.Name, Namespace: key.Namespace}, found)
+	if err != nil && !errors.IsNotFound(err) {
+		log.Error(err, "Failed to get Secret", "Secret.Namespace", key.Namespace, "Secret.Name", key.Name)
+		return nil, err
+	}
+	secret, err := r.webConfigSecretForPrometheus(ctx, cr, found)
+	var invalid *invalidInputError
+	if goerrors.As(err, &invalid) {
+		return plan, nil
+	} else if err != nil {
+		return nil, err
+	}
+	return append(plan, plannedObject{obj: secret, fields: append(metadata, "data")}), nil
+}
+
+// diffObjects compares the planned objects with the live ones read from the
+// cache, and returns the changes sorted by kind, namespace and name. Only
+// the objects controlled by the Prometheus are reported as deleted, like
+// the reconciliation leaves alone the objects of the same name created by
+// the user.
+func (r *dryRunReconciler) diffObjects(ctx context.Context, cr *monitoringv1alpha1.Prometheus, plan []plannedObject) ([]objectChange, error) {
+	log := ctrllog.FromContext(ctx)
+
+	changes := []objectChange{}
+	for _, p := range plan {
+		gvk, err := apiutil.GVKForObject(p.obj, r.Scheme)
+		if err != nil {
+			return nil, err
+		}
+		change := objectChange{Kind: gvk.Kind, Namespace: p.obj.GetNamespace(), Name: p.obj.GetName()}
+
+		// The live object is read into a new one, not to mix in the fields
+		// of the desired one
+		newObj, err := r.Scheme.New(gvk)
+		if err != nil {
+			return nil, err
+		}
+		live := newObj.(client.Object)
+		err = r.Get(ctx, client.ObjectKeyFromObject(p.obj), live)
+		if err != nil && !errors.IsNotFound(err) {
+			log.Error(err, "Failed to get "+gvk.Kind, gvk.Kind+".Namespace", change.Namespace, gvk.Kind+".Name", change.Name)
+			return nil, err
+		}
+		found := err == nil
+
+		switch {
+		case p.deleted:
+			if found && metav1.IsControlledBy(live, cr) {
+				change.Operation = "delete"
+				changes = append(changes, change)
+			}
+		case !found:
+			change.Operation = "create"
+			changes = append(changes, change)
+		default:
+			desired := p.obj
+			if desired.GetNamespace() != "" {
+				desired = desired.DeepCopyObject().(client.Object)
+				err = ctrl.SetControllerReference(cr, desired, r.Scheme)
+				if err != nil {
+					return nil, err
+				}
+			}
+			paths, err := changedPaths(desired, live, p.fields)
+			if err != nil {
+				return nil, err
+			}
+			if len(paths) > 0 {
+				change.Operation = "update"
+				change.Paths = paths
+				changes = append(changes, change)
+			}
+		}
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		a, b := changes[i], changes[j]
+		if a.Kind != b.Kind {
+			return a.Kind < b.Kind
+		}
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		return a.Name < b.Name
+	})
+	return changes, nil
+}
+
+// changedPaths returns the paths of the fields set on the desired object
+// that differ on the live one, among the given fields when there are some.
+// Like the drift detection of the workloads, the fields only set on the live
+// object, such as the ones defaulted by the API server, aren't changes. The
+// status and the metadata maintained by the API server are left out, and so
+// are the type metadata only set on some of the objects returned by the
+// clients.
+func changedPaths(desired, live client.Object, fields []string) ([]string, error) {
+	d, err := runtime.DefaultUnstructuredConverter.ToUnstructured(desired)
+	if err != nil {
+		return nil, err
+	}
+	l, err := runtime.DefaultUnstructuredConverter.ToUnstructured(live)
+	if err != nil {
+		return nil, err
+	}
+	for _, obj := range []map[string]interface{}{d, l} {
+		delete(obj, "apiVersion")
+		delete(obj, "kind")
+		delete(obj, "status")
+		if metadata, ok := obj["metadata"].(map[string]interface{}); ok {
+			for _, field := range []string{"resourceVersion", "managedFields", "generation", "uid", "creationTimestamp"} {
+				delete(metadata, field)
+			}
+		}
+	}
+	if len(fields) > 0 {
+		d = selectFields(d, fields)
+	}
+	return derivedPaths(d, l, ""), nil
+}
+
+// selectFields returns the fields of an unstructured object at the given
+// dotted paths.
+func selectFields(obj map[string]interface{}, fields []string) map[string]interface{} {
+	selected := map[string]interface{}{}
+	for _, field := range fields {
+		src, dst := obj, selected
+		parts := strings.Split(field, ".")
+		for i, part := range parts {
+			value, ok := src[part]
+			if !ok {
+				break
+			}
+			if i == len(parts)-1 {
+				dst[part] = value
+				break
+			}
+			child, ok := value.(map[string]interface{})
+			if !ok {
+				break
+			}
+			if _, ok := dst[part].(map[string]interface{}); !ok {
+				dst[part] = map[string]interface{}{}
+			}
+			src, dst = child, dst[part].(map[string]interface{})
+		}
+	}
+	return selected
+}
+
+// derivedPaths walks the desired value and returns the paths where the live
+// value differs.
+func derivedPaths(desired, live interface{}, path string) []string {
+	switch d := desired.(type) {
+	case map[string]interface{}:
+		l, ok := live.(map[string]interface{})
+		if !ok {
+			return []string{path}
+		}
+		keys := make([]string, 0, len(d))
+		for key := range d {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		var paths []string
+		for _, key := range keys {
+			child := key
+			if path != "" {
+				child = path + "." + key
+			}
+			paths = append(paths, derivedPaths(d[key], l[key], child)...)
+		}
+		return paths
+	case []interface{}:
+		l, ok := live.([]interface{})
+		if !ok || len(l) != len(d) {
+			return []string{path}
+		}
+		var paths []string
+		for i := range d {
+			paths = append(paths, derivedPaths(d[i], l[i], path+"["+strconv.Itoa(i)+"]")...)
+		}
+		return paths
+	default:
+		if !reflect.DeepEqual(desired, live) {
+			return []string{path}
+		}
+		return nil
+	}
+}
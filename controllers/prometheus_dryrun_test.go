@@ -0,0 +1,196 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	monitoringv1alpha1 "github.com/marieroque/best-prometheus-operator-in-the-world/api/monitoring/v1alpha1"
+)
+
+// dryRunChanges runs the dry run reconciliation of a Prometheus and returns
+// the changes set in its annotation.
+func dryRunChanges(t *testing.T, r *PrometheusReconciler, cr *monitoringv1alpha1.Prometheus) []objectChange {
+	t.Helper()
+	ctx := context.Background()
+	key := types.NamespacedName{Name: cr.Name, Namespace: cr.Namespace}
+	if _, err := (&dryRunReconciler{r}).Reconcile(ctx, ctrl.Request{NamespacedName: key}); err != nil {
+		t.Fatal(err)
+	}
+	found := &monitoringv1alpha1.Prometheus{}
+	if err := r.Get(ctx, key, found); err != nil {
+		t.Fatal(err)
+	}
+	var changes []objectChange
+	if err := json.Unmarshal([]byte(found.Annotations[monitoringv1alpha1.DryRunChangesAnnotation]), &changes); err != nil {
+		t.Fatalf("invalid annotation: %v", err)
+	}
+	return changes
+}
+
+func TestDryRunCreate(t *testing.T) {
+	ctx := context.Background()
+	cr := newTestPrometheus("dryrun")
+	r := newTestReconciler(t, cr)
+
+	changes := dryRunChanges(t, r, cr)
+	want := []objectChange{
+		{Kind: "ClusterRole", Name: clusterRoleNameForPrometheus(cr), Operation: "create"},
+		{Kind: "ClusterRoleBinding", Name: clusterRoleNameForPrometheus(cr), Operation: "create"},
+		{Kind: "ConfigMap", Namespace: cr.Namespace, Name: configmapNameForShard(cr, 0), Operation: "create"},
+		{Kind: "Deployment", Namespace: cr.Namespace, Name: deploymentNameForShard(cr, 0), Operation: "create"},
+		{Kind: "Service", Namespace: cr.Namespace, Name: serviceForPrometheus(cr).Name, Operation: "create"},
+		{Kind: "ServiceAccount", Namespace: cr.Namespace, Name: serviceAccountNameForPrometheus(cr), Operation: "create"},
+	}
+	if !equality.Semantic.DeepEqual(changes, want) {
+		t.Errorf("got changes %+v, want %+v", changes, want)
+	}
+
+	// Nothing is written but the annotation
+	dep := &appsv1.Deployment{}
+	err := r.Get(ctx, types.NamespacedName{Name: deploymentNameForShard(cr, 0), Namespace: cr.Namespace}, dep)
+	if !errors.IsNotFound(err) {
+		t.Errorf("the Deployment was created: %v", err)
+	}
+	found := &monitoringv1alpha1.Prometheus{}
+	if err := r.Get(ctx, types.NamespacedName{Name: cr.Name, Namespace: cr.Namespace}, found); err != nil {
+		t.Fatal(err)
+	}
+	if len(found.Finalizers) > 0 {
+		t.Errorf("got finalizers %v, want none", found.Finalizers)
+	}
+}
+
+func TestDryRunUpdate(t *testing.T) {
+	ctx := context.Background()
+	cr := newTestPrometheus("dryrun")
+	cr.Spec.Web = &monitoringv1alpha1.WebSpec{TLS: &monitoringv1alpha1.WebTLSConfig{}}
+	r := newTestReconciler(t, cr)
+	if err := reconcileUntilDone(t, r, cr); err != nil {
+		t.Fatal(err)
+	}
+
+	// The objects of the reconciliation match the spec
+	if changes := dryRunChanges(t, r, cr); len(changes) > 0 {
+		t.Errorf("got changes %+v after the reconciliation, want none", changes)
+	}
+
+	found := &monitoringv1alpha1.Prometheus{}
+	if err := r.Get(ctx, types.NamespacedName{Name: cr.Name, Namespace: cr.Namespace}, found); err != nil {
+		t.Fatal(err)
+	}
+	replicas := int32(2)
+	found.Spec.Replicas = &replicas
+	if err := r.Update(ctx, found); err != nil {
+		t.Fatal(err)
+	}
+	changes := dryRunChanges(t, r, found)
+	want := []objectChange{
+		{Kind: "Deployment", Namespace: cr.Namespace, Name: deploymentNameForShard(cr, 0), Operation: "update", Paths: []string{"spec.replicas"}},
+		{Kind: "PodDisruptionBudget", Namespace: cr.Namespace, Name: deploymentNameForShard(cr, 0), Operation: "create"},
+	}
+	if !equality.Semantic.DeepEqual(changes, want) {
+		t.Errorf("got changes %+v, want %+v", changes, want)
+	}
+}
+
+func TestDryRunDelete(t *testing.T) {
+	ctx := context.Background()
+	cr := newTestPrometheus("dryrun")
+	cr.Spec.Ingress = &monitoringv1alpha1.IngressSpec{Host: "prometheus.example.com"}
+	r := newTestReconciler(t, cr)
+	if err := reconcileUntilDone(t, r, cr); err != nil {
+		t.Fatal(err)
+	}
+
+	// The Ingress of the operator is deleted with the ingress section
+	found := &monitoringv1alpha1.Prometheus{}
+	key := types.NamespacedName{Name: cr.Name, Namespace: cr.Namespace}
+	if err := r.Get(ctx, key, found); err != nil {
+		t.Fatal(err)
+	}
+	found.Spec.Ingress = nil
+	if err := r.Update(ctx, found); err != nil {
+		t.Fatal(err)
+	}
+	// The Prometheus is no longer served under the external URL of the host
+	changes := dryRunChanges(t, r, found)
+	want := []objectChange{
+		{Kind: "Deployment", Namespace: cr.Namespace, Name: deploymentNameForShard(cr, 0), Operation: "update", Paths: []string{"spec.template.spec.containers[0].args"}},
+		{Kind: "Ingress", Namespace: cr.Namespace, Name: cr.Name, Operation: "delete"},
+	}
+	if !equality.Semantic.DeepEqual(changes, want) {
+		t.Errorf("got changes %+v, want %+v", changes, want)
+	}
+	if err := r.Get(ctx, key, &networkingv1.Ingress{}); err != nil {
+		t.Errorf("the Ingress was deleted: %v", err)
+	}
+
+	// An Ingress of the user with the same name is left alone
+	if err := r.Delete(ctx, &networkingv1.Ingress{ObjectMeta: metav1.ObjectMeta{Name: key.Name, Namespace: key.Namespace}}); err != nil {
+		t.Fatal(err)
+	}
+	user := &networkingv1.Ingress{ObjectMeta: metav1.ObjectMeta{Name: key.Name, Namespace: key.Namespace}}
+	if err := r.Create(ctx, user); err != nil {
+		t.Fatal(err)
+	}
+	for _, change := range dryRunChanges(t, r, found) {
+		if change.Kind == "Ingress" {
+			t.Errorf("got change %+v of the Ingress of the user", change)
+		}
+	}
+}
+
+func TestDryRunFinalizer(t *testing.T) {
+	ctx := context.Background()
+	cr := newTestPrometheus("dryrun")
+	now := metav1.Now()
+	cr.DeletionTimestamp = &now
+	cr.Finalizers = []string{prometheusFinalizer}
+	role := clusterRoleForPrometheus(cr)
+	r := newTestReconciler(t, cr, role)
+	key := types.NamespacedName{Name: cr.Name, Namespace: cr.Namespace}
+
+	if _, err := (&dryRunReconciler{r}).Reconcile(ctx, ctrl.Request{NamespacedName: key}); err != nil {
+		t.Fatal(err)
+	}
+
+	// The finalizer is removed for the deletion to go on
+	found := &monitoringv1alpha1.Prometheus{}
+	if err := r.Get(ctx, key, found); err != nil && !errors.IsNotFound(err) {
+		t.Fatal(err)
+	}
+	if len(found.Finalizers) > 0 {
+		t.Errorf("got finalizers %v, want none", found.Finalizers)
+	}
+
+	// The cluster-scoped objects are left in place
+	if err := r.Get(ctx, types.NamespacedName{Name: role.Name}, &rbacv1.ClusterRole{}); err != nil {
+		t.Errorf("the ClusterRole was deleted: %v", err)
+	}
+}
@@ -0,0 +1,150 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"strings"
+
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	ctrllog "sigs.k8s.io/controller-runtime/pkg/log"
+
+	monitoringv1alpha1 "github.com/marieroque/best-prometheus-operator-in-the-world/api/monitoring/v1alpha1"
+)
+
+// routePrefix returns the path prefix Prometheus serves its endpoints under,
+// without trailing slash
+func routePrefix(cr *monitoringv1alpha1.Prometheus) string {
+	if cr.Spec.Ingress == nil || cr.Spec.Ingress.Path == nil {
+		return ""
+	}
+	return strings.TrimRight(*cr.Spec.Ingress.Path, "/")
+}
+
+// webPath returns the path of a Prometheus endpoint, such as /-/ready,
+// under the route prefix
+func webPath(cr *monitoringv1alpha1.Prometheus, path string) string {
+	return routePrefix(cr) + path
+}
+
+// externalURL returns the URL Prometheus is reachable at through its Ingress
+func externalURL(cr *monitoringv1alpha1.Prometheus) string {
+	scheme := "http"
+	if cr.Spec.Ingress.TLSSecretName != nil {
+		scheme = "https"
+	}
+	return scheme + "://" + cr.Spec.Ingress.Host + routePrefix(cr) + "/"
+}
+
+// ingressForPrometheus returns the Ingress routing the traffic of its host
+// and path to the Service of a Prometheus
+func ingressForPrometheus(cr *monitoringv1alpha1.Prometheus) *networkingv1.Ingress {
+	path := routePrefix(cr) + "/"
+	pathType := networkingv1.PathTypePrefix
+	ing := &networkingv1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        cr.Name,
+			Namespace:   cr.Namespace,
+			Labels:      labelsForPrometheus(cr.Name),
+			Annotations: cr.Spec.Ingress.Annotations,
+		},
+		Spec: networkingv1.IngressSpec{
+			IngressClassName: cr.Spec.Ingress.IngressClassName,
+			Rules: []networkingv1.IngressRule{{
+				Host: cr.Spec.Ingress.Host,
+				IngressRuleValue: networkingv1.IngressRuleValue{
+					HTTP: &networkingv1.HTTPIngressRuleValue{
+						Paths: []networkingv1.HTTPIngressPath{{
+							Path:     path,
+							PathType: &pathType,
+							Backend: networkingv1.IngressBackend{
+								Service: &networkingv1.IngressServiceBackend{
+									Name: cr.Name,
+									Port: networkingv1.ServiceBackendPort{Name: "web"},
+								},
+							},
+						}},
+					},
+				},
+			}},
+		},
+	}
+	if cr.Spec.Ingress.TLSSecretName != nil {
+		ing.Spec.TLS = []networkingv1.IngressTLS{{
+			Hosts:      []string{cr.Spec.Ingress.Host},
+			SecretName: *cr.Spec.Ingress.TLSSecretName,
+		}}
+	}
+	return ing
+}
+
+// reconcileIngress makes sure the Ingress of a Prometheus matches the spec,
+// and deletes it when the ingress section is removed.
+func (r *PrometheusReconciler) reconcileIngress(ctx context.Context, cr *monitoringv1alpha1.Prometheus) error {
+	log := ctrllog.FromContext(ctx)
+
+	if cr.Spec.Ingress == nil {
+		// Only delete the Ingress created for the Prometheus, not one of the
+		// user with the same name
+		found := &networkingv1.Ingress{}
+		err := r.Get(ctx, types.NamespacedName{Name: cr.Name, Namespace: cr.Namespace}, found)
+		if err != nil {
+			if errors.IsNotFound(err) {
+				return nil
+			}
+			log.Error(err, "Failed to get Ingress", "Ingress.Namespace", cr.Namespace, "Ingress.Name", cr.Name)
+			return err
+		}
+		if !metav1.IsControlledBy(found, cr) {
+			return nil
+		}
+		err = r.Delete(ctx, found)
+		if err != nil && !errors.IsNotFound(err) {
+			log.Error(err, "Failed to delete Ingress", "Ingress.Namespace", found.Namespace, "Ingress.Name", found.Name)
+			return err
+		}
+		return nil
+	}
+
+	ing := ingressForPrometheus(cr)
+	found := &networkingv1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      ing.Name,
+			Namespace: ing.Namespace,
+		},
+	}
+	op, err := controllerutil.CreateOrUpdate(ctx, r.Client, found, func() error {
+		found.Labels = ing.Labels
+		found.Annotations = ing.Annotations
+		found.Spec = ing.Spec
+		// Set Prometheus instance as the owner and controller
+		return ctrl.SetControllerReference(cr, found, r.Scheme)
+	})
+	if err != nil {
+		log.Error(err, "Failed to reconcile Ingress", "Ingress.Namespace", ing.Namespace, "Ingress.Name", ing.Name)
+		return err
+	}
+	if op != controllerutil.OperationResultNone {
+		log.Info("Ingress reconciled", "Ingress.Namespace", ing.Namespace, "Ingress.Name", ing.Name, "operation", op)
+	}
+	return nil
+}
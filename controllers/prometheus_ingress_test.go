@@ -0,0 +1,65 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	monitoringv1alpha1 "github.com/marieroque/best-prometheus-operator-in-the-world/api/monitoring/v1alpha1"
+)
+
+func TestDeleteIngress(t *testing.T) {
+	ctx := context.Background()
+	cr := newTestPrometheus("ingress")
+	cr.Spec.Ingress = &monitoringv1alpha1.IngressSpec{Host: "prometheus.example.com"}
+	r := newTestReconciler(t, cr)
+	key := types.NamespacedName{Name: cr.Name, Namespace: cr.Namespace}
+
+	if err := r.reconcileIngress(ctx, cr); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Get(ctx, key, &networkingv1.Ingress{}); err != nil {
+		t.Fatalf("the Ingress wasn't created: %v", err)
+	}
+
+	// The Ingress of the operator is deleted with the ingress section
+	cr.Spec.Ingress = nil
+	if err := r.reconcileIngress(ctx, cr); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Get(ctx, key, &networkingv1.Ingress{}); !errors.IsNotFound(err) {
+		t.Errorf("the Ingress wasn't deleted: %v", err)
+	}
+
+	// An Ingress of the user with the same name is kept
+	user := &networkingv1.Ingress{ObjectMeta: metav1.ObjectMeta{Name: key.Name, Namespace: key.Namespace}}
+	if err := r.Create(ctx, user); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.reconcileIngress(ctx, cr); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Get(ctx, key, &networkingv1.Ingress{}); err != nil {
+		t.Errorf("the Ingress of the user was deleted: %v", err)
+	}
+}
@@ -0,0 +1,236 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ghodss/yaml"
+	promconfig "github.com/prometheus/prometheus/config"
+	// Register the service discoveries for the scrape configs to be checked
+	_ "github.com/prometheus/prometheus/discovery/install"
+	yamlv2 "gopkg.in/yaml.v2"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrllog "sigs.k8s.io/controller-runtime/pkg/log"
+
+	monitoringv1alpha1 "github.com/marieroque/best-prometheus-operator-in-the-world/api/monitoring/v1alpha1"
+)
+
+// configInputs holds the objects referenced by a Prometheus that its
+// configuration is rendered from. They are loaded once per reconciliation
+// and shared by every shard.
+type configInputs struct {
+	// scrapeConfigs are the scrape configs of the selected ScrapeConfig
+	// objects, with their job names prefixed
+	scrapeConfigs []*monitoringv1alpha1.ScrapeConfigSpec
+	// scrapeConfigSelection is the selection reported in the status
+	scrapeConfigSelection *monitoringv1alpha1.SelectedResources
+	// probes are the selected Probe objects
+	probes []*monitoringv1alpha1.Probe
+	// probeSelection is the selection reported in the status
+	probeSelection *monitoringv1alpha1.SelectedResources
+	// additionalScrapeConfigs are the raw scrape configs of the Secret
+	// referenced by spec.additionalScrapeConfigs
+	additionalScrapeConfigs []map[string]interface{}
+	// invalid is set when the Secret referenced by
+	// spec.additionalScrapeConfigs can't be used. The ConfigMaps then keep
+	// their last rendered configuration while the other objects are still
+	// reconciled.
+	invalid *invalidInputError
+}
+
+// invalidInputError is returned when a referenced object can't be used to
+// render the configuration. It is reported in the status instead of being
+// retried, the watch on the object triggering a new reconciliation once it
+// is fixed.
+type invalidInputError struct {
+	conditionType string
+	reason        string
+	message       string
+}
+
+func (e *invalidInputError) Error() string {
+	return e.message
+}
+
+// loadConfigInputs loads the objects referenced by a Prometheus
+func (r *PrometheusReconciler) loadConfigInputs(ctx context.Context, cr *monitoringv1alpha1.Prometheus) (*configInputs, error) {
+	inputs := &configInputs{}
+
+	var err error
+	inputs.scrapeConfigs, inputs.scrapeConfigSelection, err = r.loadScrapeConfigs(ctx, cr, inputs)
+	if err != nil {
+		return nil, err
+	}
+	inputs.probes, inputs.probeSelection, err = r.loadProbes(ctx, cr, inputs)
+	if err != nil {
+		return nil, err
+	}
+	inputs.additionalScrapeConfigs, err = r.loadAdditionalScrapeConfigs(ctx, cr, inputs)
+	if invalid, ok := err.(*invalidInputError); ok {
+		inputs.invalid = invalid
+	} else if err != nil {
+		return nil, err
+	}
+	return inputs, nil
+}
+
+// additionalScrapeConfigsIgnored returns why the raw scrape configs of a
+// Prometheus are left out of its configuration, or an empty string when they
+// are used. Unlike the typed ones they can't be restricted to the namespace
+// of the Prometheus or to the node it runs on.
+func (r *PrometheusReconciler) additionalScrapeConfigsIgnored(cr *monitoringv1alpha1.Prometheus) string {
+	if r.NamespaceScoped {
+		return "the operator only watches some namespaces"
+	}
+	if cr.Spec.DaemonSet {
+		return "Prometheus runs as a DaemonSet"
+	}
+	return ""
+}
+
+// loadAdditionalScrapeConfigs parses the raw scrape configs of a Prometheus
+// and checks their job names don't collide with the other scrape configs,
+// including the ones of the selected objects.
+func (r *PrometheusReconciler) loadAdditionalScrapeConfigs(ctx context.Context, cr *monitoringv1alpha1.Prometheus, inputs *configInputs) ([]map[string]interface{}, error) {
+	log := ctrllog.FromContext(ctx)
+
+	ref := cr.Spec.AdditionalScrapeConfigs
+	if ref == nil || r.additionalScrapeConfigsIgnored(cr) != "" {
+		return nil, nil
+	}
+	invalid := func(reason, format string, args ...interface{}) error {
+		return &invalidInputError{
+			conditionType: monitoringv1alpha1.ConditionTypeAdditionalScrapeConfigsValid,
+			reason:        reason,
+			message:       fmt.Sprintf(format, args...),
+		}
+	}
+
+	secret := &corev1.Secret{}
+	err := r.Get(ctx, types.NamespacedName{Name: ref.Name, Namespace: cr.Namespace}, secret)
+	if errors.IsNotFound(err) {
+		return nil, invalid("SecretNotFound", "Secret %s not found", ref.Name)
+	} else if err != nil {
+		log.Error(err, "Failed to get Secret", "Secret.Namespace", cr.Namespace, "Secret.Name", ref.Name)
+		return nil, err
+	}
+	data, ok := secret.Data[ref.Key]
+	if !ok {
+		return nil, invalid("KeyNotFound", "key %s not found in Secret %s", ref.Key, ref.Name)
+	}
+
+	var scrapeConfigs []map[string]interface{}
+	if err := yaml.Unmarshal(data, &scrapeConfigs); err != nil {
+		return nil, invalid("InvalidYAML", "key %s of Secret %s isn't a YAML list of scrape configs: %v", ref.Key, ref.Name, err)
+	}
+
+	jobNames := inputs.jobNames(cr)
+	for i, sc := range scrapeConfigs {
+		jobName, ok := sc["job_name"].(string)
+		if !ok || jobName == "" {
+			return nil, invalid("MissingJobName", "scrape config %d of Secret %s has no job_name", i, ref.Name)
+		}
+		if jobNames[jobName] {
+			return nil, invalid("DuplicateJobName", "job name %q of Secret %s is already used", jobName, ref.Name)
+		}
+		jobNames[jobName] = true
+		if err := validateScrapeConfig(sc); err != nil {
+			return nil, invalid("InvalidScrapeConfig", "scrape config %q of Secret %s is invalid: %v", jobName, ref.Name, err)
+		}
+	}
+	return scrapeConfigs, nil
+}
+
+// validateScrapeConfig checks a raw scrape config the way Prometheus loads
+// it, so that an invalid one doesn't break the whole configuration.
+func validateScrapeConfig(sc map[string]interface{}) error {
+	data, err := yaml.Marshal(sc)
+	if err != nil {
+		return err
+	}
+	return yamlv2.UnmarshalStrict(data, &promconfig.ScrapeConfig{})
+}
+
+// jobNames returns the job names of the scrape configs and presets of a
+// Prometheus and of the objects loaded so far.
+func (inputs *configInputs) jobNames(cr *monitoringv1alpha1.Prometheus) map[string]bool {
+	jobNames := map[string]bool{}
+	for _, sc := range cr.Spec.ScrapeConfigs {
+		if sc.JobName != nil {
+			jobNames[*sc.JobName] = true
+		}
+	}
+	for _, preset := range cr.Spec.Presets {
+		jobNames[string(preset)] = true
+	}
+	for _, sc := range inputs.scrapeConfigs {
+		jobNames[*sc.JobName] = true
+	}
+	for _, probe := range inputs.probes {
+		jobNames[probe.JobName()] = true
+	}
+	return jobNames
+}
+
+// updateInputsStatus reports the selected objects, the number of scrape jobs
+// and the referenced objects as valid once they were loaded, and removes the
+// conditions of the ones no longer referenced.
+func (r *PrometheusReconciler) updateInputsStatus(ctx context.Context, cr *monitoringv1alpha1.Prometheus, inputs *configInputs) error {
+	log := ctrllog.FromContext(ctx)
+
+	scrapeJobs := int32(len(inputs.jobNames(cr)) + len(inputs.additionalScrapeConfigs) - len(r.skippedPresets(cr)))
+	if !equality.Semantic.DeepEqual(cr.Status.ScrapeConfigs, inputs.scrapeConfigSelection) ||
+		!equality.Semantic.DeepEqual(cr.Status.Probes, inputs.probeSelection) ||
+		cr.Status.ScrapeJobs != scrapeJobs {
+		cr.Status.ScrapeConfigs = inputs.scrapeConfigSelection
+		cr.Status.Probes = inputs.probeSelection
+		cr.Status.ScrapeJobs = scrapeJobs
+		err := r.Status().Update(ctx, cr)
+		if err != nil {
+			log.Error(err, "Failed to update Prometheus status", "Prometheus.Namespace", cr.Namespace, "Prometheus.Name", cr.Name)
+			return err
+		}
+	}
+
+	if cr.Spec.AdditionalScrapeConfigs == nil {
+		return r.removeCondition(ctx, cr, monitoringv1alpha1.ConditionTypeAdditionalScrapeConfigsValid)
+	}
+	if inputs.invalid != nil {
+		// Reported once the workloads are reconciled
+		return nil
+	}
+	if reason := r.additionalScrapeConfigsIgnored(cr); reason != "" {
+		return r.setCondition(ctx, cr, metav1.Condition{
+			Type:    monitoringv1alpha1.ConditionTypeAdditionalScrapeConfigsValid,
+			Status:  metav1.ConditionFalse,
+			Reason:  "Ignored",
+			Message: "The additional scrape configs are ignored since " + reason,
+		})
+	}
+	return r.setCondition(ctx, cr, metav1.Condition{
+		Type:    monitoringv1alpha1.ConditionTypeAdditionalScrapeConfigsValid,
+		Status:  metav1.ConditionTrue,
+		Reason:  "Valid",
+		Message: "The additional scrape configs are valid",
+	})
+}
@@ -0,0 +1,170 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	monitoringv1alpha1 "github.com/marieroque/best-prometheus-operator-in-the-world/api/monitoring/v1alpha1"
+)
+
+// reconcileUntilDone runs the reconciler until it stops requeueing and
+// returns the last error.
+func reconcileUntilDone(t *testing.T, r *PrometheusReconciler, cr *monitoringv1alpha1.Prometheus) error {
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: cr.Name, Namespace: cr.Namespace}}
+	for i := 0; i < 10; i++ {
+		result, err := r.Reconcile(context.Background(), req)
+		if err != nil || !result.Requeue {
+			return err
+		}
+	}
+	t.Fatal("the reconciler kept requeueing")
+	return nil
+}
+
+func TestAdditionalScrapeConfigs(t *testing.T) {
+	ctx := context.Background()
+	cr := newTestPrometheus("raw")
+	cr.Spec.AdditionalScrapeConfigs = &corev1.SecretKeySelector{
+		LocalObjectReference: corev1.LocalObjectReference{Name: "raw"},
+		Key:                  "scrape.yml",
+	}
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "raw", Namespace: cr.Namespace},
+		Data:       map[string][]byte{"scrape.yml": []byte("- job_name: raw\n")},
+	}
+	r := newTestReconciler(t, cr, secret)
+	cmKey := types.NamespacedName{Name: configmapNameForShard(cr, 0), Namespace: cr.Namespace}
+	crKey := types.NamespacedName{Name: cr.Name, Namespace: cr.Namespace}
+
+	// condition returns the AdditionalScrapeConfigsValid condition and the
+	// rendered configuration
+	condition := func() (*metav1.Condition, string) {
+		t.Helper()
+		found := &monitoringv1alpha1.Prometheus{}
+		if err := r.Get(ctx, crKey, found); err != nil {
+			t.Fatal(err)
+		}
+		cm := &corev1.ConfigMap{}
+		if err := r.Get(ctx, cmKey, cm); err != nil {
+			t.Fatal(err)
+		}
+		return meta.FindStatusCondition(found.Status.Conditions, monitoringv1alpha1.ConditionTypeAdditionalScrapeConfigsValid),
+			cm.Data["prometheus.yml"]
+	}
+
+	if err := reconcileUntilDone(t, r, cr); err != nil {
+		t.Fatal(err)
+	}
+	cond, config := condition()
+	if cond == nil || cond.Status != metav1.ConditionTrue {
+		t.Errorf("got condition %+v, want True", cond)
+	}
+	if !strings.Contains(config, "job_name: raw") {
+		t.Errorf("the raw scrape config is missing from the configuration:\n%s", config)
+	}
+
+	// An invalid Secret keeps the last good configuration, and the
+	// workloads are still reconciled
+	secret.Data["scrape.yml"] = []byte("job_name: raw\n")
+	if err := r.Update(ctx, secret); err != nil {
+		t.Fatal(err)
+	}
+	dep := &appsv1.Deployment{}
+	depKey := types.NamespacedName{Name: deploymentNameForShard(cr, 0), Namespace: cr.Namespace}
+	if err := r.Get(ctx, depKey, dep); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Delete(ctx, dep); err != nil {
+		t.Fatal(err)
+	}
+	if err := reconcileUntilDone(t, r, cr); err != nil {
+		t.Fatal(err)
+	}
+	cond, config = condition()
+	if cond == nil || cond.Status != metav1.ConditionFalse || cond.Reason != "InvalidYAML" {
+		t.Errorf("got condition %+v, want False with reason InvalidYAML", cond)
+	}
+	if !strings.Contains(config, "job_name: raw") {
+		t.Errorf("the last good configuration wasn't kept:\n%s", config)
+	}
+	if err := r.Get(ctx, depKey, dep); err != nil {
+		t.Errorf("the Deployment wasn't recreated: %v", err)
+	}
+
+	// A namespace-scoped operator ignores them
+	secret.Data["scrape.yml"] = []byte("- job_name: raw\n")
+	if err := r.Update(ctx, secret); err != nil {
+		t.Fatal(err)
+	}
+	r.NamespaceScoped = true
+	if err := reconcileUntilDone(t, r, cr); err != nil {
+		t.Fatal(err)
+	}
+	cond, config = condition()
+	if cond == nil || cond.Status != metav1.ConditionFalse || cond.Reason != "Ignored" {
+		t.Errorf("got condition %+v, want False with reason Ignored", cond)
+	}
+	if strings.Contains(config, "job_name: raw") {
+		t.Errorf("the raw scrape config wasn't ignored:\n%s", config)
+	}
+}
+
+func TestAdditionalScrapeConfigsValidation(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		raw    string
+		reason string
+	}{
+		{"valid", "- job_name: raw\n  consul_sd_configs:\n  - server: consul:8500\n", ""},
+		{"unknown field", "- job_name: raw\n  scrape_intervall: 30s\n", "InvalidScrapeConfig"},
+		{"invalid duration", "- job_name: raw\n  scrape_interval: often\n", "InvalidScrapeConfig"},
+		{"invalid discovery", "- job_name: raw\n  kubernetes_sd_configs:\n  - role: container\n", "InvalidScrapeConfig"},
+		{"missing job name", "- scrape_interval: 30s\n", "MissingJobName"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			cr := newTestPrometheus("raw")
+			cr.Spec.AdditionalScrapeConfigs = &corev1.SecretKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: "raw"},
+				Key:                  "scrape.yml",
+			}
+			secret := &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "raw", Namespace: cr.Namespace},
+				Data:       map[string][]byte{"scrape.yml": []byte(tc.raw)},
+			}
+			r := newTestReconciler(t, cr, secret)
+
+			_, err := r.loadAdditionalScrapeConfigs(context.Background(), cr, &configInputs{})
+			invalid, _ := err.(*invalidInputError)
+			switch {
+			case tc.reason == "" && err != nil:
+				t.Errorf("got error %v, want none", err)
+			case tc.reason != "" && (invalid == nil || invalid.reason != tc.reason):
+				t.Errorf("got error %v, want reason %s", err, tc.reason)
+			}
+		})
+	}
+}
@@ -0,0 +1,210 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	ctrllog "sigs.k8s.io/controller-runtime/pkg/log"
+
+	monitoringv1alpha1 "github.com/marieroque/best-prometheus-operator-in-the-world/api/monitoring/v1alpha1"
+)
+
+// kubeDNSPeer selects the kube-dns pods the Prometheus pods resolve the names
+// of their targets with.
+var kubeDNSPeer = networkingv1.NetworkPolicyPeer{
+	NamespaceSelector: &metav1.LabelSelector{
+		MatchLabels: map[string]string{corev1.LabelMetadataName: metav1.NamespaceSystem},
+	},
+	PodSelector: &metav1.LabelSelector{
+		MatchLabels: map[string]string{"k8s-app": "kube-dns"},
+	},
+}
+
+// networkPolicyForPrometheus returns the NetworkPolicy only letting the
+// configured peers reach the Prometheus pods. apiServerCIDRs are the
+// destinations of the API server traffic allowed besides the egress rules.
+func networkPolicyForPrometheus(cr *monitoringv1alpha1.Prometheus, apiServerCIDRs []string) *networkingv1.NetworkPolicy {
+	tcp, udp := corev1.ProtocolTCP, corev1.ProtocolUDP
+	web := intstr.FromInt(webPort)
+	ports := []networkingv1.NetworkPolicyPort{{Protocol: &tcp, Port: &web}}
+	if cr.Spec.Thanos != nil {
+		grpc := intstr.FromInt(thanosGRPCPort)
+		ports = append(ports, networkingv1.NetworkPolicyPort{Protocol: &tcp, Port: &grpc})
+	}
+
+	var ingress []networkingv1.NetworkPolicyIngressRule
+	if len(cr.Spec.NetworkPolicy.From) > 0 {
+		ingress = []networkingv1.NetworkPolicyIngressRule{{
+			From:  cr.Spec.NetworkPolicy.From,
+			Ports: ports,
+		}}
+	}
+
+	egress := cr.Spec.NetworkPolicy.Egress
+	if len(egress) == 0 {
+		// An empty rule allows all the egress traffic
+		egress = []networkingv1.NetworkPolicyEgressRule{{}}
+	} else {
+		dns := intstr.FromInt(53)
+		https, apiserver := intstr.FromInt(443), intstr.FromInt(6443)
+		apiServerPeers := make([]networkingv1.NetworkPolicyPeer, 0, len(apiServerCIDRs))
+		for _, cidr := range apiServerCIDRs {
+			apiServerPeers = append(apiServerPeers, networkingv1.NetworkPolicyPeer{
+				IPBlock: &networkingv1.IPBlock{CIDR: cidr},
+			})
+		}
+		egress = append(egress, networkingv1.NetworkPolicyEgressRule{
+			To: []networkingv1.NetworkPolicyPeer{kubeDNSPeer},
+			Ports: []networkingv1.NetworkPolicyPort{
+				{Protocol: &udp, Port: &dns},
+				{Protocol: &tcp, Port: &dns},
+			},
+		}, networkingv1.NetworkPolicyEgressRule{
+			To: apiServerPeers,
+			Ports: []networkingv1.NetworkPolicyPort{
+				{Protocol: &tcp, Port: &https},
+				{Protocol: &tcp, Port: &apiserver},
+			},
+		})
+	}
+
+	return &networkingv1.NetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      cr.Name,
+			Namespace: cr.Namespace,
+			Labels:    labelsForPrometheus(cr.Name),
+		},
+		Spec: networkingv1.NetworkPolicySpec{
+			PodSelector: metav1.LabelSelector{MatchLabels: labelsForPrometheus(cr.Name)},
+			PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeIngress, networkingv1.PolicyTypeEgress},
+			Ingress:     ingress,
+			Egress:      egress,
+		},
+	}
+}
+
+// apiServerCIDRs returns the CIDRs of the API server the Prometheus pods are
+// allowed to reach: the configured ones, or the addresses of the endpoints of
+// the default/kubernetes Service.
+func (r *PrometheusReconciler) apiServerCIDRs(ctx context.Context, cr *monitoringv1alpha1.Prometheus) ([]string, error) {
+	log := ctrllog.FromContext(ctx)
+
+	if len(cr.Spec.NetworkPolicy.APIServerCIDRs) > 0 {
+		return cr.Spec.NetworkPolicy.APIServerCIDRs, nil
+	}
+	if r.NamespaceScoped {
+		// The endpoints of the default namespace aren't readable
+		return nil, fmt.Errorf("networkPolicy.apiServerCIDRs must be set when the operator only watches some namespaces")
+	}
+
+	endpoints := &corev1.Endpoints{}
+	err := r.Get(ctx, types.NamespacedName{Name: "kubernetes", Namespace: metav1.NamespaceDefault}, endpoints)
+	if err != nil {
+		log.Error(err, "Failed to get Endpoints", "Endpoints.Namespace", metav1.NamespaceDefault, "Endpoints.Name", "kubernetes")
+		return nil, err
+	}
+	var cidrs []string
+	for _, subset := range endpoints.Subsets {
+		for _, address := range subset.Addresses {
+			ip := net.ParseIP(address.IP)
+			if ip == nil {
+				continue
+			}
+			if ip.To4() != nil {
+				cidrs = append(cidrs, ip.String()+"/32")
+			} else {
+				cidrs = append(cidrs, ip.String()+"/128")
+			}
+		}
+	}
+	if len(cidrs) == 0 {
+		return nil, fmt.Errorf("no address in the endpoints of the default/kubernetes Service")
+	}
+	// Keep the NetworkPolicy stable whatever the order of the addresses
+	sort.Strings(cidrs)
+	return cidrs, nil
+}
+
+// reconcileNetworkPolicy makes sure the NetworkPolicy of a Prometheus matches
+// the spec, and deletes it when the networkPolicy section is removed.
+func (r *PrometheusReconciler) reconcileNetworkPolicy(ctx context.Context, cr *monitoringv1alpha1.Prometheus) error {
+	log := ctrllog.FromContext(ctx)
+
+	found := &networkingv1.NetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      cr.Name,
+			Namespace: cr.Namespace,
+		},
+	}
+	if cr.Spec.NetworkPolicy == nil {
+		// Only delete the NetworkPolicy created for the Prometheus, not one
+		// of the user with the same name
+		err := r.Get(ctx, types.NamespacedName{Name: found.Name, Namespace: found.Namespace}, found)
+		if err != nil {
+			if errors.IsNotFound(err) {
+				return nil
+			}
+			log.Error(err, "Failed to get NetworkPolicy", "NetworkPolicy.Namespace", found.Namespace, "NetworkPolicy.Name", found.Name)
+			return err
+		}
+		if !metav1.IsControlledBy(found, cr) {
+			return nil
+		}
+		err = r.Delete(ctx, found)
+		if err != nil && !errors.IsNotFound(err) {
+			log.Error(err, "Failed to delete NetworkPolicy", "NetworkPolicy.Namespace", found.Namespace, "NetworkPolicy.Name", found.Name)
+			return err
+		}
+		return nil
+	}
+
+	var cidrs []string
+	if len(cr.Spec.NetworkPolicy.Egress) > 0 {
+		var err error
+		cidrs, err = r.apiServerCIDRs(ctx, cr)
+		if err != nil {
+			return err
+		}
+	}
+	np := networkPolicyForPrometheus(cr, cidrs)
+	op, err := controllerutil.CreateOrUpdate(ctx, r.Client, found, func() error {
+		found.Labels = np.Labels
+		found.Spec = np.Spec
+		// Set Prometheus instance as the owner and controller
+		return ctrl.SetControllerReference(cr, found, r.Scheme)
+	})
+	if err != nil {
+		log.Error(err, "Failed to reconcile NetworkPolicy", "NetworkPolicy.Namespace", np.Namespace, "NetworkPolicy.Name", np.Name)
+		return err
+	}
+	if op != controllerutil.OperationResultNone {
+		log.Info("NetworkPolicy reconciled", "NetworkPolicy.Namespace", np.Namespace, "NetworkPolicy.Name", np.Name, "operation", op)
+	}
+	return nil
+}
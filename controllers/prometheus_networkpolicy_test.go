@@ -0,0 +1,139 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	monitoringv1alpha1 "github.com/marieroque/best-prometheus-operator-in-the-world/api/monitoring/v1alpha1"
+)
+
+func TestNetworkPolicyEgress(t *testing.T) {
+	cr := newTestPrometheus("egress")
+	cr.Spec.NetworkPolicy = &monitoringv1alpha1.NetworkPolicySpec{}
+
+	np := networkPolicyForPrometheus(cr, nil)
+	if len(np.Spec.Egress) != 1 || !equality.Semantic.DeepEqual(np.Spec.Egress[0], networkingv1.NetworkPolicyEgressRule{}) {
+		t.Errorf("without egress rules, got %+v, want all the egress traffic allowed", np.Spec.Egress)
+	}
+
+	target := networkingv1.NetworkPolicyEgressRule{
+		To: []networkingv1.NetworkPolicyPeer{{IPBlock: &networkingv1.IPBlock{CIDR: "192.0.2.0/24"}}},
+	}
+	cr.Spec.NetworkPolicy.Egress = []networkingv1.NetworkPolicyEgressRule{target}
+	np = networkPolicyForPrometheus(cr, []string{"10.0.0.1/32"})
+	if len(np.Spec.Egress) != 3 {
+		t.Fatalf("got %d egress rules, want 3", len(np.Spec.Egress))
+	}
+	if !equality.Semantic.DeepEqual(np.Spec.Egress[0], target) {
+		t.Errorf("got first egress rule %+v, want %+v", np.Spec.Egress[0], target)
+	}
+	// No rule may allow every destination
+	for i, rule := range np.Spec.Egress {
+		if len(rule.To) == 0 {
+			t.Errorf("egress rule %d allows every destination", i)
+		}
+	}
+	if dns := np.Spec.Egress[1]; !equality.Semantic.DeepEqual(dns.To, []networkingv1.NetworkPolicyPeer{kubeDNSPeer}) {
+		t.Errorf("got DNS peers %+v, want the kube-dns pods", dns.To)
+	}
+	want := []networkingv1.NetworkPolicyPeer{{IPBlock: &networkingv1.IPBlock{CIDR: "10.0.0.1/32"}}}
+	if api := np.Spec.Egress[2]; !equality.Semantic.DeepEqual(api.To, want) {
+		t.Errorf("got API server peers %+v, want %+v", api.To, want)
+	}
+}
+
+func TestAPIServerCIDRs(t *testing.T) {
+	endpoints := &corev1.Endpoints{
+		ObjectMeta: metav1.ObjectMeta{Name: "kubernetes", Namespace: metav1.NamespaceDefault},
+		Subsets: []corev1.EndpointSubset{{
+			Addresses: []corev1.EndpointAddress{{IP: "10.0.0.2"}, {IP: "10.0.0.1"}, {IP: "fd00::1"}},
+		}},
+	}
+	cr := newTestPrometheus("cidrs")
+	cr.Spec.NetworkPolicy = &monitoringv1alpha1.NetworkPolicySpec{}
+
+	r := newTestReconciler(t, endpoints)
+	cidrs, err := r.apiServerCIDRs(context.Background(), cr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"10.0.0.1/32", "10.0.0.2/32", "fd00::1/128"}; !reflect.DeepEqual(cidrs, want) {
+		t.Errorf("got %v, want %v", cidrs, want)
+	}
+
+	// The configured CIDRs take precedence, and are required when the
+	// endpoints can't be read
+	r.NamespaceScoped = true
+	if _, err := r.apiServerCIDRs(context.Background(), cr); err == nil {
+		t.Error("expected an error without CIDRs in namespaced mode")
+	}
+	cr.Spec.NetworkPolicy.APIServerCIDRs = []string{"10.0.0.0/24"}
+	cidrs, err = r.apiServerCIDRs(context.Background(), cr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"10.0.0.0/24"}; !reflect.DeepEqual(cidrs, want) {
+		t.Errorf("got %v, want %v", cidrs, want)
+	}
+}
+
+func TestDeleteNetworkPolicy(t *testing.T) {
+	ctx := context.Background()
+	cr := newTestPrometheus("isolated")
+	cr.Spec.NetworkPolicy = &monitoringv1alpha1.NetworkPolicySpec{}
+	r := newTestReconciler(t, cr)
+	key := types.NamespacedName{Name: cr.Name, Namespace: cr.Namespace}
+
+	if err := r.reconcileNetworkPolicy(ctx, cr); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Get(ctx, key, &networkingv1.NetworkPolicy{}); err != nil {
+		t.Fatalf("the NetworkPolicy wasn't created: %v", err)
+	}
+
+	// The NetworkPolicy of the operator is deleted with the networkPolicy
+	// section
+	cr.Spec.NetworkPolicy = nil
+	if err := r.reconcileNetworkPolicy(ctx, cr); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Get(ctx, key, &networkingv1.NetworkPolicy{}); !errors.IsNotFound(err) {
+		t.Errorf("the NetworkPolicy wasn't deleted: %v", err)
+	}
+
+	// A NetworkPolicy of the user with the same name is kept
+	user := &networkingv1.NetworkPolicy{ObjectMeta: metav1.ObjectMeta{Name: key.Name, Namespace: key.Namespace}}
+	if err := r.Create(ctx, user); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.reconcileNetworkPolicy(ctx, cr); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Get(ctx, key, &networkingv1.NetworkPolicy{}); err != nil {
+		t.Errorf("the NetworkPolicy of the user was deleted: %v", err)
+	}
+}
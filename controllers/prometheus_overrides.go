@@ -0,0 +1,101 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"encoding/json"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/strategicpatch"
+
+	monitoringv1alpha1 "github.com/marieroque/best-prometheus-operator-in-the-world/api/monitoring/v1alpha1"
+)
+
+// applyPodOverrides merges the containers, volumes, environment and arguments
+// of the spec into the pod generated by the operator.
+func applyPodOverrides(cr *monitoringv1alpha1.Prometheus, spec *corev1.PodSpec) error {
+	prometheus := &spec.Containers[0]
+	prometheus.Args = append(prometheus.Args, cr.Spec.AdditionalArgs...)
+	prometheus.Env = append(prometheus.Env, cr.Spec.Env...)
+	prometheus.VolumeMounts = append(prometheus.VolumeMounts, cr.Spec.VolumeMounts...)
+
+	var err error
+	spec.Containers, err = mergeContainers(spec.Containers, cr.Spec.Containers)
+	if err != nil {
+		return err
+	}
+	spec.InitContainers, err = mergeContainers(spec.InitContainers, cr.Spec.InitContainers)
+	if err != nil {
+		return err
+	}
+	spec.Volumes = mergeVolumes(spec.Volumes, cr.Spec.Volumes)
+	return nil
+}
+
+// mergeContainers strategically merges the patches into the containers of
+// the same name, and appends the other ones.
+func mergeContainers(base, patches []corev1.Container) ([]corev1.Container, error) {
+	merged := append([]corev1.Container{}, base...)
+	for _, patch := range patches {
+		found := false
+		for i := range merged {
+			if merged[i].Name != patch.Name {
+				continue
+			}
+			found = true
+			baseJson, err := json.Marshal(merged[i])
+			if err != nil {
+				return nil, err
+			}
+			patchJson, err := json.Marshal(patch)
+			if err != nil {
+				return nil, err
+			}
+			resultJson, err := strategicpatch.StrategicMergePatch(baseJson, patchJson, corev1.Container{})
+			if err != nil {
+				return nil, err
+			}
+			merged[i] = corev1.Container{}
+			if err := json.Unmarshal(resultJson, &merged[i]); err != nil {
+				return nil, err
+			}
+		}
+		if !found {
+			merged = append(merged, patch)
+		}
+	}
+	return merged, nil
+}
+
+// mergeVolumes replaces the volumes of the same name, and appends the other
+// ones.
+func mergeVolumes(base, volumes []corev1.Volume) []corev1.Volume {
+	merged := append([]corev1.Volume{}, base...)
+	for _, v := range volumes {
+		found := false
+		for i := range merged {
+			if merged[i].Name == v.Name {
+				merged[i] = v
+				found = true
+			}
+		}
+		if !found {
+			merged = append(merged, v)
+		}
+	}
+	return merged
+}
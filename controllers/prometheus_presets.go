@@ -0,0 +1,226 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	monitoringv1alpha1 "github.com/marieroque/best-prometheus-operator-in-the-world/api/monitoring/v1alpha1"
+)
+
+// Files of the service account mounted in the Prometheus pods
+const (
+	serviceAccountCAFile    = "/var/run/secrets/kubernetes.io/serviceaccount/ca.crt"
+	serviceAccountTokenFile = "/var/run/secrets/kubernetes.io/serviceaccount/token"
+)
+
+// clusterPresets are the presets discovering nodes or the endpoints of the
+// system namespaces. A namespace-scoped operator restricts the service
+// discoveries to the namespace of the Prometheus and only grants it a Role,
+// so they would find no target.
+var clusterPresets = map[monitoringv1alpha1.ScrapePreset]bool{
+	monitoringv1alpha1.PresetKubelet:   true,
+	monitoringv1alpha1.PresetCAdvisor:  true,
+	monitoringv1alpha1.PresetAPIServer: true,
+	monitoringv1alpha1.PresetCoreDNS:   true,
+}
+
+// skippedPresets returns the presets of a Prometheus that are left out of its
+// configuration.
+func (r *PrometheusReconciler) skippedPresets(cr *monitoringv1alpha1.Prometheus) []monitoringv1alpha1.ScrapePreset {
+	if !r.NamespaceScoped {
+		return nil
+	}
+	var skipped []monitoringv1alpha1.ScrapePreset
+	for _, preset := range cr.Spec.Presets {
+		if clusterPresets[preset] {
+			skipped = append(skipped, preset)
+		}
+	}
+	return skipped
+}
+
+// presetScrapeConfigs returns the scrape configs the presets of a Prometheus
+// expand into, in the order of the presets.
+func (r *PrometheusReconciler) presetScrapeConfigs(cr *monitoringv1alpha1.Prometheus) []*monitoringv1alpha1.ScrapeConfigSpec {
+	scrapeConfigs := make([]*monitoringv1alpha1.ScrapeConfigSpec, 0, len(cr.Spec.Presets))
+	for _, preset := range cr.Spec.Presets {
+		if r.NamespaceScoped && clusterPresets[preset] {
+			continue
+		}
+		var sc *monitoringv1alpha1.ScrapeConfigSpec
+		switch preset {
+		case monitoringv1alpha1.PresetKubelet:
+			sc = kubeletScrapeConfig("")
+		case monitoringv1alpha1.PresetCAdvisor:
+			sc = kubeletScrapeConfig("/metrics/cadvisor")
+		case monitoringv1alpha1.PresetAPIServer:
+			sc = apiServerScrapeConfig()
+		case monitoringv1alpha1.PresetCoreDNS:
+			sc = coreDNSScrapeConfig()
+		case monitoringv1alpha1.PresetAnnotatedPods:
+			sc = annotatedPodsScrapeConfig()
+		default:
+			continue
+		}
+		jobName := string(preset)
+		sc.JobName = &jobName
+		scrapeConfigs = append(scrapeConfigs, sc)
+	}
+	return scrapeConfigs
+}
+
+// updatePresetsStatus reports the presets skipped since the operator only
+// watches some namespaces.
+func (r *PrometheusReconciler) updatePresetsStatus(ctx context.Context, cr *monitoringv1alpha1.Prometheus) error {
+	if len(cr.Spec.Presets) == 0 {
+		return r.removeCondition(ctx, cr, monitoringv1alpha1.ConditionTypePresetsSupported)
+	}
+	skipped := r.skippedPresets(cr)
+	if len(skipped) == 0 {
+		return r.setCondition(ctx, cr, metav1.Condition{
+			Type:    monitoringv1alpha1.ConditionTypePresetsSupported,
+			Status:  metav1.ConditionTrue,
+			Reason:  "Supported",
+			Message: "Every preset is part of the configuration",
+		})
+	}
+	names := make([]string, 0, len(skipped))
+	for _, preset := range skipped {
+		names = append(names, string(preset))
+	}
+	return r.setCondition(ctx, cr, metav1.Condition{
+		Type:    monitoringv1alpha1.ConditionTypePresetsSupported,
+		Status:  metav1.ConditionFalse,
+		Reason:  "NamespaceScoped",
+		Message: fmt.Sprintf("The %s presets are skipped since the operator only watches some namespaces", strings.Join(names, ", ")),
+	})
+}
+
+// kubeletScrapeConfig scrapes the kubelet of every node on the given metrics
+// path. The serving certificates of the kubelets are usually self-signed,
+// and therefore not verified.
+func kubeletScrapeConfig(metricsPath string) *monitoringv1alpha1.ScrapeConfigSpec {
+	sc := &monitoringv1alpha1.ScrapeConfigSpec{
+		Scheme:          stringPtr("https"),
+		TLSConfig:       &monitoringv1alpha1.TLSConfig{InsecureSkipVerify: boolPtr(true)},
+		BearerTokenFile: stringPtr(serviceAccountTokenFile),
+		K8SSDConfigs:    []*monitoringv1alpha1.K8SSDConfig{{Role: stringPtr("node")}},
+		RelabelConfigs: []*monitoringv1alpha1.RelabelConfig{{
+			Action: stringPtr("labelmap"),
+			Regex:  stringPtr("__meta_kubernetes_node_label_(.+)"),
+		}},
+	}
+	if metricsPath != "" {
+		sc.MetricsPath = &metricsPath
+	}
+	return sc
+}
+
+// apiServerScrapeConfig scrapes the endpoints of the default/kubernetes
+// Service.
+func apiServerScrapeConfig() *monitoringv1alpha1.ScrapeConfigSpec {
+	return &monitoringv1alpha1.ScrapeConfigSpec{
+		Scheme:          stringPtr("https"),
+		TLSConfig:       &monitoringv1alpha1.TLSConfig{CAFile: stringPtr(serviceAccountCAFile)},
+		BearerTokenFile: stringPtr(serviceAccountTokenFile),
+		K8SSDConfigs: []*monitoringv1alpha1.K8SSDConfig{{
+			Role:       stringPtr("endpoints"),
+			Namespaces: &monitoringv1alpha1.K8SSDNamespaces{Names: []string{"default"}},
+		}},
+		RelabelConfigs: []*monitoringv1alpha1.RelabelConfig{{
+			SourceLabels: []string{
+				"__meta_kubernetes_service_name",
+				"__meta_kubernetes_endpoint_port_name",
+			},
+			Action: stringPtr("keep"),
+			Regex:  stringPtr("kubernetes;https"),
+		}},
+	}
+}
+
+// coreDNSScrapeConfig scrapes the metrics port of the endpoints of the
+// kube-dns Service.
+func coreDNSScrapeConfig() *monitoringv1alpha1.ScrapeConfigSpec {
+	return &monitoringv1alpha1.ScrapeConfigSpec{
+		K8SSDConfigs: []*monitoringv1alpha1.K8SSDConfig{{
+			Role:       stringPtr("endpoints"),
+			Namespaces: &monitoringv1alpha1.K8SSDNamespaces{Names: []string{"kube-system"}},
+		}},
+		RelabelConfigs: []*monitoringv1alpha1.RelabelConfig{{
+			SourceLabels: []string{
+				"__meta_kubernetes_service_name",
+				"__meta_kubernetes_endpoint_port_name",
+			},
+			Action: stringPtr("keep"),
+			Regex:  stringPtr("kube-dns;metrics"),
+		}, {
+			SourceLabels: []string{"__meta_kubernetes_pod_name"},
+			TargetLabel:  stringPtr("pod"),
+		}},
+	}
+}
+
+// annotatedPodsScrapeConfig scrapes the pods opting in through the
+// prometheus.io annotations.
+func annotatedPodsScrapeConfig() *monitoringv1alpha1.ScrapeConfigSpec {
+	return &monitoringv1alpha1.ScrapeConfigSpec{
+		K8SSDConfigs: []*monitoringv1alpha1.K8SSDConfig{{Role: stringPtr("pod")}},
+		RelabelConfigs: []*monitoringv1alpha1.RelabelConfig{{
+			SourceLabels: []string{"__meta_kubernetes_pod_annotation_prometheus_io_scrape"},
+			Action:       stringPtr("keep"),
+			Regex:        stringPtr("true"),
+		}, {
+			SourceLabels: []string{"__meta_kubernetes_pod_annotation_prometheus_io_scheme"},
+			Regex:        stringPtr("(https?)"),
+			TargetLabel:  stringPtr("__scheme__"),
+		}, {
+			SourceLabels: []string{"__meta_kubernetes_pod_annotation_prometheus_io_path"},
+			Regex:        stringPtr("(.+)"),
+			TargetLabel:  stringPtr("__metrics_path__"),
+		}, {
+			SourceLabels: []string{
+				"__address__",
+				"__meta_kubernetes_pod_annotation_prometheus_io_port",
+			},
+			Regex:       stringPtr(`([^:]+)(?::\d+)?;(\d+)`),
+			Replacement: stringPtr("$1:$2"),
+			TargetLabel: stringPtr("__address__"),
+		}, {
+			Action: stringPtr("labelmap"),
+			Regex:  stringPtr("__meta_kubernetes_pod_label_(.+)"),
+		}, {
+			SourceLabels: []string{"__meta_kubernetes_namespace"},
+			TargetLabel:  stringPtr("namespace"),
+		}, {
+			SourceLabels: []string{"__meta_kubernetes_pod_name"},
+			TargetLabel:  stringPtr("pod"),
+		}},
+	}
+}
+
+func stringPtr(s string) *string {
+	return &s
+}
+
+func boolPtr(b bool) *bool {
+	return &b
+}
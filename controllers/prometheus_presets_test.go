@@ -0,0 +1,185 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/ghodss/yaml"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	monitoringv1alpha1 "github.com/marieroque/best-prometheus-operator-in-the-world/api/monitoring/v1alpha1"
+)
+
+func TestPresetScrapeConfigs(t *testing.T) {
+	tests := []struct {
+		preset monitoringv1alpha1.ScrapePreset
+		want   string
+	}{{
+		preset: monitoringv1alpha1.PresetKubelet,
+		want: `bearer_token_file: /var/run/secrets/kubernetes.io/serviceaccount/token
+job_name: kubelet
+kubernetes_sd_configs:
+- role: node
+relabel_configs:
+- action: labelmap
+  regex: __meta_kubernetes_node_label_(.+)
+scheme: https
+tls_config:
+  insecure_skip_verify: true
+`,
+	}, {
+		preset: monitoringv1alpha1.PresetCAdvisor,
+		want: `bearer_token_file: /var/run/secrets/kubernetes.io/serviceaccount/token
+job_name: cadvisor
+kubernetes_sd_configs:
+- role: node
+metrics_path: /metrics/cadvisor
+relabel_configs:
+- action: labelmap
+  regex: __meta_kubernetes_node_label_(.+)
+scheme: https
+tls_config:
+  insecure_skip_verify: true
+`,
+	}, {
+		preset: monitoringv1alpha1.PresetAPIServer,
+		want: `bearer_token_file: /var/run/secrets/kubernetes.io/serviceaccount/token
+job_name: apiserver
+kubernetes_sd_configs:
+- namespaces:
+    names:
+    - default
+  role: endpoints
+relabel_configs:
+- action: keep
+  regex: kubernetes;https
+  source_labels:
+  - __meta_kubernetes_service_name
+  - __meta_kubernetes_endpoint_port_name
+scheme: https
+tls_config:
+  ca_file: /var/run/secrets/kubernetes.io/serviceaccount/ca.crt
+`,
+	}, {
+		preset: monitoringv1alpha1.PresetCoreDNS,
+		want: `job_name: coredns
+kubernetes_sd_configs:
+- namespaces:
+    names:
+    - kube-system
+  role: endpoints
+relabel_configs:
+- action: keep
+  regex: kube-dns;metrics
+  source_labels:
+  - __meta_kubernetes_service_name
+  - __meta_kubernetes_endpoint_port_name
+- source_labels:
+  - __meta_kubernetes_pod_name
+  target_label: pod
+`,
+	}, {
+		preset: monitoringv1alpha1.PresetAnnotatedPods,
+		want: `job_name: annotated-pods
+kubernetes_sd_configs:
+- role: pod
+relabel_configs:
+- action: keep
+  regex: "true"
+  source_labels:
+  - __meta_kubernetes_pod_annotation_prometheus_io_scrape
+- regex: (https?)
+  source_labels:
+  - __meta_kubernetes_pod_annotation_prometheus_io_scheme
+  target_label: __scheme__
+- regex: (.+)
+  source_labels:
+  - __meta_kubernetes_pod_annotation_prometheus_io_path
+  target_label: __metrics_path__
+- regex: ([^:]+)(?::\d+)?;(\d+)
+  replacement: $1:$2
+  source_labels:
+  - __address__
+  - __meta_kubernetes_pod_annotation_prometheus_io_port
+  target_label: __address__
+- action: labelmap
+  regex: __meta_kubernetes_pod_label_(.+)
+- source_labels:
+  - __meta_kubernetes_namespace
+  target_label: namespace
+- source_labels:
+  - __meta_kubernetes_pod_name
+  target_label: pod
+`,
+	}}
+
+	r := &PrometheusReconciler{}
+	for _, tt := range tests {
+		cr := newTestPrometheus("presets")
+		cr.Spec.Presets = []monitoringv1alpha1.ScrapePreset{tt.preset}
+		scrapeConfigs := r.presetScrapeConfigs(cr)
+		if len(scrapeConfigs) != 1 {
+			t.Fatalf("%s: got %d scrape configs, want 1", tt.preset, len(scrapeConfigs))
+		}
+		dataJson, err := json.Marshal(scrapeConfigs[0])
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := yaml.JSONToYAML(dataJson)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != tt.want {
+			t.Errorf("%s: got\n%s\nwant\n%s", tt.preset, got, tt.want)
+		}
+	}
+}
+
+func TestPresetsNamespaceScoped(t *testing.T) {
+	ctx := context.Background()
+	cr := newTestPrometheus("namespaced-presets")
+	cr.Spec.Presets = []monitoringv1alpha1.ScrapePreset{
+		monitoringv1alpha1.PresetKubelet,
+		monitoringv1alpha1.PresetAnnotatedPods,
+		monitoringv1alpha1.PresetCoreDNS,
+	}
+	r := newTestReconciler(t, cr)
+	r.NamespaceScoped = true
+
+	scrapeConfigs := r.presetScrapeConfigs(cr)
+	if len(scrapeConfigs) != 1 || *scrapeConfigs[0].JobName != string(monitoringv1alpha1.PresetAnnotatedPods) {
+		t.Errorf("got %d scrape configs, want only the annotated-pods one", len(scrapeConfigs))
+	}
+
+	if err := r.updatePresetsStatus(ctx, cr); err != nil {
+		t.Fatal(err)
+	}
+	found := &monitoringv1alpha1.Prometheus{}
+	if err := r.Get(ctx, types.NamespacedName{Name: cr.Name, Namespace: cr.Namespace}, found); err != nil {
+		t.Fatal(err)
+	}
+	cond := meta.FindStatusCondition(found.Status.Conditions, monitoringv1alpha1.ConditionTypePresetsSupported)
+	want := "The kubelet, coredns presets are skipped since the operator only watches some namespaces"
+	if cond == nil || cond.Status != metav1.ConditionFalse || cond.Message != want {
+		t.Errorf("got condition %+v, want False with message %q", cond, want)
+	}
+}
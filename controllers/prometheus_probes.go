@@ -0,0 +1,115 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	monitoringv1alpha1 "github.com/marieroque/best-prometheus-operator-in-the-world/api/monitoring/v1alpha1"
+)
+
+// Default timings of the probes of the Prometheus container. The startup
+// probe allows 15 minutes for the WAL replay before the liveness probe kicks
+// in.
+var (
+	defaultLivenessProbe = corev1.Probe{
+		PeriodSeconds:    5,
+		TimeoutSeconds:   3,
+		FailureThreshold: 6,
+	}
+	defaultReadinessProbe = corev1.Probe{
+		PeriodSeconds:    5,
+		TimeoutSeconds:   3,
+		FailureThreshold: 3,
+	}
+	defaultStartupProbe = corev1.Probe{
+		PeriodSeconds:    15,
+		TimeoutSeconds:   3,
+		FailureThreshold: 60,
+	}
+)
+
+// addPrometheusProbes sets the liveness, readiness and startup probes of the
+// Prometheus container
+func addPrometheusProbes(cr *monitoringv1alpha1.Prometheus, container *corev1.Container) {
+	probes := cr.Spec.Probes
+	if probes == nil {
+		probes = &monitoringv1alpha1.ProbesSpec{}
+	}
+	container.LivenessProbe = prometheusProbe(cr, "/-/healthy", defaultLivenessProbe, probes.Liveness)
+	container.ReadinessProbe = prometheusProbe(cr, "/-/ready", defaultReadinessProbe, probes.Readiness)
+	container.StartupProbe = prometheusProbe(cr, "/-/ready", defaultStartupProbe, probes.Startup)
+	if webBasicAuth(cr) {
+		container.Env = append(container.Env, webPasswordEnv(cr)...)
+	}
+}
+
+// prometheusProbe returns a probe checking an endpoint of Prometheus with the
+// default timings overridden by the spec. Every timing is set explicitly for
+// the drift detection to ignore the values defaulted by the API server.
+func prometheusProbe(cr *monitoringv1alpha1.Prometheus, path string, defaults corev1.Probe, cfg *monitoringv1alpha1.ProbeConfig) *corev1.Probe {
+	probe := defaults
+	probe.SuccessThreshold = 1
+	probe.ProbeHandler = probeHandler(cr, path)
+	if cfg == nil {
+		return &probe
+	}
+	if cfg.InitialDelaySeconds != nil {
+		probe.InitialDelaySeconds = *cfg.InitialDelaySeconds
+	}
+	if cfg.PeriodSeconds != nil {
+		probe.PeriodSeconds = *cfg.PeriodSeconds
+	}
+	if cfg.TimeoutSeconds != nil {
+		probe.TimeoutSeconds = *cfg.TimeoutSeconds
+	}
+	if cfg.FailureThreshold != nil {
+		probe.FailureThreshold = *cfg.FailureThreshold
+	}
+	return &probe
+}
+
+// probeHandler returns the handler checking an endpoint of Prometheus, under
+// its route prefix and with the scheme of the web endpoint. The kubelet can't
+// authenticate, so the endpoint is requested from the container with the
+// password of the operator user when basic authentication is required.
+func probeHandler(cr *monitoringv1alpha1.Prometheus, path string) corev1.ProbeHandler {
+	if !webBasicAuth(cr) {
+		scheme := corev1.URISchemeHTTP
+		if webScheme(cr) == "https" {
+			scheme = corev1.URISchemeHTTPS
+		}
+		return corev1.ProbeHandler{
+			HTTPGet: &corev1.HTTPGetAction{
+				Path:   webPath(cr, path),
+				Port:   intstr.FromString("web"),
+				Scheme: scheme,
+			},
+		}
+	}
+
+	url := fmt.Sprintf("%s://%s:${WEB_PASSWORD}@127.0.0.1:%d%s", webScheme(cr), operatorUser, webPort, webPath(cr, path))
+	script := fmt.Sprintf(`if [ -x "$(command -v curl)" ]; then exec curl --fail --silent --insecure --output /dev/null "%[1]s"; `+
+		`elif [ -x "$(command -v wget)" ]; then exec wget -q -O /dev/null --no-check-certificate "%[1]s"; `+
+		`else exit 1; fi`, url)
+	return corev1.ProbeHandler{
+		Exec: &corev1.ExecAction{Command: []string{"sh", "-c", script}},
+	}
+}
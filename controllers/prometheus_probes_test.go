@@ -0,0 +1,107 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+
+	monitoringv1alpha1 "github.com/marieroque/best-prometheus-operator-in-the-world/api/monitoring/v1alpha1"
+)
+
+func TestPrometheusProbes(t *testing.T) {
+	cr := newTestPrometheus("probes")
+	template, err := newTestReconciler(t).podTemplateForPrometheus(cr, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	container := containerNamed(t, &template.Spec, "prometheus")
+	for _, tc := range []struct {
+		name     string
+		probe    *corev1.Probe
+		path     string
+		defaults corev1.Probe
+	}{
+		{"liveness", container.LivenessProbe, "/-/healthy", defaultLivenessProbe},
+		{"readiness", container.ReadinessProbe, "/-/ready", defaultReadinessProbe},
+		{"startup", container.StartupProbe, "/-/ready", defaultStartupProbe},
+	} {
+		if tc.probe == nil || tc.probe.HTTPGet == nil {
+			t.Fatalf("got %s probe %+v, want an HTTP probe", tc.name, tc.probe)
+		}
+		if tc.probe.HTTPGet.Path != tc.path || tc.probe.HTTPGet.Port.StrVal != "web" || tc.probe.HTTPGet.Scheme != corev1.URISchemeHTTP {
+			t.Errorf("got %s probe handler %+v, want http on web%s", tc.name, tc.probe.HTTPGet, tc.path)
+		}
+		if tc.probe.PeriodSeconds != tc.defaults.PeriodSeconds || tc.probe.TimeoutSeconds != tc.defaults.TimeoutSeconds ||
+			tc.probe.FailureThreshold != tc.defaults.FailureThreshold || tc.probe.SuccessThreshold != 1 {
+			t.Errorf("got %s probe %+v, want the default timings", tc.name, tc.probe)
+		}
+	}
+}
+
+func TestPrometheusProbeOverrides(t *testing.T) {
+	cr := newTestPrometheus("probes")
+	delay, failures := int32(10), int32(120)
+	cr.Spec.Probes = &monitoringv1alpha1.ProbesSpec{
+		Startup: &monitoringv1alpha1.ProbeConfig{
+			InitialDelaySeconds: &delay,
+			FailureThreshold:    &failures,
+		},
+	}
+	cr.Spec.Ingress = &monitoringv1alpha1.IngressSpec{Host: "example.com", Path: stringPtr("/prometheus/")}
+	cr.Spec.Web = &monitoringv1alpha1.WebSpec{TLS: &monitoringv1alpha1.WebTLSConfig{}}
+
+	container := &corev1.Container{}
+	addPrometheusProbes(cr, container)
+	startup := container.StartupProbe
+	if startup.InitialDelaySeconds != 10 || startup.FailureThreshold != 120 || startup.PeriodSeconds != defaultStartupProbe.PeriodSeconds {
+		t.Errorf("got startup probe %+v, want the overridden timings and the default period", startup)
+	}
+	if container.LivenessProbe.FailureThreshold != defaultLivenessProbe.FailureThreshold {
+		t.Errorf("got liveness probe %+v, want the default timings", container.LivenessProbe)
+	}
+	if get := container.ReadinessProbe.HTTPGet; get.Path != "/prometheus/-/ready" || get.Scheme != corev1.URISchemeHTTPS {
+		t.Errorf("got readiness probe handler %+v, want https under the route prefix", get)
+	}
+	if len(container.Env) != 0 {
+		t.Errorf("got env %+v, want none without basic authentication", container.Env)
+	}
+}
+
+func TestPrometheusProbesBasicAuth(t *testing.T) {
+	cr := newTestPrometheus("probes")
+	cr.Spec.Web = &monitoringv1alpha1.WebSpec{
+		TLS:            &monitoringv1alpha1.WebTLSConfig{},
+		BasicAuthUsers: &corev1.LocalObjectReference{Name: "users"},
+	}
+
+	container := &corev1.Container{}
+	addPrometheusProbes(cr, container)
+	exec := container.LivenessProbe.Exec
+	if exec == nil || len(exec.Command) != 3 {
+		t.Fatalf("got liveness probe %+v, want a command", container.LivenessProbe)
+	}
+	if url := "https://" + operatorUser + ":${WEB_PASSWORD}@127.0.0.1:9090/-/healthy"; !strings.Contains(exec.Command[2], url) {
+		t.Errorf("got command %q, want a request to %s", exec.Command[2], url)
+	}
+	if len(container.Env) != 1 || container.Env[0].Name != "WEB_PASSWORD" ||
+		container.Env[0].ValueFrom.SecretKeyRef.Name != webConfigSecretName(cr) {
+		t.Errorf("got env %+v, want the password of the operator user", container.Env)
+	}
+}
@@ -0,0 +1,311 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	ctrllog "sigs.k8s.io/controller-runtime/pkg/log"
+
+	monitoringv1alpha1 "github.com/marieroque/best-prometheus-operator-in-the-world/api/monitoring/v1alpha1"
+)
+
+// The operator can only grant the permissions it holds itself, so it needs
+// read access to everything Prometheus discovers through kubernetes_sd_configs.
+//+kubebuilder:rbac:groups=core,resources=serviceaccounts,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=rbac.authorization.k8s.io,resources=clusterroles;clusterrolebindings,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=core,resources=nodes;nodes/metrics;services;endpoints,verbs=get;list;watch
+//+kubebuilder:rbac:groups=networking.k8s.io,resources=ingresses,verbs=get;list;watch
+//+kubebuilder:rbac:urls=/metrics,verbs=get
+
+// serviceAccountNameForPrometheus returns the name of the service account the
+// Prometheus pods run with.
+func serviceAccountNameForPrometheus(cr *monitoringv1alpha1.Prometheus) string {
+	return "prometheus-" + cr.Name
+}
+
+// clusterRoleNameForPrometheus returns the name shared by the ClusterRole and
+// ClusterRoleBinding of a Prometheus. Cluster-scoped names must be unique
+// across namespaces, hence the namespace in the name.
+func clusterRoleNameForPrometheus(cr *monitoringv1alpha1.Prometheus) string {
+	return "prometheus-" + cr.Namespace + "-" + cr.Name
+}
+
+// labelsForPrometheusClusterObject returns the labels of the cluster-scoped
+// objects created for the given prometheus CR.
+func labelsForPrometheusClusterObject(cr *monitoringv1alpha1.Prometheus) map[string]string {
+	ls := labelsForPrometheus(cr.Name)
+	ls["prometheus_cr_namespace"] = cr.Namespace
+	return ls
+}
+
+// policyRulesForPrometheus returns the permissions needed by Prometheus for
+// Kubernetes service discovery. Nodes and non-resource URLs only exist at the
+// cluster scope and are left out of namespaced rules.
+func policyRulesForPrometheus(namespaced bool) []rbacv1.PolicyRule {
+	if namespaced {
+		return []rbacv1.PolicyRule{{
+			APIGroups: []string{""},
+			Resources: []string{"services", "endpoints", "pods"},
+			Verbs:     []string{"get", "list", "watch"},
+		}, {
+			APIGroups: []string{"networking.k8s.io"},
+			Resources: []string{"ingresses"},
+			Verbs:     []string{"get", "list", "watch"},
+		}}
+	}
+	return []rbacv1.PolicyRule{{
+		APIGroups: []string{""},
+		Resources: []string{"nodes", "nodes/metrics", "services", "endpoints", "pods"},
+		Verbs:     []string{"get", "list", "watch"},
+	}, {
+		APIGroups: []string{"networking.k8s.io"},
+		Resources: []string{"ingresses"},
+		Verbs:     []string{"get", "list", "watch"},
+	}, {
+		NonResourceURLs: []string{"/metrics"},
+		Verbs:           []string{"get"},
+	}}
+}
+
+// serviceAccountForPrometheus returns the service account the Prometheus pods
+// run with.
+func serviceAccountForPrometheus(cr *monitoringv1alpha1.Prometheus) *corev1.ServiceAccount {
+	return &corev1.ServiceAccount{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      serviceAccountNameForPrometheus(cr),
+			Namespace: cr.Namespace,
+			Labels:    labelsForPrometheus(cr.Name),
+		},
+	}
+}
+
+// clusterRoleForPrometheus returns the ClusterRole granting the permissions
+// of Kubernetes service discovery to a Prometheus.
+func clusterRoleForPrometheus(cr *monitoringv1alpha1.Prometheus) *rbacv1.ClusterRole {
+	return &rbacv1.ClusterRole{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   clusterRoleNameForPrometheus(cr),
+			Labels: labelsForPrometheusClusterObject(cr),
+		},
+		Rules: policyRulesForPrometheus(false),
+	}
+}
+
+// clusterRoleBindingForPrometheus returns the ClusterRoleBinding of the
+// ClusterRole of a Prometheus to its service account.
+func clusterRoleBindingForPrometheus(cr *monitoringv1alpha1.Prometheus) *rbacv1.ClusterRoleBinding {
+	return &rbacv1.ClusterRoleBinding{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   clusterRoleNameForPrometheus(cr),
+			Labels: labelsForPrometheusClusterObject(cr),
+		},
+		RoleRef: rbacv1.RoleRef{
+			APIGroup: rbacv1.GroupName,
+			Kind:     "ClusterRole",
+			Name:     clusterRoleNameForPrometheus(cr),
+		},
+		Subjects: []rbacv1.Subject{{
+			Kind:      rbacv1.ServiceAccountKind,
+			Name:      serviceAccountNameForPrometheus(cr),
+			Namespace: cr.Namespace,
+		}},
+	}
+}
+
+// roleForPrometheus returns the Role granting the permissions of Kubernetes
+// service discovery in the namespace of a Prometheus.
+func roleForPrometheus(cr *monitoringv1alpha1.Prometheus) *rbacv1.Role {
+	return &rbacv1.Role{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      serviceAccountNameForPrometheus(cr),
+			Namespace: cr.Namespace,
+			Labels:    labelsForPrometheus(cr.Name),
+		},
+		Rules: policyRulesForPrometheus(true),
+	}
+}
+
+// roleBindingForPrometheus returns the RoleBinding of the Role of a
+// Prometheus to its service account.
+func roleBindingForPrometheus(cr *monitoringv1alpha1.Prometheus) *rbacv1.RoleBinding {
+	return &rbacv1.RoleBinding{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      serviceAccountNameForPrometheus(cr),
+			Namespace: cr.Namespace,
+			Labels:    labelsForPrometheus(cr.Name),
+		},
+		RoleRef: rbacv1.RoleRef{
+			APIGroup: rbacv1.GroupName,
+			Kind:     "Role",
+			Name:     serviceAccountNameForPrometheus(cr),
+		},
+		Subjects: []rbacv1.Subject{{
+			Kind:      rbacv1.ServiceAccountKind,
+			Name:      serviceAccountNameForPrometheus(cr),
+			Namespace: cr.Namespace,
+		}},
+	}
+}
+
+// reconcileRBAC makes sure the service account of the Prometheus pods exists
+// and is bound to the permissions needed by Kubernetes service discovery.
+func (r *PrometheusReconciler) reconcileRBAC(ctx context.Context, cr *monitoringv1alpha1.Prometheus) error {
+	log := ctrllog.FromContext(ctx)
+
+	desiredSA := serviceAccountForPrometheus(cr)
+	sa := &corev1.ServiceAccount{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      desiredSA.Name,
+			Namespace: desiredSA.Namespace,
+		},
+	}
+	op, err := controllerutil.CreateOrUpdate(ctx, r.Client, sa, func() error {
+		sa.Labels = desiredSA.Labels
+		// Set Prometheus instance as the owner and controller
+		return ctrl.SetControllerReference(cr, sa, r.Scheme)
+	})
+	if err != nil {
+		log.Error(err, "Failed to reconcile ServiceAccount", "ServiceAccount.Namespace", sa.Namespace, "ServiceAccount.Name", sa.Name)
+		return err
+	}
+	log.V(1).Info("ServiceAccount reconciled", "ServiceAccount.Name", sa.Name, "operation", op)
+
+	if r.NamespaceScoped {
+		return r.reconcileNamespacedRBAC(ctx, cr)
+	}
+
+	// Cluster-scoped objects can't be owned by a namespaced Prometheus, they
+	// are removed by the finalizer instead.
+	desiredRole := clusterRoleForPrometheus(cr)
+	role := &rbacv1.ClusterRole{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: desiredRole.Name,
+		},
+	}
+	op, err = controllerutil.CreateOrUpdate(ctx, r.Client, role, func() error {
+		role.Labels = desiredRole.Labels
+		role.Rules = desiredRole.Rules
+		return nil
+	})
+	if err != nil {
+		log.Error(err, "Failed to reconcile ClusterRole", "ClusterRole.Name", role.Name)
+		return err
+	}
+	log.V(1).Info("ClusterRole reconciled", "ClusterRole.Name", role.Name, "operation", op)
+
+	desiredBinding := clusterRoleBindingForPrometheus(cr)
+	binding := &rbacv1.ClusterRoleBinding{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: desiredBinding.Name,
+		},
+	}
+	op, err = controllerutil.CreateOrUpdate(ctx, r.Client, binding, func() error {
+		binding.Labels = desiredBinding.Labels
+		binding.RoleRef = desiredBinding.RoleRef
+		binding.Subjects = desiredBinding.Subjects
+		return nil
+	})
+	if err != nil {
+		log.Error(err, "Failed to reconcile ClusterRoleBinding", "ClusterRoleBinding.Name", binding.Name)
+		return err
+	}
+	log.V(1).Info("ClusterRoleBinding reconciled", "ClusterRoleBinding.Name", binding.Name, "operation", op)
+
+	return nil
+}
+
+// reconcileNamespacedRBAC binds the service account of the Prometheus pods to
+// a Role limited to the namespace of the Prometheus. It is used when the
+// operator isn't allowed to manage cluster-scoped objects.
+func (r *PrometheusReconciler) reconcileNamespacedRBAC(ctx context.Context, cr *monitoringv1alpha1.Prometheus) error {
+	log := ctrllog.FromContext(ctx)
+
+	desiredRole := roleForPrometheus(cr)
+	role := &rbacv1.Role{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      desiredRole.Name,
+			Namespace: desiredRole.Namespace,
+		},
+	}
+	op, err := controllerutil.CreateOrUpdate(ctx, r.Client, role, func() error {
+		role.Labels = desiredRole.Labels
+		role.Rules = desiredRole.Rules
+		// Set Prometheus instance as the owner and controller
+		return ctrl.SetControllerReference(cr, role, r.Scheme)
+	})
+	if err != nil {
+		log.Error(err, "Failed to reconcile Role", "Role.Namespace", role.Namespace, "Role.Name", role.Name)
+		return err
+	}
+	log.V(1).Info("Role reconciled", "Role.Name", role.Name, "operation", op)
+
+	desiredBinding := roleBindingForPrometheus(cr)
+	binding := &rbacv1.RoleBinding{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      desiredBinding.Name,
+			Namespace: desiredBinding.Namespace,
+		},
+	}
+	op, err = controllerutil.CreateOrUpdate(ctx, r.Client, binding, func() error {
+		binding.Labels = desiredBinding.Labels
+		binding.RoleRef = desiredBinding.RoleRef
+		binding.Subjects = desiredBinding.Subjects
+		// Set Prometheus instance as the owner and controller
+		return ctrl.SetControllerReference(cr, binding, r.Scheme)
+	})
+	if err != nil {
+		log.Error(err, "Failed to reconcile RoleBinding", "RoleBinding.Namespace", binding.Namespace, "RoleBinding.Name", binding.Name)
+		return err
+	}
+	log.V(1).Info("RoleBinding reconciled", "RoleBinding.Name", binding.Name, "operation", op)
+
+	return nil
+}
+
+// finalizePrometheus removes the cluster-scoped objects of a Prometheus that
+// garbage collection can't reach through owner references.
+func (r *PrometheusReconciler) finalizePrometheus(ctx context.Context, cr *monitoringv1alpha1.Prometheus) error {
+	log := ctrllog.FromContext(ctx)
+
+	// Namespaced RBAC is owned by the Prometheus and garbage collected
+	if r.NamespaceScoped {
+		return nil
+	}
+
+	binding := &rbacv1.ClusterRoleBinding{ObjectMeta: metav1.ObjectMeta{Name: clusterRoleNameForPrometheus(cr)}}
+	if err := r.Delete(ctx, binding); err != nil && !errors.IsNotFound(err) {
+		log.Error(err, "Failed to delete ClusterRoleBinding", "ClusterRoleBinding.Name", binding.Name)
+		return err
+	}
+
+	role := &rbacv1.ClusterRole{ObjectMeta: metav1.ObjectMeta{Name: clusterRoleNameForPrometheus(cr)}}
+	if err := r.Delete(ctx, role); err != nil && !errors.IsNotFound(err) {
+		log.Error(err, "Failed to delete ClusterRole", "ClusterRole.Name", role.Name)
+		return err
+	}
+
+	log.Info("Successfully finalized Prometheus")
+	return nil
+}
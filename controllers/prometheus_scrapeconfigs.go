@@ -0,0 +1,185 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	ctrllog "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	monitoringv1alpha1 "github.com/marieroque/best-prometheus-operator-in-the-world/api/monitoring/v1alpha1"
+)
+
+// loadScrapeConfigs returns the scrape configs of the ScrapeConfig objects
+// selected by a Prometheus, with their job names prefixed, along with the
+// selection reported in the status. Objects that can't be added to the
+// configuration are rejected instead of failing the reconciliation, so that
+// a single team can't break the configuration of the others.
+func (r *PrometheusReconciler) loadScrapeConfigs(ctx context.Context, cr *monitoringv1alpha1.Prometheus, inputs *configInputs) ([]*monitoringv1alpha1.ScrapeConfigSpec, *monitoringv1alpha1.SelectedResources, error) {
+	log := ctrllog.FromContext(ctx)
+
+	if cr.Spec.ScrapeConfigSelector == nil {
+		return nil, nil, nil
+	}
+	selector, err := metav1.LabelSelectorAsSelector(cr.Spec.ScrapeConfigSelector)
+	if err != nil {
+		log.Error(err, "Invalid ScrapeConfig selector", "Prometheus.Namespace", cr.Namespace, "Prometheus.Name", cr.Name)
+		return nil, nil, err
+	}
+	namespaces, err := r.selectedNamespaces(ctx, cr, cr.Spec.ScrapeConfigNamespaceSelector)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var items []monitoringv1alpha1.ScrapeConfig
+	for _, ns := range namespaces {
+		list := &monitoringv1alpha1.ScrapeConfigList{}
+		err := r.List(ctx, list, client.InNamespace(ns), client.MatchingLabelsSelector{Selector: selector})
+		if err != nil {
+			log.Error(err, "Failed to list ScrapeConfigs", "ScrapeConfig.Namespace", ns)
+			return nil, nil, err
+		}
+		items = append(items, list.Items...)
+	}
+	sort.Slice(items, func(i, j int) bool {
+		if items[i].Namespace != items[j].Namespace {
+			return items[i].Namespace < items[j].Namespace
+		}
+		return items[i].Name < items[j].Name
+	})
+
+	jobNames := inputs.jobNames(cr)
+
+	var scrapeConfigs []*monitoringv1alpha1.ScrapeConfigSpec
+	selection := &monitoringv1alpha1.SelectedResources{}
+	for i := range items {
+		sc := &items[i]
+		if r.namespaceDenied(sc.Namespace) {
+			continue
+		}
+		reject := func(reason string) {
+			selection.Rejected = append(selection.Rejected, monitoringv1alpha1.RejectedResource{
+				Namespace: sc.Namespace,
+				Name:      sc.Name,
+				Reason:    reason,
+			})
+		}
+		if err := cr.ValidateScrapeConfig(sc); err != nil {
+			reject(err.Error())
+			continue
+		}
+		jobName := sc.JobName()
+		if jobNames[jobName] {
+			reject(fmt.Sprintf("job name %q is already used", jobName))
+			continue
+		}
+		jobNames[jobName] = true
+
+		spec := sc.Spec.DeepCopy()
+		spec.JobName = &jobName
+		scrapeConfigs = append(scrapeConfigs, spec)
+		selection.Selected = append(selection.Selected, sc.Namespace+"/"+sc.Name)
+	}
+	return scrapeConfigs, selection, nil
+}
+
+// selectedNamespaces returns the namespaces matched by a namespace selector of
+// a Prometheus. An empty namespace stands for all of them.
+func (r *PrometheusReconciler) selectedNamespaces(ctx context.Context, cr *monitoringv1alpha1.Prometheus, namespaceSelector *metav1.LabelSelector) ([]string, error) {
+	log := ctrllog.FromContext(ctx)
+
+	// Namespaces are cluster-scoped, their labels can't be read when the
+	// operator only watches some namespaces
+	if r.NamespaceScoped || namespaceSelector == nil {
+		return []string{cr.Namespace}, nil
+	}
+	selector, err := metav1.LabelSelectorAsSelector(namespaceSelector)
+	if err != nil {
+		log.Error(err, "Invalid namespace selector", "Prometheus.Namespace", cr.Namespace, "Prometheus.Name", cr.Name)
+		return nil, err
+	}
+	if selector.Empty() {
+		return []string{metav1.NamespaceAll}, nil
+	}
+
+	list := &corev1.NamespaceList{}
+	err = r.List(ctx, list, client.MatchingLabelsSelector{Selector: selector})
+	if err != nil {
+		log.Error(err, "Failed to list Namespaces")
+		return nil, err
+	}
+	namespaces := make([]string, 0, len(list.Items))
+	for _, ns := range list.Items {
+		namespaces = append(namespaces, ns.Name)
+	}
+	return namespaces, nil
+}
+
+// namespaceDenied reports whether the resources of a namespace are ignored
+// by the operator.
+func (r *PrometheusReconciler) namespaceDenied(namespace string) bool {
+	for _, ns := range r.DenyNamespaces {
+		if ns == namespace {
+			return true
+		}
+	}
+	return false
+}
+
+// prometheusesSelectingScrapeConfigs is a mapping function enqueuing the
+// Prometheuses that select ScrapeConfigs. They are all enqueued since an
+// object leaving a selection must be removed from the configuration too.
+func (r *PrometheusReconciler) prometheusesSelectingScrapeConfigs(obj client.Object) []reconcile.Request {
+	return r.prometheusesMatching(func(cr *monitoringv1alpha1.Prometheus) bool {
+		return cr.Spec.ScrapeConfigSelector != nil
+	})
+}
+
+// prometheusesSelectingNamespaces is a mapping function enqueuing the
+// Prometheuses whose selections depend on the labels of the namespaces.
+func (r *PrometheusReconciler) prometheusesSelectingNamespaces(obj client.Object) []reconcile.Request {
+	return r.prometheusesMatching(func(cr *monitoringv1alpha1.Prometheus) bool {
+		return (cr.Spec.ScrapeConfigSelector != nil && cr.Spec.ScrapeConfigNamespaceSelector != nil) ||
+			(cr.Spec.ProbeSelector != nil && cr.Spec.ProbeNamespaceSelector != nil)
+	})
+}
+
+// prometheusesMatching returns the requests of the Prometheuses of every
+// namespace that match the given function.
+func (r *PrometheusReconciler) prometheusesMatching(match func(cr *monitoringv1alpha1.Prometheus) bool) []reconcile.Request {
+	list := &monitoringv1alpha1.PrometheusList{}
+	err := r.List(context.Background(), list)
+	if err != nil {
+		return nil
+	}
+	var requests []reconcile.Request
+	for _, cr := range list.Items {
+		if match(cr) {
+			requests = append(requests, reconcile.Request{
+				NamespacedName: types.NamespacedName{Name: cr.Name, Namespace: cr.Namespace},
+			})
+		}
+	}
+	return requests
+}
@@ -0,0 +1,123 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	monitoringv1alpha1 "github.com/marieroque/best-prometheus-operator-in-the-world/api/monitoring/v1alpha1"
+)
+
+// newTestScrapeConfig returns a ScrapeConfig of a static target with the
+// given labels.
+func newTestScrapeConfig(namespace, name string, labels map[string]string) *monitoringv1alpha1.ScrapeConfig {
+	jobName := "app"
+	return &monitoringv1alpha1.ScrapeConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace, Labels: labels},
+		Spec: monitoringv1alpha1.ScrapeConfigSpec{
+			JobName:       &jobName,
+			StaticConfigs: []*monitoringv1alpha1.StaticConfig{{Targets: []string{"app:8080"}}},
+		},
+	}
+}
+
+func TestLoadScrapeConfigs(t *testing.T) {
+	cr := newTestPrometheus("selection")
+	cr.Spec.ScrapeConfigSelector = &metav1.LabelSelector{MatchLabels: map[string]string{"team": "a"}}
+	cr.Spec.ScrapeConfigNamespaceSelector = &metav1.LabelSelector{MatchLabels: map[string]string{"env": "prod"}}
+	duplicate := "prod/duplicate/app"
+	cr.Spec.ScrapeConfigs = []*monitoringv1alpha1.ScrapeConfigSpec{{JobName: &duplicate}}
+
+	team := map[string]string{"team": "a"}
+	fileSD := newTestScrapeConfig("prod", "file", team)
+	fileSD.Spec.FileSDConfigs = []*monitoringv1alpha1.FileSDConfig{{ConfigMap: "targets"}}
+	objs := []client.Object{
+		cr,
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "prod", Labels: map[string]string{"env": "prod"}}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "dev", Labels: map[string]string{"env": "dev"}}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "denied", Labels: map[string]string{"env": "prod"}}},
+		newTestScrapeConfig("prod", "selected", team),
+		newTestScrapeConfig("prod", "other-team", map[string]string{"team": "b"}),
+		newTestScrapeConfig("prod", "duplicate", team),
+		newTestScrapeConfig("dev", "other-namespace", team),
+		newTestScrapeConfig("denied", "denied", team),
+		fileSD,
+	}
+
+	r := newTestReconciler(t, objs...)
+	r.DenyNamespaces = []string{"denied"}
+	scrapeConfigs, selection, err := r.loadScrapeConfigs(context.Background(), cr, &configInputs{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(scrapeConfigs) != 1 || *scrapeConfigs[0].JobName != "prod/selected/app" {
+		t.Errorf("got scrape configs %+v, want the one of prod/selected", scrapeConfigs)
+	}
+	if want := []string{"prod/selected"}; !reflect.DeepEqual(selection.Selected, want) {
+		t.Errorf("got selected %v, want %v", selection.Selected, want)
+	}
+	var rejected []string
+	for _, res := range selection.Rejected {
+		rejected = append(rejected, res.Namespace+"/"+res.Name)
+	}
+	if want := []string{"prod/duplicate", "prod/file"}; !reflect.DeepEqual(rejected, want) {
+		t.Errorf("got rejected %v, want %v", rejected, want)
+	}
+
+	// A namespace-scoped operator only selects the namespace of the
+	// Prometheus
+	r.NamespaceScoped = true
+	_, selection, err = r.loadScrapeConfigs(context.Background(), cr, &configInputs{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(selection.Selected) != 0 || len(selection.Rejected) != 0 {
+		t.Errorf("got selection %+v, want none outside of %s", selection, cr.Namespace)
+	}
+}
+
+func TestSelectedNamespaces(t *testing.T) {
+	cr := newTestPrometheus("selection")
+	r := newTestReconciler(t,
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "prod", Labels: map[string]string{"env": "prod"}}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "dev", Labels: map[string]string{"env": "dev"}}},
+	)
+
+	for _, tc := range []struct {
+		name     string
+		selector *metav1.LabelSelector
+		want     []string
+	}{
+		{"no selector", nil, []string{cr.Namespace}},
+		{"empty selector", &metav1.LabelSelector{}, []string{metav1.NamespaceAll}},
+		{"labels", &metav1.LabelSelector{MatchLabels: map[string]string{"env": "prod"}}, []string{"prod"}},
+	} {
+		got, err := r.selectedNamespaces(context.Background(), cr, tc.selector)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%s: got namespaces %q, want %q", tc.name, got, tc.want)
+		}
+	}
+}
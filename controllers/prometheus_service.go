@@ -0,0 +1,133 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	ctrllog "sigs.k8s.io/controller-runtime/pkg/log"
+
+	monitoringv1alpha1 "github.com/marieroque/best-prometheus-operator-in-the-world/api/monitoring/v1alpha1"
+)
+
+// serviceForPrometheus returns the headless Service selecting the pods of
+// every shard of a Prometheus. It also exposes the gRPC port of the Thanos
+// sidecars so that Thanos Query can discover them through DNS.
+func serviceForPrometheus(cr *monitoringv1alpha1.Prometheus) *corev1.Service {
+	ports := []corev1.ServicePort{{
+		Name:       "web",
+		Port:       webPort,
+		TargetPort: intstr.FromString("web"),
+	}}
+	if cr.Spec.Thanos != nil {
+		ports = append(ports, corev1.ServicePort{
+			Name:       "grpc",
+			Port:       thanosGRPCPort,
+			TargetPort: intstr.FromString("grpc"),
+		})
+	}
+
+	return &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      cr.Name,
+			Namespace: cr.Namespace,
+			Labels:    labelsForPrometheus(cr.Name),
+		},
+		Spec: corev1.ServiceSpec{
+			ClusterIP: corev1.ClusterIPNone,
+			Selector:  labelsForPrometheus(cr.Name),
+			Ports:     ports,
+		},
+	}
+}
+
+// reconcileService makes sure the Service of a Prometheus exists and matches
+// the spec.
+func (r *PrometheusReconciler) reconcileService(ctx context.Context, cr *monitoringv1alpha1.Prometheus) error {
+	log := ctrllog.FromContext(ctx)
+
+	svc := serviceForPrometheus(cr)
+	found := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      svc.Name,
+			Namespace: svc.Namespace,
+		},
+	}
+	op, err := controllerutil.CreateOrUpdate(ctx, r.Client, found, func() error {
+		// The cluster IP is immutable, only set it on creation
+		if found.CreationTimestamp.IsZero() {
+			found.Spec.ClusterIP = svc.Spec.ClusterIP
+		}
+		found.Labels = svc.Labels
+		found.Spec.Selector = svc.Spec.Selector
+		found.Spec.Ports = svc.Spec.Ports
+		// Set Prometheus instance as the owner and controller
+		return ctrl.SetControllerReference(cr, found, r.Scheme)
+	})
+	if err != nil {
+		log.Error(err, "Failed to reconcile Service", "Service.Namespace", svc.Namespace, "Service.Name", svc.Name)
+		return err
+	}
+	if op != controllerutil.OperationResultNone {
+		log.Info("Service reconciled", "Service.Namespace", svc.Namespace, "Service.Name", svc.Name, "operation", op)
+	}
+	return nil
+}
+
+// updateThanosStatus reports whether the Thanos sidecars of the Prometheus
+// pods are ready. The condition is removed when Thanos is disabled.
+func (r *PrometheusReconciler) updateThanosStatus(ctx context.Context, cr *monitoringv1alpha1.Prometheus) error {
+	log := ctrllog.FromContext(ctx)
+
+	if cr.Spec.Thanos == nil {
+		return r.removeCondition(ctx, cr, monitoringv1alpha1.ConditionTypeThanosSidecarReady)
+	}
+
+	podList := &corev1.PodList{}
+	err := r.List(ctx, podList, client.InNamespace(cr.Namespace), client.MatchingLabels(labelsForPrometheus(cr.Name)))
+	if err != nil {
+		log.Error(err, "Failed to list pods", "Prometheus.Namespace", cr.Namespace, "Prometheus.Name", cr.Name)
+		return err
+	}
+	ready := 0
+	for _, pod := range podList.Items {
+		for _, cs := range pod.Status.ContainerStatuses {
+			if cs.Name == "thanos-sidecar" && cs.Ready {
+				ready++
+			}
+		}
+	}
+
+	condition := metav1.Condition{
+		Type:    monitoringv1alpha1.ConditionTypeThanosSidecarReady,
+		Status:  metav1.ConditionTrue,
+		Reason:  "SidecarsReady",
+		Message: fmt.Sprintf("%d/%d Thanos sidecars are ready", ready, len(podList.Items)),
+	}
+	if len(podList.Items) == 0 || ready < len(podList.Items) {
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = "SidecarsNotReady"
+	}
+	return r.setCondition(ctx, cr, condition)
+}
@@ -0,0 +1,466 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net"
+	"time"
+
+	"github.com/ghodss/yaml"
+	"golang.org/x/crypto/bcrypt"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	ctrllog "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	monitoringv1alpha1 "github.com/marieroque/best-prometheus-operator-in-the-world/api/monitoring/v1alpha1"
+)
+
+const (
+	// webConfigDir is where the web configuration file is mounted.
+	webConfigDir  = "/etc/prometheus/web_config/"
+	webConfigFile = "web-config.yaml"
+	// webTLSDir is where the certificates of the web endpoint are mounted.
+	webTLSDir = "/etc/prometheus/web_tls/"
+	// operatorUser is the basic auth user of the sidecars calling Prometheus.
+	operatorUser = "prometheus-operator"
+)
+
+// Keys of the web config Secret generated for a Prometheus.
+const (
+	webConfigPasswordKey     = "operator-password"
+	webConfigPasswordHashKey = "operator-password-hash"
+	thanosHTTPClientKey      = "thanos-http-client.yaml"
+)
+
+// selfSignedValidity is how long the generated certificates are valid, they
+// are renewed during the last month.
+const selfSignedValidity = 365 * 24 * time.Hour
+
+// webConfig is the content of the web configuration file of Prometheus.
+type webConfig struct {
+	TLSServerConfig *webTLSServerConfig `json:"tls_server_config,omitempty"`
+	BasicAuthUsers  map[string]string   `json:"basic_auth_users,omitempty"`
+}
+
+type webTLSServerConfig struct {
+	CertFile       string `json:"cert_file"`
+	KeyFile        string `json:"key_file"`
+	ClientCAFile   string `json:"client_ca_file,omitempty"`
+	ClientAuthType string `json:"client_auth_type,omitempty"`
+}
+
+// webScheme returns the scheme the Prometheus web endpoint is served with
+func webScheme(cr *monitoringv1alpha1.Prometheus) string {
+	if cr.Spec.Web != nil && cr.Spec.Web.TLS != nil {
+		return "https"
+	}
+	return "http"
+}
+
+// webBasicAuth reports whether the Prometheus web endpoint requires basic
+// authentication
+func webBasicAuth(cr *monitoringv1alpha1.Prometheus) bool {
+	return cr.Spec.Web != nil && cr.Spec.Web.BasicAuthUsers != nil
+}
+
+// webSelfSigned reports whether the operator generates the certificate of the
+// Prometheus web endpoint
+func webSelfSigned(cr *monitoringv1alpha1.Prometheus) bool {
+	return cr.Spec.Web != nil && cr.Spec.Web.TLS != nil && cr.Spec.Web.TLS.Cert == nil
+}
+
+// webConfigSecretName returns the name of the Secret holding the web
+// configuration file of a Prometheus.
+func webConfigSecretName(cr *monitoringv1alpha1.Prometheus) string {
+	return cr.Name + "-web-config"
+}
+
+// webTLSSecretName returns the name of the Secret holding the self-signed
+// certificate of a Prometheus.
+func webTLSSecretName(cr *monitoringv1alpha1.Prometheus) string {
+	return cr.Name + "-web-tls"
+}
+
+// reconcileWeb makes sure the web configuration and the self-signed
+// certificate of a Prometheus match the spec, and removes them when the web
+// endpoint isn't secured anymore. An unusable Secret of basic auth users is
+// reported in the status and returned as an *invalidInputError, the web
+// configuration then keeps its last rendered users.
+func (r *PrometheusReconciler) reconcileWeb(ctx context.Context, cr *monitoringv1alpha1.Prometheus) error {
+	if !webSelfSigned(cr) {
+		if err := r.deleteSecret(ctx, cr, webTLSSecretName(cr)); err != nil {
+			return err
+		}
+	} else if err := r.reconcileSelfSignedCert(ctx, cr); err != nil {
+		return err
+	}
+
+	if cr.Spec.Web == nil {
+		if err := r.removeCondition(ctx, cr, monitoringv1alpha1.ConditionTypeWebConfigValid); err != nil {
+			return err
+		}
+		return r.deleteSecret(ctx, cr, webConfigSecretName(cr))
+	}
+	err := r.reconcileWebConfig(ctx, cr)
+	if invalid, ok := err.(*invalidInputError); ok {
+		if err := r.setCondition(ctx, cr, metav1.Condition{
+			Type:    invalid.conditionType,
+			Status:  metav1.ConditionFalse,
+			Reason:  invalid.reason,
+			Message: invalid.message,
+		}); err != nil {
+			return err
+		}
+		return invalid
+	} else if err != nil {
+		return err
+	}
+	if !webBasicAuth(cr) {
+		return r.removeCondition(ctx, cr, monitoringv1alpha1.ConditionTypeWebConfigValid)
+	}
+	return r.setCondition(ctx, cr, metav1.Condition{
+		Type:    monitoringv1alpha1.ConditionTypeWebConfigValid,
+		Status:  metav1.ConditionTrue,
+		Reason:  "Valid",
+		Message: "The basic auth users are valid",
+	})
+}
+
+// reconcileWebConfig renders the web configuration file of a Prometheus. The
+// password of the operator user is generated once and kept afterwards.
+func (r *PrometheusReconciler) reconcileWebConfig(ctx context.Context, cr *monitoringv1alpha1.Prometheus) error {
+	log := ctrllog.FromContext(ctx)
+
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      webConfigSecretName(cr),
+			Namespace: cr.Namespace,
+		},
+	}
+	err := r.Get(ctx, types.NamespacedName{Name: secret.Name, Namespace: secret.Namespace}, secret)
+	if err != nil && !errors.IsNotFound(err) {
+		log.Error(err, "Failed to get Secret", "Secret.Namespace", secret.Namespace, "Secret.Name", secret.Name)
+		return err
+	}
+	desired, err := r.webConfigSecretForPrometheus(ctx, cr, secret)
+	if err != nil {
+		return err
+	}
+
+	op, err := controllerutil.CreateOrUpdate(ctx, r.Client, secret, func() error {
+		secret.Labels = desired.Labels
+		secret.Data = desired.Data
+		// Set Prometheus instance as the owner and controller
+		return ctrl.SetControllerReference(cr, secret, r.Scheme)
+	})
+	if err != nil {
+		log.Error(err, "Failed to reconcile Secret", "Secret.Namespace", secret.Namespace, "Secret.Name", secret.Name)
+		return err
+	}
+	if op != controllerutil.OperationResultNone {
+		log.Info("Secret reconciled", "Secret.Namespace", secret.Namespace, "Secret.Name", secret.Name, "operation", op)
+	}
+	return nil
+}
+
+// webConfigSecretForPrometheus returns the Secret holding the web
+// configuration file of a Prometheus, keeping the password of the operator
+// user found in the live Secret. An unusable Secret of basic auth users is
+// returned as an *invalidInputError.
+func (r *PrometheusReconciler) webConfigSecretForPrometheus(ctx context.Context, cr *monitoringv1alpha1.Prometheus, found *corev1.Secret) (*corev1.Secret, error) {
+	log := ctrllog.FromContext(ctx)
+
+	var err error
+	cfg := webConfig{}
+	data := map[string][]byte{}
+	if tls := cr.Spec.Web.TLS; tls != nil {
+		cfg.TLSServerConfig = &webTLSServerConfig{
+			CertFile: webTLSDir + corev1.TLSCertKey,
+			KeyFile:  webTLSDir + corev1.TLSPrivateKeyKey,
+		}
+		if tls.ClientCA != nil {
+			cfg.TLSServerConfig.ClientCAFile = webTLSDir + "client-ca.crt"
+			cfg.TLSServerConfig.ClientAuthType = "VerifyClientCertIfGiven"
+		}
+		if tls.ClientAuthType != nil {
+			cfg.TLSServerConfig.ClientAuthType = *tls.ClientAuthType
+		}
+	}
+	if webBasicAuth(cr) {
+		name := cr.Spec.Web.BasicAuthUsers.Name
+		users := &corev1.Secret{}
+		err = r.Get(ctx, types.NamespacedName{Name: name, Namespace: cr.Namespace}, users)
+		if errors.IsNotFound(err) {
+			return nil, &invalidInputError{
+				conditionType: monitoringv1alpha1.ConditionTypeWebConfigValid,
+				reason:        "SecretNotFound",
+				message:       fmt.Sprintf("Secret %s not found", name),
+			}
+		} else if err != nil {
+			log.Error(err, "Failed to get basic auth users Secret", "Secret.Namespace", cr.Namespace, "Secret.Name", name)
+			return nil, err
+		}
+		if _, ok := users.Data[operatorUser]; ok {
+			return nil, &invalidInputError{
+				conditionType: monitoringv1alpha1.ConditionTypeWebConfigValid,
+				reason:        "ReservedUser",
+				message:       fmt.Sprintf("user %s of Secret %s is reserved for the sidecars", operatorUser, name),
+			}
+		}
+		cfg.BasicAuthUsers = make(map[string]string, len(users.Data)+1)
+		for user, hash := range users.Data {
+			cfg.BasicAuthUsers[user] = string(hash)
+		}
+
+		password, hash := found.Data[webConfigPasswordKey], found.Data[webConfigPasswordHashKey]
+		if len(password) == 0 || len(hash) == 0 {
+			password, hash, err = generatePassword()
+			if err != nil {
+				log.Error(err, "Failed to generate the operator password")
+				return nil, err
+			}
+		}
+		cfg.BasicAuthUsers[operatorUser] = string(hash)
+		data[webConfigPasswordKey] = password
+		data[webConfigPasswordHashKey] = hash
+	}
+
+	data[webConfigFile], err = marshalYAML(&cfg)
+	if err != nil {
+		log.Error(err, "Failed to render the web configuration")
+		return nil, err
+	}
+	if cr.Spec.Thanos != nil {
+		data[thanosHTTPClientKey], err = marshalYAML(thanosHTTPClientConfig(cr, string(data[webConfigPasswordKey])))
+		if err != nil {
+			log.Error(err, "Failed to render the Thanos HTTP client configuration")
+			return nil, err
+		}
+	}
+
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      webConfigSecretName(cr),
+			Namespace: cr.Namespace,
+			Labels:    labelsForPrometheus(cr.Name),
+		},
+		Data: data,
+	}, nil
+}
+
+// thanosHTTPClientConfig returns the configuration the Thanos sidecar uses to
+// call a secured Prometheus. The certificate isn't verified since Prometheus
+// is reached through the loopback address.
+func thanosHTTPClientConfig(cr *monitoringv1alpha1.Prometheus, password string) map[string]interface{} {
+	cfg := map[string]interface{}{}
+	if webScheme(cr) == "https" {
+		cfg["tls_config"] = map[string]interface{}{"insecure_skip_verify": true}
+	}
+	if webBasicAuth(cr) {
+		cfg["basic_auth"] = map[string]string{"username": operatorUser, "password": password}
+	}
+	return cfg
+}
+
+// reconcileSelfSignedCert makes sure the self-signed certificate of a
+// Prometheus exists, and renews it when it is about to expire.
+func (r *PrometheusReconciler) reconcileSelfSignedCert(ctx context.Context, cr *monitoringv1alpha1.Prometheus) error {
+	log := ctrllog.FromContext(ctx)
+
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      webTLSSecretName(cr),
+			Namespace: cr.Namespace,
+		},
+	}
+	op, err := controllerutil.CreateOrUpdate(ctx, r.Client, secret, func() error {
+		secret.Labels = labelsForPrometheus(cr.Name)
+		secret.Type = corev1.SecretTypeTLS
+		if !certificateExpiring(secret.Data[corev1.TLSCertKey]) {
+			return ctrl.SetControllerReference(cr, secret, r.Scheme)
+		}
+		cert, key, err := generateSelfSignedCert(cr)
+		if err != nil {
+			return err
+		}
+		secret.Data = map[string][]byte{
+			corev1.TLSCertKey:       cert,
+			corev1.TLSPrivateKeyKey: key,
+		}
+		// Set Prometheus instance as the owner and controller
+		return ctrl.SetControllerReference(cr, secret, r.Scheme)
+	})
+	if err != nil {
+		log.Error(err, "Failed to reconcile Secret", "Secret.Namespace", secret.Namespace, "Secret.Name", secret.Name)
+		return err
+	}
+	if op != controllerutil.OperationResultNone {
+		log.Info("Secret reconciled", "Secret.Namespace", secret.Namespace, "Secret.Name", secret.Name, "operation", op)
+	}
+	return nil
+}
+
+// deleteSecret deletes a Secret generated by the operator if it exists and
+// is controlled by the Prometheus, leaving alone a Secret of the same name
+// created by the user.
+func (r *PrometheusReconciler) deleteSecret(ctx context.Context, cr *monitoringv1alpha1.Prometheus, name string) error {
+	log := ctrllog.FromContext(ctx)
+
+	secret := &corev1.Secret{}
+	err := r.Get(ctx, types.NamespacedName{Name: name, Namespace: cr.Namespace}, secret)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		log.Error(err, "Failed to get Secret", "Secret.Namespace", cr.Namespace, "Secret.Name", name)
+		return err
+	}
+	if !metav1.IsControlledBy(secret, cr) {
+		return nil
+	}
+	err = r.Delete(ctx, secret)
+	if err != nil && !errors.IsNotFound(err) {
+		log.Error(err, "Failed to delete Secret", "Secret.Namespace", secret.Namespace, "Secret.Name", secret.Name)
+		return err
+	}
+	if err == nil {
+		log.Info("Secret deleted", "Secret.Namespace", secret.Namespace, "Secret.Name", secret.Name)
+	}
+	return nil
+}
+
+// prometheusesForSecret returns the Prometheuses of the namespace of a Secret
+// that reference it, for changes to the Secret to be reconciled.
+func (r *PrometheusReconciler) prometheusesForSecret(obj client.Object) []reconcile.Request {
+	list := &monitoringv1alpha1.PrometheusList{}
+	err := r.List(context.Background(), list, client.InNamespace(obj.GetNamespace()))
+	if err != nil {
+		return nil
+	}
+	var requests []reconcile.Request
+	for _, cr := range list.Items {
+		if secretReferenced(cr, obj.GetName()) {
+			requests = append(requests, reconcile.Request{
+				NamespacedName: types.NamespacedName{Name: cr.Name, Namespace: cr.Namespace},
+			})
+		}
+	}
+	return requests
+}
+
+// secretReferenced reports whether the configuration of a Prometheus is
+// rendered from the given Secret
+func secretReferenced(cr *monitoringv1alpha1.Prometheus, name string) bool {
+	if cr.Spec.Web != nil && cr.Spec.Web.BasicAuthUsers != nil && cr.Spec.Web.BasicAuthUsers.Name == name {
+		return true
+	}
+	return cr.Spec.AdditionalScrapeConfigs != nil && cr.Spec.AdditionalScrapeConfigs.Name == name
+}
+
+// generatePassword returns a random password and its bcrypt hash
+func generatePassword() ([]byte, []byte, error) {
+	raw := make([]byte, 24)
+	if _, err := rand.Read(raw); err != nil {
+		return nil, nil, err
+	}
+	password := []byte(hex.EncodeToString(raw))
+	hash, err := bcrypt.GenerateFromPassword(password, bcrypt.DefaultCost)
+	if err != nil {
+		return nil, nil, err
+	}
+	return password, hash, nil
+}
+
+// generateSelfSignedCert returns a PEM encoded self-signed certificate valid
+// for the names of the Service of a Prometheus, and its private key
+func generateSelfSignedCert(cr *monitoringv1alpha1.Prometheus) ([]byte, []byte, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, nil, err
+	}
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, nil, err
+	}
+	now := time.Now()
+	template := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: fmt.Sprintf("%s.%s.svc", cr.Name, cr.Namespace)},
+		DNSNames: []string{
+			"localhost",
+			cr.Name,
+			fmt.Sprintf("%s.%s", cr.Name, cr.Namespace),
+			fmt.Sprintf("%s.%s.svc", cr.Name, cr.Namespace),
+		},
+		IPAddresses:           []net.IP{net.IPv4(127, 0, 0, 1)},
+		NotBefore:             now.Add(-time.Hour),
+		NotAfter:              now.Add(selfSignedValidity),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		return nil, nil, err
+	}
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return nil, nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}), nil
+}
+
+// certificateExpiring reports whether a PEM encoded certificate is missing,
+// invalid or expires within a month
+func certificateExpiring(data []byte) bool {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return true
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return true
+	}
+	return time.Now().Add(30 * 24 * time.Hour).After(cert.NotAfter)
+}
+
+// marshalYAML renders an object as YAML through its JSON tags
+func marshalYAML(obj interface{}) ([]byte, error) {
+	dataJson, err := json.Marshal(obj)
+	if err != nil {
+		return nil, err
+	}
+	return yaml.JSONToYAML(dataJson)
+}
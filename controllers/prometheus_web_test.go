@@ -0,0 +1,165 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	monitoringv1alpha1 "github.com/marieroque/best-prometheus-operator-in-the-world/api/monitoring/v1alpha1"
+)
+
+func TestWebConfigBasicAuthUsers(t *testing.T) {
+	ctx := context.Background()
+	cr := newTestPrometheus("web")
+	cr.Spec.Web = &monitoringv1alpha1.WebSpec{
+		BasicAuthUsers: &corev1.LocalObjectReference{Name: "users"},
+	}
+	r := newTestReconciler(t, cr)
+	crKey := types.NamespacedName{Name: cr.Name, Namespace: cr.Namespace}
+	webKey := types.NamespacedName{Name: webConfigSecretName(cr), Namespace: cr.Namespace}
+
+	// condition returns the WebConfigValid condition
+	condition := func() *metav1.Condition {
+		t.Helper()
+		found := &monitoringv1alpha1.Prometheus{}
+		if err := r.Get(ctx, crKey, found); err != nil {
+			t.Fatal(err)
+		}
+		return meta.FindStatusCondition(found.Status.Conditions, monitoringv1alpha1.ConditionTypeWebConfigValid)
+	}
+
+	// A missing Secret is reported in the status instead of being retried
+	if err := reconcileUntilDone(t, r, cr); err != nil {
+		t.Fatal(err)
+	}
+	if cond := condition(); cond == nil || cond.Status != metav1.ConditionFalse || cond.Reason != "SecretNotFound" {
+		t.Errorf("got condition %+v, want False with reason SecretNotFound", cond)
+	}
+
+	users := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "users", Namespace: cr.Namespace},
+		Data:       map[string][]byte{"alice": []byte("$2y$10$alice")},
+	}
+	if err := r.Create(ctx, users); err != nil {
+		t.Fatal(err)
+	}
+	if err := reconcileUntilDone(t, r, cr); err != nil {
+		t.Fatal(err)
+	}
+	if cond := condition(); cond == nil || cond.Status != metav1.ConditionTrue {
+		t.Errorf("got condition %+v, want True", cond)
+	}
+	web := &corev1.Secret{}
+	if err := r.Get(ctx, webKey, web); err != nil {
+		t.Fatal(err)
+	}
+	config := string(web.Data[webConfigFile])
+	if !strings.Contains(config, "alice: $2y$10$alice") || !strings.Contains(config, operatorUser+":") {
+		t.Errorf("the users are missing from the web configuration:\n%s", config)
+	}
+
+	// The reserved user is rejected, and the last rendered users are kept
+	users.Data[operatorUser] = []byte("$2y$10$mallory")
+	if err := r.Update(ctx, users); err != nil {
+		t.Fatal(err)
+	}
+	if err := reconcileUntilDone(t, r, cr); err != nil {
+		t.Fatal(err)
+	}
+	if cond := condition(); cond == nil || cond.Status != metav1.ConditionFalse || cond.Reason != "ReservedUser" {
+		t.Errorf("got condition %+v, want False with reason ReservedUser", cond)
+	}
+	if err := r.Get(ctx, webKey, web); err != nil {
+		t.Fatal(err)
+	}
+	if got := string(web.Data[webConfigFile]); got != config || strings.Contains(got, "mallory") {
+		t.Errorf("the last rendered web configuration wasn't kept:\n%s", got)
+	}
+
+	// The condition is removed with the basic auth users
+	found := &monitoringv1alpha1.Prometheus{}
+	if err := r.Get(ctx, crKey, found); err != nil {
+		t.Fatal(err)
+	}
+	found.Spec.Web = nil
+	if err := r.Update(ctx, found); err != nil {
+		t.Fatal(err)
+	}
+	if err := reconcileUntilDone(t, r, cr); err != nil {
+		t.Fatal(err)
+	}
+	if cond := condition(); cond != nil {
+		t.Errorf("got condition %+v, want none", cond)
+	}
+}
+
+func TestDeleteWebSecrets(t *testing.T) {
+	ctx := context.Background()
+	cr := newTestPrometheus("web")
+	cr.Spec.Web = &monitoringv1alpha1.WebSpec{TLS: &monitoringv1alpha1.WebTLSConfig{}}
+	r := newTestReconciler(t, cr)
+	crKey := types.NamespacedName{Name: cr.Name, Namespace: cr.Namespace}
+	configKey := types.NamespacedName{Name: webConfigSecretName(cr), Namespace: cr.Namespace}
+	tlsKey := types.NamespacedName{Name: webTLSSecretName(cr), Namespace: cr.Namespace}
+
+	if err := reconcileUntilDone(t, r, cr); err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range []types.NamespacedName{configKey, tlsKey} {
+		if err := r.Get(ctx, key, &corev1.Secret{}); err != nil {
+			t.Fatalf("Secret %s wasn't created: %v", key.Name, err)
+		}
+	}
+
+	// The Secrets of the operator are deleted with the web section, and
+	// a Secret of the user with the same name is kept
+	found := &monitoringv1alpha1.Prometheus{}
+	if err := r.Get(ctx, crKey, found); err != nil {
+		t.Fatal(err)
+	}
+	found.Spec.Web = nil
+	if err := r.Update(ctx, found); err != nil {
+		t.Fatal(err)
+	}
+	if err := reconcileUntilDone(t, r, cr); err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range []types.NamespacedName{configKey, tlsKey} {
+		if err := r.Get(ctx, key, &corev1.Secret{}); !errors.IsNotFound(err) {
+			t.Errorf("Secret %s wasn't deleted: %v", key.Name, err)
+		}
+	}
+
+	user := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: tlsKey.Name, Namespace: tlsKey.Namespace}}
+	if err := r.Create(ctx, user); err != nil {
+		t.Fatal(err)
+	}
+	if err := reconcileUntilDone(t, r, cr); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Get(ctx, tlsKey, &corev1.Secret{}); err != nil {
+		t.Errorf("the Secret of the user was deleted: %v", err)
+	}
+}
@@ -0,0 +1,588 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"fmt"
+	"sort"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/version"
+
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	monitoringv1alpha1 "github.com/marieroque/best-prometheus-operator-in-the-world/api/monitoring/v1alpha1"
+)
+
+// Default images of the containers of the Prometheus pods. The reloader is the
+// sidecar reloading Prometheus when its configuration changes. It also
+// expands the $(VAR) environment variables references found in the
+// configuration.
+const (
+	DefaultPrometheusImage = "quay.io/prometheus/prometheus"
+	DefaultReloaderImage   = "quay.io/prometheus-operator/prometheus-config-reloader:v0.55.0"
+	DefaultThanosImage     = "quay.io/thanos/thanos"
+)
+
+const (
+	// configDir is where the ConfigMap holding the configuration is mounted.
+	configDir = "/etc/prometheus/config/"
+	// configOutDir is where the reloader writes the expanded configuration
+	// read by Prometheus.
+	configOutDir  = "/etc/prometheus/config_out/"
+	configOutFile = configOutDir + "prometheus.env.yaml"
+	// dataDir is where the Prometheus TSDB is stored unless spec.tsdb.path
+	// is set.
+	dataDir = "/prometheus/"
+	// fileSDDir is where the ConfigMaps of the file service discoveries are
+	// mounted, each one in a directory named after it.
+	fileSDDir = "/etc/prometheus/file_sd/"
+)
+
+const (
+	webPort         = 9090
+	thanosGRPCPort  = 10901
+	thanosHTTPPort  = 10902
+	thanosBlockSpan = "2h"
+)
+
+// deploymentForPrometheus returns the prometheus Deployment object of a shard
+func (r *PrometheusReconciler) deploymentForPrometheus(cr *monitoringv1alpha1.Prometheus, shard int32) (*appsv1.Deployment, error) {
+	ls := labelsForPrometheusShard(cr.Name, shard)
+	replicas := replicasForPrometheus(cr)
+	template, err := r.podTemplateForPrometheus(cr, shard)
+	if err != nil {
+		return nil, err
+	}
+	// Every shard selects its pods by the shard label, the Deployments
+	// created before sharding are recreated with that selector by
+	// reconcileShard since it is immutable.
+	dep := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      deploymentNameForShard(cr, shard),
+			Namespace: cr.Namespace,
+			Labels:    ls,
+		},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: &replicas,
+			Selector: &metav1.LabelSelector{
+				MatchLabels: ls,
+			},
+			Template: template,
+		},
+	}
+	// Set Prometheus instance as the owner and controller
+	ctrl.SetControllerReference(cr, dep, r.Scheme)
+	return dep, nil
+}
+
+// daemonSetForPrometheus returns the prometheus DaemonSet object running an
+// agent on every node
+func (r *PrometheusReconciler) daemonSetForPrometheus(cr *monitoringv1alpha1.Prometheus) (*appsv1.DaemonSet, error) {
+	ls := labelsForPrometheusShard(cr.Name, 0)
+	template, err := r.podTemplateForPrometheus(cr, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	ds := &appsv1.DaemonSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      cr.Name,
+			Namespace: cr.Namespace,
+			Labels:    ls,
+		},
+		Spec: appsv1.DaemonSetSpec{
+			Selector: &metav1.LabelSelector{
+				MatchLabels: ls,
+			},
+			Template: template,
+		},
+	}
+	// Set Prometheus instance as the owner and controller
+	ctrl.SetControllerReference(cr, ds, r.Scheme)
+	return ds, nil
+}
+
+// podTemplateForPrometheus returns the template of the pods running a shard
+func (r *PrometheusReconciler) podTemplateForPrometheus(cr *monitoringv1alpha1.Prometheus, shard int32) (corev1.PodTemplateSpec, error) {
+	configMounts := []corev1.VolumeMount{{
+		MountPath: configDir,
+		Name:      "prometheus-config-volume",
+		ReadOnly:  true,
+	}, {
+		MountPath: configOutDir,
+		Name:      "config-out",
+	}}
+	reloaderArgs := []string{
+		"--listen-address=:8080",
+		"--config-file=" + configDir + "prometheus.yml",
+		"--config-envsubst-file=" + configOutFile,
+	}
+	// The node name is used to only discover the targets of the local node
+	// when running as a DaemonSet, the pod name to tell replicas apart in the
+	// external labels
+	reloaderEnv := []corev1.EnvVar{{
+		Name: "NODE_NAME",
+		ValueFrom: &corev1.EnvVarSource{
+			FieldRef: &corev1.ObjectFieldSelector{FieldPath: "spec.nodeName"},
+		},
+	}, {
+		Name: "POD_NAME",
+		ValueFrom: &corev1.EnvVarSource{
+			FieldRef: &corev1.ObjectFieldSelector{FieldPath: "metadata.name"},
+		},
+	}}
+
+	template := corev1.PodTemplateSpec{
+		ObjectMeta: metav1.ObjectMeta{
+			Labels: labelsForPrometheusShard(cr.Name, shard),
+		},
+		Spec: corev1.PodSpec{
+			// Expands the configuration once before Prometheus starts
+			InitContainers: []corev1.Container{{
+				Name:         "init-config-reloader",
+				Image:        r.reloaderImage(),
+				Args:         append([]string{"--watch-interval=0"}, reloaderArgs...),
+				Env:          reloaderEnv,
+				VolumeMounts: configMounts,
+			}},
+			Containers: []corev1.Container{{
+				Name:  "prometheus",
+				Image: r.prometheusImage(cr),
+				Args:  prometheusArgs(cr),
+				Ports: []corev1.ContainerPort{{
+					Name:          "web",
+					ContainerPort: webPort,
+				}},
+				VolumeMounts: []corev1.VolumeMount{{
+					MountPath: configOutDir,
+					Name:      "config-out",
+					ReadOnly:  true,
+				}, {
+					MountPath: dataPathForPrometheus(cr),
+					Name:      "prometheus-data",
+				}},
+			}, {
+				Name:         "config-reloader",
+				Image:        r.reloaderImage(),
+				Args:         append([]string{"--reload-url=" + reloadURL(cr)}, reloaderArgs...),
+				Env:          append(reloaderEnv, webPasswordEnv(cr)...),
+				VolumeMounts: configMounts,
+			}},
+			Volumes: []corev1.Volume{{
+				Name: "prometheus-config-volume",
+				VolumeSource: corev1.VolumeSource{
+					ConfigMap: &corev1.ConfigMapVolumeSource{
+						LocalObjectReference: corev1.LocalObjectReference{
+							Name: configmapNameForShard(cr, shard),
+						},
+					},
+				},
+			}, {
+				Name: "config-out",
+				VolumeSource: corev1.VolumeSource{
+					EmptyDir: &corev1.EmptyDirVolumeSource{},
+				},
+			}, {
+				Name: "prometheus-data",
+				VolumeSource: corev1.VolumeSource{
+					EmptyDir: &corev1.EmptyDirVolumeSource{},
+				},
+			}},
+			ServiceAccountName: serviceAccountNameForPrometheus(cr),
+			ImagePullSecrets:   cr.Spec.ImagePullSecrets,
+		},
+	}
+
+	addPrometheusProbes(cr, &template.Spec.Containers[0])
+	addFileSDVolumes(cr, &template.Spec)
+	if cr.Spec.Web != nil {
+		addWebVolumes(cr, &template.Spec)
+	}
+	if cr.Spec.Thanos != nil {
+		template.Spec.Containers = append(template.Spec.Containers, r.thanosSidecarForPrometheus(cr))
+	}
+	err := applyPodOverrides(cr, &template.Spec)
+	if err != nil {
+		return template, err
+	}
+	// The pull policy applies to the containers added by the overrides too,
+	// unless they set their own
+	for i := range template.Spec.InitContainers {
+		if template.Spec.InitContainers[i].ImagePullPolicy == "" {
+			template.Spec.InitContainers[i].ImagePullPolicy = cr.Spec.ImagePullPolicy
+		}
+	}
+	for i := range template.Spec.Containers {
+		if template.Spec.Containers[i].ImagePullPolicy == "" {
+			template.Spec.Containers[i].ImagePullPolicy = cr.Spec.ImagePullPolicy
+		}
+	}
+	return template, nil
+}
+
+// prometheusImage returns the image of the Prometheus container, pinned by
+// digest when one is given and tagged with the version otherwise
+func (r *PrometheusReconciler) prometheusImage(cr *monitoringv1alpha1.Prometheus) string {
+	image := r.PrometheusImage
+	if image == "" {
+		image = DefaultPrometheusImage
+	}
+	if cr.Spec.Image != nil {
+		image = *cr.Spec.Image
+	}
+	if cr.Spec.ImageDigest != nil {
+		return image + "@" + *cr.Spec.ImageDigest
+	}
+	return image + ":v" + *cr.Spec.Version
+}
+
+// reloaderImage returns the image of the config reloader containers
+func (r *PrometheusReconciler) reloaderImage() string {
+	if r.ReloaderImage == "" {
+		return DefaultReloaderImage
+	}
+	return r.ReloaderImage
+}
+
+// thanosImage returns the image of the Thanos sidecar container
+func (r *PrometheusReconciler) thanosImage(cr *monitoringv1alpha1.Prometheus) string {
+	image := r.ThanosImage
+	if image == "" {
+		image = DefaultThanosImage
+	}
+	return image + ":v" + *cr.Spec.Thanos.Version
+}
+
+// thanosSidecarForPrometheus returns the Thanos sidecar container exposing the
+// TSDB of Prometheus to Thanos Query and uploading its blocks
+func (r *PrometheusReconciler) thanosSidecarForPrometheus(cr *monitoringv1alpha1.Prometheus) corev1.Container {
+	sidecar := corev1.Container{
+		Name:  "thanos-sidecar",
+		Image: r.thanosImage(cr),
+		Args: []string{
+			"sidecar",
+			fmt.Sprintf("--prometheus.url=%s://127.0.0.1:%d%s", webScheme(cr), webPort, routePrefix(cr)),
+			"--tsdb.path=" + dataPathForPrometheus(cr),
+			fmt.Sprintf("--grpc-address=:%d", thanosGRPCPort),
+			fmt.Sprintf("--http-address=:%d", thanosHTTPPort),
+		},
+		Ports: []corev1.ContainerPort{{
+			Name:          "grpc",
+			ContainerPort: thanosGRPCPort,
+		}, {
+			Name:          "http",
+			ContainerPort: thanosHTTPPort,
+		}},
+		ReadinessProbe: &corev1.Probe{
+			ProbeHandler: corev1.ProbeHandler{
+				HTTPGet: &corev1.HTTPGetAction{
+					Path: "/-/ready",
+					Port: intstr.FromString("http"),
+				},
+			},
+			TimeoutSeconds:   1,
+			PeriodSeconds:    10,
+			SuccessThreshold: 1,
+			FailureThreshold: 3,
+		},
+		VolumeMounts: []corev1.VolumeMount{{
+			MountPath: dataPathForPrometheus(cr),
+			Name:      "prometheus-data",
+		}},
+	}
+	if objstore := cr.Spec.Thanos.ObjectStorageConfig; objstore != nil {
+		sidecar.Args = append(sidecar.Args, "--objstore.config=$(OBJSTORE_CONFIG)")
+		sidecar.Env = append(sidecar.Env, corev1.EnvVar{
+			Name: "OBJSTORE_CONFIG",
+			ValueFrom: &corev1.EnvVarSource{
+				SecretKeyRef: objstore,
+			},
+		})
+	}
+	if cr.Spec.Web != nil {
+		sidecar.Args = append(sidecar.Args, "--prometheus.http-client=$(PROMETHEUS_HTTP_CLIENT)")
+		sidecar.Env = append(sidecar.Env, corev1.EnvVar{
+			Name: "PROMETHEUS_HTTP_CLIENT",
+			ValueFrom: &corev1.EnvVarSource{
+				SecretKeyRef: &corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: webConfigSecretName(cr)},
+					Key:                  thanosHTTPClientKey,
+				},
+			},
+		})
+	}
+	return sidecar
+}
+
+// reloadURL returns the URL the config reloader calls to reload Prometheus.
+// The password of the operator user is expanded by the kubelet.
+func reloadURL(cr *monitoringv1alpha1.Prometheus) string {
+	userinfo := ""
+	if webBasicAuth(cr) {
+		userinfo = operatorUser + ":$(WEB_PASSWORD)@"
+	}
+	return fmt.Sprintf("%s://%s127.0.0.1:%d%s", webScheme(cr), userinfo, webPort, webPath(cr, "/-/reload"))
+}
+
+// webPasswordEnv returns the environment of the sidecars authenticating to
+// Prometheus with the operator user
+func webPasswordEnv(cr *monitoringv1alpha1.Prometheus) []corev1.EnvVar {
+	if !webBasicAuth(cr) {
+		return nil
+	}
+	return []corev1.EnvVar{{
+		Name: "WEB_PASSWORD",
+		ValueFrom: &corev1.EnvVarSource{
+			SecretKeyRef: &corev1.SecretKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: webConfigSecretName(cr)},
+				Key:                  webConfigPasswordKey,
+			},
+		},
+	}}
+}
+
+// addWebVolumes mounts the web configuration file and the certificates of
+// the web endpoint into the Prometheus container. Prometheus reads them on
+// every connection, so certificate rotations don't need a restart.
+func addWebVolumes(cr *monitoringv1alpha1.Prometheus, spec *corev1.PodSpec) {
+	prometheus := &spec.Containers[0]
+	prometheus.Args = append(prometheus.Args, "--web.config.file="+webConfigDir+webConfigFile)
+	prometheus.VolumeMounts = append(prometheus.VolumeMounts, corev1.VolumeMount{
+		MountPath: webConfigDir,
+		Name:      "web-config",
+		ReadOnly:  true,
+	})
+	spec.Volumes = append(spec.Volumes, corev1.Volume{
+		Name: "web-config",
+		VolumeSource: corev1.VolumeSource{
+			Secret: &corev1.SecretVolumeSource{
+				SecretName: webConfigSecretName(cr),
+				Items:      []corev1.KeyToPath{{Key: webConfigFile, Path: webConfigFile}},
+			},
+		},
+	})
+
+	tls := cr.Spec.Web.TLS
+	if tls == nil {
+		return
+	}
+	cert, key := tls.Cert, tls.Key
+	if webSelfSigned(cr) {
+		generated := corev1.LocalObjectReference{Name: webTLSSecretName(cr)}
+		cert = &corev1.SecretKeySelector{LocalObjectReference: generated, Key: corev1.TLSCertKey}
+		key = &corev1.SecretKeySelector{LocalObjectReference: generated, Key: corev1.TLSPrivateKeyKey}
+	}
+	sources := []corev1.VolumeProjection{
+		secretProjection(cert, corev1.TLSCertKey),
+		secretProjection(key, corev1.TLSPrivateKeyKey),
+	}
+	if tls.ClientCA != nil {
+		sources = append(sources, secretProjection(tls.ClientCA, "client-ca.crt"))
+	}
+	prometheus.VolumeMounts = append(prometheus.VolumeMounts, corev1.VolumeMount{
+		MountPath: webTLSDir,
+		Name:      "web-tls",
+		ReadOnly:  true,
+	})
+	spec.Volumes = append(spec.Volumes, corev1.Volume{
+		Name: "web-tls",
+		VolumeSource: corev1.VolumeSource{
+			Projected: &corev1.ProjectedVolumeSource{Sources: sources},
+		},
+	})
+}
+
+// addFileSDVolumes mounts the ConfigMaps of the file service discoveries into
+// the Prometheus container. The kubelet updates the mounted files when the
+// ConfigMaps change, and Prometheus watches them. The volumes are optional
+// so that a missing ConfigMap doesn't prevent Prometheus from starting. The
+// volumes are named after the index of the ConfigMap since its name can hold
+// dots, which volume names can't.
+func addFileSDVolumes(cr *monitoringv1alpha1.Prometheus, spec *corev1.PodSpec) {
+	optional := true
+	for i, name := range fileSDConfigMaps(cr) {
+		volume := fmt.Sprintf("file-sd-%d", i)
+		spec.Containers[0].VolumeMounts = append(spec.Containers[0].VolumeMounts, corev1.VolumeMount{
+			MountPath: fileSDDir + name,
+			Name:      volume,
+			ReadOnly:  true,
+		})
+		spec.Volumes = append(spec.Volumes, corev1.Volume{
+			Name: volume,
+			VolumeSource: corev1.VolumeSource{
+				ConfigMap: &corev1.ConfigMapVolumeSource{
+					LocalObjectReference: corev1.LocalObjectReference{Name: name},
+					Optional:             &optional,
+				},
+			},
+		})
+	}
+}
+
+// fileSDConfigMaps returns the sorted names of the ConfigMaps referenced by
+// the file service discoveries of a Prometheus
+func fileSDConfigMaps(cr *monitoringv1alpha1.Prometheus) []string {
+	seen := map[string]bool{}
+	var names []string
+	for _, sc := range cr.Spec.ScrapeConfigs {
+		for _, sd := range sc.FileSDConfigs {
+			if !seen[sd.ConfigMap] {
+				seen[sd.ConfigMap] = true
+				names = append(names, sd.ConfigMap)
+			}
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// secretProjection projects a Secret key to the given path
+func secretProjection(selector *corev1.SecretKeySelector, path string) corev1.VolumeProjection {
+	return corev1.VolumeProjection{
+		Secret: &corev1.SecretProjection{
+			LocalObjectReference: selector.LocalObjectReference,
+			Items:                []corev1.KeyToPath{{Key: selector.Key, Path: path}},
+		},
+	}
+}
+
+// prometheusArgs returns the command line arguments of the Prometheus container
+func prometheusArgs(cr *monitoringv1alpha1.Prometheus) []string {
+	args := []string{
+		"--config.file=" + configOutFile,
+		// Lets the config reloader trigger a reload
+		"--web.enable-lifecycle",
+	}
+	if cr.Spec.Ingress != nil {
+		args = append(args,
+			"--web.external-url="+externalURL(cr),
+			"--web.route-prefix="+routePrefix(cr)+"/",
+		)
+	}
+	if cr.AgentMode() {
+		args = append(args, agentStorageArgs(cr)...)
+		// Prometheus 3 replaced the agent feature flag with a dedicated flag
+		v, err := version.ParseSemantic(*cr.Spec.Version)
+		if err == nil && v.Major() >= 3 {
+			args = append(args, "--agent")
+		} else {
+			args = append(args, "--enable-feature=agent")
+		}
+		return args
+	}
+	return append(args, tsdbArgs(cr)...)
+}
+
+// tsdbArgs returns the storage arguments of a Prometheus server
+func tsdbArgs(cr *monitoringv1alpha1.Prometheus) []string {
+	args := []string{"--storage.tsdb.path=" + dataPathForPrometheus(cr)}
+	tsdb := cr.Spec.TSDB
+	if tsdb == nil {
+		tsdb = &monitoringv1alpha1.TSDBSpec{}
+	}
+	if tsdb.RetentionTime != nil {
+		args = append(args, "--storage.tsdb.retention.time="+*tsdb.RetentionTime)
+	}
+	if tsdb.RetentionSize != nil {
+		args = append(args, "--storage.tsdb.retention.size="+*tsdb.RetentionSize)
+	}
+	if tsdb.WALCompression != nil {
+		args = append(args, boolFlag("storage.tsdb.wal-compression", *tsdb.WALCompression))
+	}
+	if cr.Spec.Thanos != nil {
+		// Local compaction has to be disabled for the sidecar to upload
+		// the blocks, which requires equal min and max durations
+		args = append(args,
+			"--storage.tsdb.min-block-duration="+thanosBlockSpan,
+			"--storage.tsdb.max-block-duration="+thanosBlockSpan,
+		)
+		return args
+	}
+	if tsdb.MinBlockDuration != nil {
+		args = append(args, "--storage.tsdb.min-block-duration="+*tsdb.MinBlockDuration)
+	}
+	if tsdb.MaxBlockDuration != nil {
+		args = append(args, "--storage.tsdb.max-block-duration="+*tsdb.MaxBlockDuration)
+	}
+	return args
+}
+
+// agentStorageArgs returns the storage arguments of a Prometheus agent
+func agentStorageArgs(cr *monitoringv1alpha1.Prometheus) []string {
+	args := []string{"--storage.agent.path=" + dataPathForPrometheus(cr)}
+	if cr.Spec.TSDB != nil && cr.Spec.TSDB.WALCompression != nil {
+		args = append(args, boolFlag("storage.agent.wal-compression", *cr.Spec.TSDB.WALCompression))
+	}
+	return args
+}
+
+// boolFlag returns a boolean command line flag, disabled flags being negated
+// with the no- prefix.
+func boolFlag(name string, enabled bool) string {
+	if enabled {
+		return "--" + name
+	}
+	return "--no-" + name
+}
+
+// dataPathForPrometheus returns the directory Prometheus stores its data in
+func dataPathForPrometheus(cr *monitoringv1alpha1.Prometheus) string {
+	if cr.Spec.TSDB != nil && cr.Spec.TSDB.Path != nil {
+		return *cr.Spec.TSDB.Path
+	}
+	return dataDir
+}
+
+// podTemplateChanged reports whether the live pod template drifted from the
+// desired one. Fields left empty in the desired template are ignored so that
+// the values defaulted by the API server don't count as a drift. The length of
+// the lists is compared explicitly since DeepDerivative ignores extra items.
+func podTemplateChanged(desired, live *corev1.PodTemplateSpec) bool {
+	if !equality.Semantic.DeepDerivative(desired, live) {
+		return true
+	}
+	if len(desired.Spec.Containers) != len(live.Spec.Containers) ||
+		len(desired.Spec.InitContainers) != len(live.Spec.InitContainers) ||
+		len(desired.Spec.Volumes) != len(live.Spec.Volumes) ||
+		len(desired.Spec.ImagePullSecrets) != len(live.Spec.ImagePullSecrets) {
+		return true
+	}
+	for i := range desired.Spec.Containers {
+		if containerListsChanged(&desired.Spec.Containers[i], &live.Spec.Containers[i]) {
+			return true
+		}
+	}
+	for i := range desired.Spec.InitContainers {
+		if containerListsChanged(&desired.Spec.InitContainers[i], &live.Spec.InitContainers[i]) {
+			return true
+		}
+	}
+	return false
+}
+
+// containerListsChanged reports whether lists of a container have a different
+// length than the desired ones.
+func containerListsChanged(desired, live *corev1.Container) bool {
+	return len(desired.Args) != len(live.Args) ||
+		len(desired.Env) != len(live.Env) ||
+		len(desired.VolumeMounts) != len(live.VolumeMounts)
+}
@@ -0,0 +1,304 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	monitoringv1alpha1 "github.com/marieroque/best-prometheus-operator-in-the-world/api/monitoring/v1alpha1"
+)
+
+func TestPrometheusArgsAgentMode(t *testing.T) {
+	tests := []struct {
+		version string
+		want    []string
+	}{
+		{version: "2.40.0", want: []string{"--enable-feature=agent", "--storage.agent.path=" + dataDir}},
+		{version: "3.0.0", want: []string{"--agent", "--storage.agent.path=" + dataDir}},
+	}
+	for _, tt := range tests {
+		cr := newTestPrometheus("agent")
+		mode := monitoringv1alpha1.ModeAgent
+		cr.Spec.Mode = &mode
+		cr.Spec.Version = &tt.version
+
+		args := strings.Join(prometheusArgs(cr), " ")
+		for _, want := range tt.want {
+			if !strings.Contains(args, want) {
+				t.Errorf("version %s: got args %q without %q", tt.version, args, want)
+			}
+		}
+		if strings.Contains(args, "--storage.tsdb.") {
+			t.Errorf("version %s: got TSDB args %q in agent mode", tt.version, args)
+		}
+	}
+}
+
+func TestDaemonSetReconcile(t *testing.T) {
+	ctx := context.Background()
+	cr := newTestPrometheus("daemonset")
+	mode := monitoringv1alpha1.ModeAgent
+	cr.Spec.Mode = &mode
+	cr.Spec.DaemonSet = true
+	r := newTestReconciler(t, cr)
+	key := types.NamespacedName{Name: cr.Name, Namespace: cr.Namespace}
+
+	if err := reconcileUntilDone(t, r, cr); err != nil {
+		t.Fatal(err)
+	}
+	ds := &appsv1.DaemonSet{}
+	if err := r.Get(ctx, key, ds); err != nil {
+		t.Fatalf("the DaemonSet wasn't created: %v", err)
+	}
+	if args := strings.Join(ds.Spec.Template.Spec.Containers[0].Args, " "); !strings.Contains(args, "--enable-feature=agent") {
+		t.Errorf("got args %q, want an agent", args)
+	}
+	if err := r.Get(ctx, key, &appsv1.Deployment{}); !errors.IsNotFound(err) {
+		t.Errorf("got a Deployment along the DaemonSet (%v)", err)
+	}
+}
+
+// containerNamed returns the container of a pod template with the given name.
+func containerNamed(t *testing.T, spec *corev1.PodSpec, name string) *corev1.Container {
+	t.Helper()
+	for i := range spec.Containers {
+		if spec.Containers[i].Name == name {
+			return &spec.Containers[i]
+		}
+	}
+	t.Fatalf("got no %s container in %+v", name, spec.Containers)
+	return nil
+}
+
+func TestThanosSidecar(t *testing.T) {
+	cr := newTestPrometheus("thanos")
+	version := "0.30.0"
+	cr.Spec.Thanos = &monitoringv1alpha1.ThanosSpec{
+		Version: &version,
+		ObjectStorageConfig: &corev1.SecretKeySelector{
+			LocalObjectReference: corev1.LocalObjectReference{Name: "objstore"},
+			Key:                  "objstore.yml",
+		},
+	}
+	r := newTestReconciler(t)
+
+	template, err := r.podTemplateForPrometheus(cr, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sidecar := containerNamed(t, &template.Spec, "thanos-sidecar")
+	if sidecar.Image != DefaultThanosImage+":v0.30.0" {
+		t.Errorf("got sidecar image %s", sidecar.Image)
+	}
+	if args := strings.Join(sidecar.Args, " "); !strings.Contains(args, "--objstore.config=$(OBJSTORE_CONFIG)") {
+		t.Errorf("got sidecar args %q without the object storage", args)
+	}
+	if len(sidecar.Env) != 1 || sidecar.Env[0].ValueFrom.SecretKeyRef != cr.Spec.Thanos.ObjectStorageConfig {
+		t.Errorf("got sidecar env %+v, want the object storage Secret", sidecar.Env)
+	}
+
+	// The sidecar uploads the blocks, local compaction is disabled
+	args := strings.Join(containerNamed(t, &template.Spec, "prometheus").Args, " ")
+	for _, want := range []string{"--storage.tsdb.min-block-duration=2h", "--storage.tsdb.max-block-duration=2h"} {
+		if !strings.Contains(args, want) {
+			t.Errorf("got Prometheus args %q without %q", args, want)
+		}
+	}
+
+	if ports := serviceForPrometheus(cr).Spec.Ports; len(ports) != 2 || ports[1].Port != thanosGRPCPort {
+		t.Errorf("got Service ports %+v, want the gRPC port of the sidecar", ports)
+	}
+
+	data, err := r.renderConfig(cr, 0, &configInputs{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"prometheus: default/thanos", "prometheus_replica: $(POD_NAME)"} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("got configuration without the external label %q:\n%s", want, data)
+		}
+	}
+}
+
+func TestThanosStatus(t *testing.T) {
+	ctx := context.Background()
+	cr := newTestPrometheus("thanos-status")
+	version := "0.30.0"
+	cr.Spec.Thanos = &monitoringv1alpha1.ThanosSpec{Version: &version}
+	pod := func(name string, ready bool) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: cr.Namespace, Labels: labelsForPrometheusShard(cr.Name, 0)},
+			Status: corev1.PodStatus{ContainerStatuses: []corev1.ContainerStatus{
+				{Name: "thanos-sidecar", Ready: ready},
+			}},
+		}
+	}
+
+	tests := []struct {
+		name string
+		pods []client.Object
+		want metav1.ConditionStatus
+	}{
+		{name: "no pod", want: metav1.ConditionFalse},
+		{name: "sidecars ready", pods: []client.Object{pod("a", true), pod("b", true)}, want: metav1.ConditionTrue},
+		{name: "sidecar not ready", pods: []client.Object{pod("a", true), pod("b", false)}, want: metav1.ConditionFalse},
+	}
+	for _, tt := range tests {
+		cr := cr.DeepCopy()
+		r := newTestReconciler(t, append(tt.pods, cr)...)
+		if err := r.updateThanosStatus(ctx, cr); err != nil {
+			t.Fatal(err)
+		}
+		condition := meta.FindStatusCondition(cr.Status.Conditions, monitoringv1alpha1.ConditionTypeThanosSidecarReady)
+		if condition == nil || condition.Status != tt.want {
+			t.Errorf("%s: got condition %+v, want %s", tt.name, condition, tt.want)
+		}
+	}
+}
+
+func TestTSDBArgs(t *testing.T) {
+	retention, size, window := "30d", "10GB", "1h"
+	minBlock, maxBlock, path := "1h", "12h", "/data/"
+	disabled := false
+	cr := newTestPrometheus("tsdb")
+	cr.Spec.TSDB = &monitoringv1alpha1.TSDBSpec{
+		RetentionTime:        &retention,
+		RetentionSize:        &size,
+		WALCompression:       &disabled,
+		OutOfOrderTimeWindow: &window,
+		Path:                 &path,
+		MinBlockDuration:     &minBlock,
+		MaxBlockDuration:     &maxBlock,
+	}
+
+	want := []string{
+		"--storage.tsdb.path=/data/",
+		"--storage.tsdb.retention.time=30d",
+		"--storage.tsdb.retention.size=10GB",
+		"--no-storage.tsdb.wal-compression",
+		"--storage.tsdb.min-block-duration=1h",
+		"--storage.tsdb.max-block-duration=12h",
+	}
+	if got := tsdbArgs(cr); !equality.Semantic.DeepEqual(got, want) {
+		t.Errorf("got args %q, want %q", got, want)
+	}
+
+	// The out-of-order window is reloadable and set in the configuration
+	data, err := newTestReconciler(t).renderConfig(cr, 0, &configInputs{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "out_of_order_time_window: 1h") {
+		t.Errorf("got configuration without the out-of-order window:\n%s", data)
+	}
+
+	template, err := newTestReconciler(t).podTemplateForPrometheus(cr, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, mount := range containerNamed(t, &template.Spec, "prometheus").VolumeMounts {
+		if mount.Name == "prometheus-data" && mount.MountPath != path {
+			t.Errorf("got the data volume mounted at %s, want %s", mount.MountPath, path)
+		}
+	}
+}
+
+func TestPrometheusImage(t *testing.T) {
+	mirror, custom := "mirror.example.com/prometheus", "registry.example.com/custom/prometheus"
+	digest := "sha256:" + strings.Repeat("a", 64)
+	tests := []struct {
+		name          string
+		operator      string
+		image, pinned *string
+		want          string
+	}{
+		{name: "default", want: DefaultPrometheusImage + ":v2.40.0"},
+		{name: "operator mirror", operator: mirror, want: mirror + ":v2.40.0"},
+		{name: "spec image", operator: mirror, image: &custom, want: custom + ":v2.40.0"},
+		{name: "digest", image: &custom, pinned: &digest, want: custom + "@" + digest},
+	}
+	for _, tt := range tests {
+		cr := newTestPrometheus("image")
+		cr.Spec.Image = tt.image
+		cr.Spec.ImageDigest = tt.pinned
+		r := &PrometheusReconciler{PrometheusImage: tt.operator}
+		if got := r.prometheusImage(cr); got != tt.want {
+			t.Errorf("%s: got image %s, want %s", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestPodTemplateImages(t *testing.T) {
+	cr := newTestPrometheus("images")
+	cr.Spec.ImagePullSecrets = []corev1.LocalObjectReference{{Name: "registry"}}
+	r := newTestReconciler(t)
+	r.ReloaderImage = "mirror.example.com/prometheus-config-reloader:v0.55.0"
+
+	template, err := r.podTemplateForPrometheus(cr, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !equality.Semantic.DeepEqual(template.Spec.ImagePullSecrets, cr.Spec.ImagePullSecrets) {
+		t.Errorf("got image pull secrets %+v, want %+v", template.Spec.ImagePullSecrets, cr.Spec.ImagePullSecrets)
+	}
+	for _, c := range append(template.Spec.InitContainers, *containerNamed(t, &template.Spec, "config-reloader")) {
+		if c.Image != r.ReloaderImage {
+			t.Errorf("container %s: got image %s, want %s", c.Name, c.Image, r.ReloaderImage)
+		}
+	}
+}
+
+func TestImagePullPolicy(t *testing.T) {
+	cr := newTestPrometheus("pull")
+	cr.Spec.ImagePullPolicy = corev1.PullAlways
+	cr.Spec.Containers = []corev1.Container{
+		{Name: "prometheus", ImagePullPolicy: corev1.PullNever},
+		{Name: "proxy", Image: "proxy:v1"},
+		{Name: "cache", Image: "cache:v1", ImagePullPolicy: corev1.PullIfNotPresent},
+	}
+
+	template, err := newTestReconciler(t).podTemplateForPrometheus(cr, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, want := range map[string]corev1.PullPolicy{
+		"prometheus":      corev1.PullNever,
+		"config-reloader": corev1.PullAlways,
+		"proxy":           corev1.PullAlways,
+		"cache":           corev1.PullIfNotPresent,
+	} {
+		if got := containerNamed(t, &template.Spec, name).ImagePullPolicy; got != want {
+			t.Errorf("container %s: got pull policy %s, want %s", name, got, want)
+		}
+	}
+	for _, c := range template.Spec.InitContainers {
+		if c.ImagePullPolicy != corev1.PullAlways {
+			t.Errorf("init container %s: got pull policy %s, want %s", c.Name, c.ImagePullPolicy, corev1.PullAlways)
+		}
+	}
+}
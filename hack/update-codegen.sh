@@ -0,0 +1,55 @@
+#!/usr/bin/env bash
+
+# Generates the typed clientset, listers, informers and apply configurations
+# of the monitoring.mroque/v1alpha1 API in pkg/client. The generators are
+# looked up in BIN_DIR, see the generate-client target of the Makefile.
+
+set -o errexit
+set -o nounset
+set -o pipefail
+
+ROOT=$(cd "$(dirname "${BASH_SOURCE[0]}")/.." && pwd)
+BIN_DIR=${BIN_DIR:-${ROOT}/bin}
+MODULE=github.com/marieroque/best-prometheus-operator-in-the-world
+APIS=${MODULE}/api/monitoring/v1alpha1
+OUTPUT=${MODULE}/pkg/client
+HEADER=${ROOT}/hack/boilerplate.go.txt
+
+# The generators write under a GOPATH-like tree, the result is copied back
+OUTPUT_BASE=$(mktemp -d)
+trap 'rm -rf "${OUTPUT_BASE}"' EXIT
+
+cd "${ROOT}"
+
+"${BIN_DIR}/applyconfiguration-gen" \
+  --go-header-file "${HEADER}" \
+  --input-dirs "${APIS}" \
+  --output-package "${OUTPUT}/applyconfiguration" \
+  --output-base "${OUTPUT_BASE}"
+
+"${BIN_DIR}/client-gen" \
+  --go-header-file "${HEADER}" \
+  --clientset-name versioned \
+  --input-base "${MODULE}/api" \
+  --input "monitoring/v1alpha1" \
+  --apply-configuration-package "${OUTPUT}/applyconfiguration" \
+  --output-package "${OUTPUT}/clientset" \
+  --output-base "${OUTPUT_BASE}"
+
+"${BIN_DIR}/lister-gen" \
+  --go-header-file "${HEADER}" \
+  --input-dirs "${APIS}" \
+  --output-package "${OUTPUT}/listers" \
+  --output-base "${OUTPUT_BASE}"
+
+"${BIN_DIR}/informer-gen" \
+  --go-header-file "${HEADER}" \
+  --input-dirs "${APIS}" \
+  --versioned-clientset-package "${OUTPUT}/clientset/versioned" \
+  --listers-package "${OUTPUT}/listers" \
+  --output-package "${OUTPUT}/informers" \
+  --output-base "${OUTPUT_BASE}"
+
+rm -rf "${ROOT}/pkg/client"
+mkdir -p "${ROOT}/pkg"
+cp -r "${OUTPUT_BASE}/${OUTPUT}" "${ROOT}/pkg/client"
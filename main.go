@@ -19,6 +19,7 @@ package main
 import (
 	"flag"
 	"os"
+	"strings"
 
 	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
 	// to ensure that exec-entrypoint and run can make use of them.
@@ -28,10 +29,12 @@ import (
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 
-	monitoringv1alpha1 "github.com/marieroque/best-prometheus-operator-in-the-world/api/v1alpha1"
+	monitoringv1alpha1 "github.com/marieroque/best-prometheus-operator-in-the-world/api/monitoring/v1alpha1"
+	monitoringv1beta1 "github.com/marieroque/best-prometheus-operator-in-the-world/api/monitoring/v1beta1"
 	"github.com/marieroque/best-prometheus-operator-in-the-world/controllers"
 	//+kubebuilder:scaffold:imports
 )
@@ -45,18 +48,44 @@ func init() {
 	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
 
 	utilruntime.Must(monitoringv1alpha1.AddToScheme(scheme))
+	utilruntime.Must(monitoringv1beta1.AddToScheme(scheme))
 	//+kubebuilder:scaffold:scheme
 }
 
 func main() {
 	var metricsAddr string
 	var enableLeaderElection bool
+	var dryRun bool
 	var probeAddr string
+	var namespaces string
+	var denyNamespaces string
+	var prometheusImage, reloaderImage, thanosImage, blackboxExporterImage string
 	flag.StringVar(&metricsAddr, "metrics-bind-address", ":8080", "The address the metric endpoint binds to.")
 	flag.StringVar(&probeAddr, "health-probe-bind-address", ":8081", "The address the probe endpoint binds to.")
 	flag.BoolVar(&enableLeaderElection, "leader-elect", false,
 		"Enable leader election for controller manager. "+
 			"Enabling this will ensure there is only one active controller manager.")
+	flag.StringVar(&namespaces, "namespaces", "",
+		"Comma-separated list of namespaces to watch. All namespaces are watched when empty. "+
+			"When set, the operator doesn't manage cluster-scoped objects and Prometheus only discovers "+
+			"targets in its own namespace.")
+	flag.StringVar(&denyNamespaces, "deny-namespaces", "",
+		"Comma-separated list of namespaces whose Prometheus resources are ignored.")
+	flag.StringVar(&prometheusImage, "prometheus-image", envOrDefault("PROMETHEUS_IMAGE", controllers.DefaultPrometheusImage),
+		"Repository of the Prometheus image, tagged with the version of each Prometheus. "+
+			"Can also be set with the PROMETHEUS_IMAGE environment variable.")
+	flag.StringVar(&reloaderImage, "config-reloader-image", envOrDefault("CONFIG_RELOADER_IMAGE", controllers.DefaultReloaderImage),
+		"Image of the config reloader sidecar. "+
+			"Can also be set with the CONFIG_RELOADER_IMAGE environment variable.")
+	flag.StringVar(&thanosImage, "thanos-image", envOrDefault("THANOS_IMAGE", controllers.DefaultThanosImage),
+		"Repository of the Thanos image, tagged with the Thanos version of each Prometheus. "+
+			"Can also be set with the THANOS_IMAGE environment variable.")
+	flag.StringVar(&blackboxExporterImage, "blackbox-exporter-image", envOrDefault("BLACKBOX_EXPORTER_IMAGE", controllers.DefaultBlackboxExporterImage),
+		"Image of the blackbox exporter checking the Probes. "+
+			"Can also be set with the BLACKBOX_EXPORTER_IMAGE environment variable.")
+	flag.BoolVar(&dryRun, "dry-run", false,
+		"Compute the objects of each Prometheus without writing them. The changes the operator would make "+
+			"are logged and set in the "+monitoringv1alpha1.DryRunChangesAnnotation+" annotation of the Prometheus.")
 	opts := zap.Options{
 		Development: true,
 	}
@@ -65,26 +94,59 @@ func main() {
 
 	ctrl.SetLogger(zap.New(zap.UseFlagOptions(&opts)))
 
-	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
+	options := ctrl.Options{
 		Scheme:                 scheme,
 		MetricsBindAddress:     metricsAddr,
 		Port:                   9443,
 		HealthProbeBindAddress: probeAddr,
 		LeaderElection:         enableLeaderElection,
 		LeaderElectionID:       "08aa623c.mroque",
-	})
+	}
+
+	watchNamespaces := splitList(namespaces)
+	switch len(watchNamespaces) {
+	case 0:
+		setupLog.Info("watching all namespaces")
+	case 1:
+		setupLog.Info("watching a single namespace", "namespace", watchNamespaces[0])
+		options.Namespace = watchNamespaces[0]
+	default:
+		setupLog.Info("watching multiple namespaces", "namespaces", watchNamespaces)
+		options.NewCache = cache.MultiNamespacedCacheBuilder(watchNamespaces)
+	}
+
+	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), options)
 	if err != nil {
 		setupLog.Error(err, "unable to start manager")
 		os.Exit(1)
 	}
 
 	if err = (&controllers.PrometheusReconciler{
-		Client: mgr.GetClient(),
-		Scheme: mgr.GetScheme(),
+		Client:                mgr.GetClient(),
+		Scheme:                mgr.GetScheme(),
+		NamespaceScoped:       len(watchNamespaces) > 0,
+		DenyNamespaces:        splitList(denyNamespaces),
+		PrometheusImage:       prometheusImage,
+		ReloaderImage:         reloaderImage,
+		ThanosImage:           thanosImage,
+		BlackboxExporterImage: blackboxExporterImage,
+		DryRun:                dryRun,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "Prometheus")
 		os.Exit(1)
 	}
+	// Webhooks can be disabled with ENABLE_WEBHOOKS=false when running the
+	// manager locally without serving certificates.
+	if os.Getenv("ENABLE_WEBHOOKS") != "false" {
+		if err = (&monitoringv1alpha1.Prometheus{}).SetupWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "Prometheus")
+			os.Exit(1)
+		}
+		if err = (&monitoringv1beta1.Prometheus{}).SetupWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "Prometheus")
+			os.Exit(1)
+		}
+	}
 	//+kubebuilder:scaffold:builder
 
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
@@ -102,3 +164,23 @@ func main() {
 		os.Exit(1)
 	}
 }
+
+// splitList splits a comma-separated flag value, ignoring empty items.
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// envOrDefault returns the value of an environment variable, or the given
+// default when it is unset.
+func envOrDefault(key, def string) string {
+	if value, ok := os.LookupEnv(key); ok {
+		return value
+	}
+	return def
+}
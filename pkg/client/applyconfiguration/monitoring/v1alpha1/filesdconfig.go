@@ -0,0 +1,58 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+// Code generated by applyconfiguration-gen. DO NOT EDIT.
+
+package v1alpha1
+
+// FileSDConfigApplyConfiguration represents an declarative configuration of the FileSDConfig type for use
+// with apply.
+type FileSDConfigApplyConfiguration struct {
+	ConfigMap       *string  `json:"configMap,omitempty"`
+	Keys            []string `json:"keys,omitempty"`
+	RefreshInterval *string  `json:"refresh_interval,omitempty"`
+}
+
+// FileSDConfigApplyConfiguration constructs an declarative configuration of the FileSDConfig type for use with
+// apply.
+func FileSDConfig() *FileSDConfigApplyConfiguration {
+	return &FileSDConfigApplyConfiguration{}
+}
+
+// WithConfigMap sets the ConfigMap field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the ConfigMap field is set to the value of the last call.
+func (b *FileSDConfigApplyConfiguration) WithConfigMap(value string) *FileSDConfigApplyConfiguration {
+	b.ConfigMap = &value
+	return b
+}
+
+// WithKeys adds the given value to the Keys field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, values provided by each call will be appended to the Keys field.
+func (b *FileSDConfigApplyConfiguration) WithKeys(values ...string) *FileSDConfigApplyConfiguration {
+	for i := range values {
+		b.Keys = append(b.Keys, values[i])
+	}
+	return b
+}
+
+// WithRefreshInterval sets the RefreshInterval field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the RefreshInterval field is set to the value of the last call.
+func (b *FileSDConfigApplyConfiguration) WithRefreshInterval(value string) *FileSDConfigApplyConfiguration {
+	b.RefreshInterval = &value
+	return b
+}
@@ -0,0 +1,67 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+// Code generated by applyconfiguration-gen. DO NOT EDIT.
+
+package v1alpha1
+
+// HTTPProbeApplyConfiguration represents an declarative configuration of the HTTPProbe type for use
+// with apply.
+type HTTPProbeApplyConfiguration struct {
+	Method              *string `json:"method,omitempty"`
+	ValidStatusCodes    []int32 `json:"valid_status_codes,omitempty"`
+	FollowRedirects     *bool   `json:"follow_redirects,omitempty"`
+	PreferredIPProtocol *string `json:"preferred_ip_protocol,omitempty"`
+}
+
+// HTTPProbeApplyConfiguration constructs an declarative configuration of the HTTPProbe type for use with
+// apply.
+func HTTPProbe() *HTTPProbeApplyConfiguration {
+	return &HTTPProbeApplyConfiguration{}
+}
+
+// WithMethod sets the Method field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Method field is set to the value of the last call.
+func (b *HTTPProbeApplyConfiguration) WithMethod(value string) *HTTPProbeApplyConfiguration {
+	b.Method = &value
+	return b
+}
+
+// WithValidStatusCodes adds the given value to the ValidStatusCodes field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, values provided by each call will be appended to the ValidStatusCodes field.
+func (b *HTTPProbeApplyConfiguration) WithValidStatusCodes(values ...int32) *HTTPProbeApplyConfiguration {
+	for i := range values {
+		b.ValidStatusCodes = append(b.ValidStatusCodes, values[i])
+	}
+	return b
+}
+
+// WithFollowRedirects sets the FollowRedirects field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the FollowRedirects field is set to the value of the last call.
+func (b *HTTPProbeApplyConfiguration) WithFollowRedirects(value bool) *HTTPProbeApplyConfiguration {
+	b.FollowRedirects = &value
+	return b
+}
+
+// WithPreferredIPProtocol sets the PreferredIPProtocol field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the PreferredIPProtocol field is set to the value of the last call.
+func (b *HTTPProbeApplyConfiguration) WithPreferredIPProtocol(value string) *HTTPProbeApplyConfiguration {
+	b.PreferredIPProtocol = &value
+	return b
+}
@@ -0,0 +1,47 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+// Code generated by applyconfiguration-gen. DO NOT EDIT.
+
+package v1alpha1
+
+// HTTPSDConfigApplyConfiguration represents an declarative configuration of the HTTPSDConfig type for use
+// with apply.
+type HTTPSDConfigApplyConfiguration struct {
+	URL             *string `json:"url,omitempty"`
+	RefreshInterval *string `json:"refresh_interval,omitempty"`
+}
+
+// HTTPSDConfigApplyConfiguration constructs an declarative configuration of the HTTPSDConfig type for use with
+// apply.
+func HTTPSDConfig() *HTTPSDConfigApplyConfiguration {
+	return &HTTPSDConfigApplyConfiguration{}
+}
+
+// WithURL sets the URL field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the URL field is set to the value of the last call.
+func (b *HTTPSDConfigApplyConfiguration) WithURL(value string) *HTTPSDConfigApplyConfiguration {
+	b.URL = &value
+	return b
+}
+
+// WithRefreshInterval sets the RefreshInterval field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the RefreshInterval field is set to the value of the last call.
+func (b *HTTPSDConfigApplyConfiguration) WithRefreshInterval(value string) *HTTPSDConfigApplyConfiguration {
+	b.RefreshInterval = &value
+	return b
+}
@@ -0,0 +1,65 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+// Code generated by applyconfiguration-gen. DO NOT EDIT.
+
+package v1alpha1
+
+import (
+	monitoringv1alpha1 "github.com/marieroque/best-prometheus-operator-in-the-world/api/monitoring/v1alpha1"
+)
+
+// K8SSDConfigApplyConfiguration represents an declarative configuration of the K8SSDConfig type for use
+// with apply.
+type K8SSDConfigApplyConfiguration struct {
+	Role       *string                             `json:"role,omitempty"`
+	Namespaces *K8SSDNamespacesApplyConfiguration  `json:"namespaces,omitempty"`
+	Selectors  []*monitoringv1alpha1.K8SSDSelector `json:"selectors,omitempty"`
+}
+
+// K8SSDConfigApplyConfiguration constructs an declarative configuration of the K8SSDConfig type for use with
+// apply.
+func K8SSDConfig() *K8SSDConfigApplyConfiguration {
+	return &K8SSDConfigApplyConfiguration{}
+}
+
+// WithRole sets the Role field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Role field is set to the value of the last call.
+func (b *K8SSDConfigApplyConfiguration) WithRole(value string) *K8SSDConfigApplyConfiguration {
+	b.Role = &value
+	return b
+}
+
+// WithNamespaces sets the Namespaces field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Namespaces field is set to the value of the last call.
+func (b *K8SSDConfigApplyConfiguration) WithNamespaces(value *K8SSDNamespacesApplyConfiguration) *K8SSDConfigApplyConfiguration {
+	b.Namespaces = value
+	return b
+}
+
+// WithSelectors adds the given value to the Selectors field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, values provided by each call will be appended to the Selectors field.
+func (b *K8SSDConfigApplyConfiguration) WithSelectors(values ...**monitoringv1alpha1.K8SSDSelector) *K8SSDConfigApplyConfiguration {
+	for i := range values {
+		if values[i] == nil {
+			panic("nil value passed to WithSelectors")
+		}
+		b.Selectors = append(b.Selectors, *values[i])
+	}
+	return b
+}
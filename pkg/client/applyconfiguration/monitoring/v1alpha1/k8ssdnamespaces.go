@@ -0,0 +1,40 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+// Code generated by applyconfiguration-gen. DO NOT EDIT.
+
+package v1alpha1
+
+// K8SSDNamespacesApplyConfiguration represents an declarative configuration of the K8SSDNamespaces type for use
+// with apply.
+type K8SSDNamespacesApplyConfiguration struct {
+	Names []string `json:"names,omitempty"`
+}
+
+// K8SSDNamespacesApplyConfiguration constructs an declarative configuration of the K8SSDNamespaces type for use with
+// apply.
+func K8SSDNamespaces() *K8SSDNamespacesApplyConfiguration {
+	return &K8SSDNamespacesApplyConfiguration{}
+}
+
+// WithNames adds the given value to the Names field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, values provided by each call will be appended to the Names field.
+func (b *K8SSDNamespacesApplyConfiguration) WithNames(values ...string) *K8SSDNamespacesApplyConfiguration {
+	for i := range values {
+		b.Names = append(b.Names, values[i])
+	}
+	return b
+}
@@ -0,0 +1,56 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+// Code generated by applyconfiguration-gen. DO NOT EDIT.
+
+package v1alpha1
+
+// K8SSDSelectorApplyConfiguration represents an declarative configuration of the K8SSDSelector type for use
+// with apply.
+type K8SSDSelectorApplyConfiguration struct {
+	Role  *string `json:"role,omitempty"`
+	Label *string `json:"label,omitempty"`
+	Field *string `json:"field,omitempty"`
+}
+
+// K8SSDSelectorApplyConfiguration constructs an declarative configuration of the K8SSDSelector type for use with
+// apply.
+func K8SSDSelector() *K8SSDSelectorApplyConfiguration {
+	return &K8SSDSelectorApplyConfiguration{}
+}
+
+// WithRole sets the Role field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Role field is set to the value of the last call.
+func (b *K8SSDSelectorApplyConfiguration) WithRole(value string) *K8SSDSelectorApplyConfiguration {
+	b.Role = &value
+	return b
+}
+
+// WithLabel sets the Label field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Label field is set to the value of the last call.
+func (b *K8SSDSelectorApplyConfiguration) WithLabel(value string) *K8SSDSelectorApplyConfiguration {
+	b.Label = &value
+	return b
+}
+
+// WithField sets the Field field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Field field is set to the value of the last call.
+func (b *K8SSDSelectorApplyConfiguration) WithField(value string) *K8SSDSelectorApplyConfiguration {
+	b.Field = &value
+	return b
+}
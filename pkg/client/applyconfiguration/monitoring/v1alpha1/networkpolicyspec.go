@@ -0,0 +1,66 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+// Code generated by applyconfiguration-gen. DO NOT EDIT.
+
+package v1alpha1
+
+import (
+	v1 "k8s.io/api/networking/v1"
+)
+
+// NetworkPolicySpecApplyConfiguration represents an declarative configuration of the NetworkPolicySpec type for use
+// with apply.
+type NetworkPolicySpecApplyConfiguration struct {
+	From           []v1.NetworkPolicyPeer       `json:"from,omitempty"`
+	Egress         []v1.NetworkPolicyEgressRule `json:"egress,omitempty"`
+	APIServerCIDRs []string                     `json:"apiServerCIDRs,omitempty"`
+}
+
+// NetworkPolicySpecApplyConfiguration constructs an declarative configuration of the NetworkPolicySpec type for use with
+// apply.
+func NetworkPolicySpec() *NetworkPolicySpecApplyConfiguration {
+	return &NetworkPolicySpecApplyConfiguration{}
+}
+
+// WithFrom adds the given value to the From field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, values provided by each call will be appended to the From field.
+func (b *NetworkPolicySpecApplyConfiguration) WithFrom(values ...v1.NetworkPolicyPeer) *NetworkPolicySpecApplyConfiguration {
+	for i := range values {
+		b.From = append(b.From, values[i])
+	}
+	return b
+}
+
+// WithEgress adds the given value to the Egress field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, values provided by each call will be appended to the Egress field.
+func (b *NetworkPolicySpecApplyConfiguration) WithEgress(values ...v1.NetworkPolicyEgressRule) *NetworkPolicySpecApplyConfiguration {
+	for i := range values {
+		b.Egress = append(b.Egress, values[i])
+	}
+	return b
+}
+
+// WithAPIServerCIDRs adds the given value to the APIServerCIDRs field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, values provided by each call will be appended to the APIServerCIDRs field.
+func (b *NetworkPolicySpecApplyConfiguration) WithAPIServerCIDRs(values ...string) *NetworkPolicySpecApplyConfiguration {
+	for i := range values {
+		b.APIServerCIDRs = append(b.APIServerCIDRs, values[i])
+	}
+	return b
+}
@@ -0,0 +1,65 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+// Code generated by applyconfiguration-gen. DO NOT EDIT.
+
+package v1alpha1
+
+// ProbeConfigApplyConfiguration represents an declarative configuration of the ProbeConfig type for use
+// with apply.
+type ProbeConfigApplyConfiguration struct {
+	InitialDelaySeconds *int32 `json:"initialDelaySeconds,omitempty"`
+	PeriodSeconds       *int32 `json:"periodSeconds,omitempty"`
+	TimeoutSeconds      *int32 `json:"timeoutSeconds,omitempty"`
+	FailureThreshold    *int32 `json:"failureThreshold,omitempty"`
+}
+
+// ProbeConfigApplyConfiguration constructs an declarative configuration of the ProbeConfig type for use with
+// apply.
+func ProbeConfig() *ProbeConfigApplyConfiguration {
+	return &ProbeConfigApplyConfiguration{}
+}
+
+// WithInitialDelaySeconds sets the InitialDelaySeconds field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the InitialDelaySeconds field is set to the value of the last call.
+func (b *ProbeConfigApplyConfiguration) WithInitialDelaySeconds(value int32) *ProbeConfigApplyConfiguration {
+	b.InitialDelaySeconds = &value
+	return b
+}
+
+// WithPeriodSeconds sets the PeriodSeconds field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the PeriodSeconds field is set to the value of the last call.
+func (b *ProbeConfigApplyConfiguration) WithPeriodSeconds(value int32) *ProbeConfigApplyConfiguration {
+	b.PeriodSeconds = &value
+	return b
+}
+
+// WithTimeoutSeconds sets the TimeoutSeconds field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the TimeoutSeconds field is set to the value of the last call.
+func (b *ProbeConfigApplyConfiguration) WithTimeoutSeconds(value int32) *ProbeConfigApplyConfiguration {
+	b.TimeoutSeconds = &value
+	return b
+}
+
+// WithFailureThreshold sets the FailureThreshold field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the FailureThreshold field is set to the value of the last call.
+func (b *ProbeConfigApplyConfiguration) WithFailureThreshold(value int32) *ProbeConfigApplyConfiguration {
+	b.FailureThreshold = &value
+	return b
+}
@@ -0,0 +1,74 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+// Code generated by applyconfiguration-gen. DO NOT EDIT.
+
+package v1alpha1
+
+// ProbeModuleApplyConfiguration represents an declarative configuration of the ProbeModule type for use
+// with apply.
+type ProbeModuleApplyConfiguration struct {
+	Prober  *string                      `json:"prober,omitempty"`
+	Timeout *string                      `json:"timeout,omitempty"`
+	HTTP    *HTTPProbeApplyConfiguration `json:"http,omitempty"`
+	TCP     *TCPProbeApplyConfiguration  `json:"tcp,omitempty"`
+	ICMP    *ICMPProbeApplyConfiguration `json:"icmp,omitempty"`
+}
+
+// ProbeModuleApplyConfiguration constructs an declarative configuration of the ProbeModule type for use with
+// apply.
+func ProbeModule() *ProbeModuleApplyConfiguration {
+	return &ProbeModuleApplyConfiguration{}
+}
+
+// WithProber sets the Prober field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Prober field is set to the value of the last call.
+func (b *ProbeModuleApplyConfiguration) WithProber(value string) *ProbeModuleApplyConfiguration {
+	b.Prober = &value
+	return b
+}
+
+// WithTimeout sets the Timeout field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Timeout field is set to the value of the last call.
+func (b *ProbeModuleApplyConfiguration) WithTimeout(value string) *ProbeModuleApplyConfiguration {
+	b.Timeout = &value
+	return b
+}
+
+// WithHTTP sets the HTTP field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the HTTP field is set to the value of the last call.
+func (b *ProbeModuleApplyConfiguration) WithHTTP(value *HTTPProbeApplyConfiguration) *ProbeModuleApplyConfiguration {
+	b.HTTP = value
+	return b
+}
+
+// WithTCP sets the TCP field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the TCP field is set to the value of the last call.
+func (b *ProbeModuleApplyConfiguration) WithTCP(value *TCPProbeApplyConfiguration) *ProbeModuleApplyConfiguration {
+	b.TCP = value
+	return b
+}
+
+// WithICMP sets the ICMP field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the ICMP field is set to the value of the last call.
+func (b *ProbeModuleApplyConfiguration) WithICMP(value *ICMPProbeApplyConfiguration) *ProbeModuleApplyConfiguration {
+	b.ICMP = value
+	return b
+}
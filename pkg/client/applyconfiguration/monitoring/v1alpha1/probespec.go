@@ -0,0 +1,82 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+// Code generated by applyconfiguration-gen. DO NOT EDIT.
+
+package v1alpha1
+
+import (
+	monitoringv1alpha1 "github.com/marieroque/best-prometheus-operator-in-the-world/api/monitoring/v1alpha1"
+)
+
+// ProbeSpecApplyConfiguration represents an declarative configuration of the ProbeSpec type for use
+// with apply.
+type ProbeSpecApplyConfiguration struct {
+	Module         *ProbeModuleApplyConfiguration      `json:"module,omitempty"`
+	Targets        []string                            `json:"targets,omitempty"`
+	Labels         map[string]string                   `json:"labels,omitempty"`
+	RelabelConfigs []*monitoringv1alpha1.RelabelConfig `json:"relabel_configs,omitempty"`
+}
+
+// ProbeSpecApplyConfiguration constructs an declarative configuration of the ProbeSpec type for use with
+// apply.
+func ProbeSpec() *ProbeSpecApplyConfiguration {
+	return &ProbeSpecApplyConfiguration{}
+}
+
+// WithModule sets the Module field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Module field is set to the value of the last call.
+func (b *ProbeSpecApplyConfiguration) WithModule(value *ProbeModuleApplyConfiguration) *ProbeSpecApplyConfiguration {
+	b.Module = value
+	return b
+}
+
+// WithTargets adds the given value to the Targets field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, values provided by each call will be appended to the Targets field.
+func (b *ProbeSpecApplyConfiguration) WithTargets(values ...string) *ProbeSpecApplyConfiguration {
+	for i := range values {
+		b.Targets = append(b.Targets, values[i])
+	}
+	return b
+}
+
+// WithLabels puts the entries into the Labels field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, the entries provided by each call will be put on the Labels field,
+// overwriting an existing map entries in Labels field with the same key.
+func (b *ProbeSpecApplyConfiguration) WithLabels(entries map[string]string) *ProbeSpecApplyConfiguration {
+	if b.Labels == nil && len(entries) > 0 {
+		b.Labels = make(map[string]string, len(entries))
+	}
+	for k, v := range entries {
+		b.Labels[k] = v
+	}
+	return b
+}
+
+// WithRelabelConfigs adds the given value to the RelabelConfigs field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, values provided by each call will be appended to the RelabelConfigs field.
+func (b *ProbeSpecApplyConfiguration) WithRelabelConfigs(values ...**monitoringv1alpha1.RelabelConfig) *ProbeSpecApplyConfiguration {
+	for i := range values {
+		if values[i] == nil {
+			panic("nil value passed to WithRelabelConfigs")
+		}
+		b.RelabelConfigs = append(b.RelabelConfigs, *values[i])
+	}
+	return b
+}
@@ -0,0 +1,56 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+// Code generated by applyconfiguration-gen. DO NOT EDIT.
+
+package v1alpha1
+
+// ProbesSpecApplyConfiguration represents an declarative configuration of the ProbesSpec type for use
+// with apply.
+type ProbesSpecApplyConfiguration struct {
+	Liveness  *ProbeConfigApplyConfiguration `json:"liveness,omitempty"`
+	Readiness *ProbeConfigApplyConfiguration `json:"readiness,omitempty"`
+	Startup   *ProbeConfigApplyConfiguration `json:"startup,omitempty"`
+}
+
+// ProbesSpecApplyConfiguration constructs an declarative configuration of the ProbesSpec type for use with
+// apply.
+func ProbesSpec() *ProbesSpecApplyConfiguration {
+	return &ProbesSpecApplyConfiguration{}
+}
+
+// WithLiveness sets the Liveness field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Liveness field is set to the value of the last call.
+func (b *ProbesSpecApplyConfiguration) WithLiveness(value *ProbeConfigApplyConfiguration) *ProbesSpecApplyConfiguration {
+	b.Liveness = value
+	return b
+}
+
+// WithReadiness sets the Readiness field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Readiness field is set to the value of the last call.
+func (b *ProbesSpecApplyConfiguration) WithReadiness(value *ProbeConfigApplyConfiguration) *ProbesSpecApplyConfiguration {
+	b.Readiness = value
+	return b
+}
+
+// WithStartup sets the Startup field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Startup field is set to the value of the last call.
+func (b *ProbesSpecApplyConfiguration) WithStartup(value *ProbeConfigApplyConfiguration) *ProbesSpecApplyConfiguration {
+	b.Startup = value
+	return b
+}
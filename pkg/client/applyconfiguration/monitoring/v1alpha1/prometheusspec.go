@@ -0,0 +1,331 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+// Code generated by applyconfiguration-gen. DO NOT EDIT.
+
+package v1alpha1
+
+import (
+	v1alpha1 "github.com/marieroque/best-prometheus-operator-in-the-world/api/monitoring/v1alpha1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// PrometheusSpecApplyConfiguration represents an declarative configuration of the PrometheusSpec type for use
+// with apply.
+type PrometheusSpecApplyConfiguration struct {
+	Version                       *string                              `json:"version,omitempty"`
+	Image                         *string                              `json:"image,omitempty"`
+	ImageDigest                   *string                              `json:"imageDigest,omitempty"`
+	ImagePullPolicy               *v1.PullPolicy                       `json:"imagePullPolicy,omitempty"`
+	ImagePullSecrets              []v1.LocalObjectReference            `json:"imagePullSecrets,omitempty"`
+	ScrapeConfigs                 []*v1alpha1.ScrapeConfigSpec         `json:"scrape_configs,omitempty"`
+	Presets                       []v1alpha1.ScrapePreset              `json:"presets,omitempty"`
+	Paused                        *bool                                `json:"paused,omitempty"`
+	Shards                        *int32                               `json:"shards,omitempty"`
+	Mode                          *string                              `json:"mode,omitempty"`
+	DaemonSet                     *bool                                `json:"daemonSet,omitempty"`
+	RemoteWrite                   []*v1alpha1.RemoteWriteConfig        `json:"remote_write,omitempty"`
+	Thanos                        *ThanosSpecApplyConfiguration        `json:"thanos,omitempty"`
+	TSDB                          *TSDBSpecApplyConfiguration          `json:"tsdb,omitempty"`
+	Web                           *WebSpecApplyConfiguration           `json:"web,omitempty"`
+	Ingress                       *IngressSpecApplyConfiguration       `json:"ingress,omitempty"`
+	Probes                        *ProbesSpecApplyConfiguration        `json:"probes,omitempty"`
+	Replicas                      *int32                               `json:"replicas,omitempty"`
+	NetworkPolicy                 *NetworkPolicySpecApplyConfiguration `json:"networkPolicy,omitempty"`
+	Containers                    []v1.Container                       `json:"containers,omitempty"`
+	InitContainers                []v1.Container                       `json:"initContainers,omitempty"`
+	Volumes                       []v1.Volume                          `json:"volumes,omitempty"`
+	VolumeMounts                  []v1.VolumeMount                     `json:"volumeMounts,omitempty"`
+	Env                           []v1.EnvVar                          `json:"env,omitempty"`
+	AdditionalArgs                []string                             `json:"additionalArgs,omitempty"`
+	AdditionalScrapeConfigs       *v1.SecretKeySelector                `json:"additionalScrapeConfigs,omitempty"`
+	ScrapeConfigSelector          *metav1.LabelSelector                `json:"scrapeConfigSelector,omitempty"`
+	ScrapeConfigNamespaceSelector *metav1.LabelSelector                `json:"scrapeConfigNamespaceSelector,omitempty"`
+	ProbeSelector                 *metav1.LabelSelector                `json:"probeSelector,omitempty"`
+	ProbeNamespaceSelector        *metav1.LabelSelector                `json:"probeNamespaceSelector,omitempty"`
+}
+
+// PrometheusSpecApplyConfiguration constructs an declarative configuration of the PrometheusSpec type for use with
+// apply.
+func PrometheusSpec() *PrometheusSpecApplyConfiguration {
+	return &PrometheusSpecApplyConfiguration{}
+}
+
+// WithVersion sets the Version field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Version field is set to the value of the last call.
+func (b *PrometheusSpecApplyConfiguration) WithVersion(value string) *PrometheusSpecApplyConfiguration {
+	b.Version = &value
+	return b
+}
+
+// WithImage sets the Image field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Image field is set to the value of the last call.
+func (b *PrometheusSpecApplyConfiguration) WithImage(value string) *PrometheusSpecApplyConfiguration {
+	b.Image = &value
+	return b
+}
+
+// WithImageDigest sets the ImageDigest field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the ImageDigest field is set to the value of the last call.
+func (b *PrometheusSpecApplyConfiguration) WithImageDigest(value string) *PrometheusSpecApplyConfiguration {
+	b.ImageDigest = &value
+	return b
+}
+
+// WithImagePullPolicy sets the ImagePullPolicy field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the ImagePullPolicy field is set to the value of the last call.
+func (b *PrometheusSpecApplyConfiguration) WithImagePullPolicy(value v1.PullPolicy) *PrometheusSpecApplyConfiguration {
+	b.ImagePullPolicy = &value
+	return b
+}
+
+// WithImagePullSecrets adds the given value to the ImagePullSecrets field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, values provided by each call will be appended to the ImagePullSecrets field.
+func (b *PrometheusSpecApplyConfiguration) WithImagePullSecrets(values ...v1.LocalObjectReference) *PrometheusSpecApplyConfiguration {
+	for i := range values {
+		b.ImagePullSecrets = append(b.ImagePullSecrets, values[i])
+	}
+	return b
+}
+
+// WithScrapeConfigs adds the given value to the ScrapeConfigs field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, values provided by each call will be appended to the ScrapeConfigs field.
+func (b *PrometheusSpecApplyConfiguration) WithScrapeConfigs(values ...**v1alpha1.ScrapeConfigSpec) *PrometheusSpecApplyConfiguration {
+	for i := range values {
+		if values[i] == nil {
+			panic("nil value passed to WithScrapeConfigs")
+		}
+		b.ScrapeConfigs = append(b.ScrapeConfigs, *values[i])
+	}
+	return b
+}
+
+// WithPresets adds the given value to the Presets field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, values provided by each call will be appended to the Presets field.
+func (b *PrometheusSpecApplyConfiguration) WithPresets(values ...v1alpha1.ScrapePreset) *PrometheusSpecApplyConfiguration {
+	for i := range values {
+		b.Presets = append(b.Presets, values[i])
+	}
+	return b
+}
+
+// WithPaused sets the Paused field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Paused field is set to the value of the last call.
+func (b *PrometheusSpecApplyConfiguration) WithPaused(value bool) *PrometheusSpecApplyConfiguration {
+	b.Paused = &value
+	return b
+}
+
+// WithShards sets the Shards field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Shards field is set to the value of the last call.
+func (b *PrometheusSpecApplyConfiguration) WithShards(value int32) *PrometheusSpecApplyConfiguration {
+	b.Shards = &value
+	return b
+}
+
+// WithMode sets the Mode field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Mode field is set to the value of the last call.
+func (b *PrometheusSpecApplyConfiguration) WithMode(value string) *PrometheusSpecApplyConfiguration {
+	b.Mode = &value
+	return b
+}
+
+// WithDaemonSet sets the DaemonSet field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the DaemonSet field is set to the value of the last call.
+func (b *PrometheusSpecApplyConfiguration) WithDaemonSet(value bool) *PrometheusSpecApplyConfiguration {
+	b.DaemonSet = &value
+	return b
+}
+
+// WithRemoteWrite adds the given value to the RemoteWrite field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, values provided by each call will be appended to the RemoteWrite field.
+func (b *PrometheusSpecApplyConfiguration) WithRemoteWrite(values ...**v1alpha1.RemoteWriteConfig) *PrometheusSpecApplyConfiguration {
+	for i := range values {
+		if values[i] == nil {
+			panic("nil value passed to WithRemoteWrite")
+		}
+		b.RemoteWrite = append(b.RemoteWrite, *values[i])
+	}
+	return b
+}
+
+// WithThanos sets the Thanos field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Thanos field is set to the value of the last call.
+func (b *PrometheusSpecApplyConfiguration) WithThanos(value *ThanosSpecApplyConfiguration) *PrometheusSpecApplyConfiguration {
+	b.Thanos = value
+	return b
+}
+
+// WithTSDB sets the TSDB field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the TSDB field is set to the value of the last call.
+func (b *PrometheusSpecApplyConfiguration) WithTSDB(value *TSDBSpecApplyConfiguration) *PrometheusSpecApplyConfiguration {
+	b.TSDB = value
+	return b
+}
+
+// WithWeb sets the Web field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Web field is set to the value of the last call.
+func (b *PrometheusSpecApplyConfiguration) WithWeb(value *WebSpecApplyConfiguration) *PrometheusSpecApplyConfiguration {
+	b.Web = value
+	return b
+}
+
+// WithIngress sets the Ingress field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Ingress field is set to the value of the last call.
+func (b *PrometheusSpecApplyConfiguration) WithIngress(value *IngressSpecApplyConfiguration) *PrometheusSpecApplyConfiguration {
+	b.Ingress = value
+	return b
+}
+
+// WithProbes sets the Probes field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Probes field is set to the value of the last call.
+func (b *PrometheusSpecApplyConfiguration) WithProbes(value *ProbesSpecApplyConfiguration) *PrometheusSpecApplyConfiguration {
+	b.Probes = value
+	return b
+}
+
+// WithReplicas sets the Replicas field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Replicas field is set to the value of the last call.
+func (b *PrometheusSpecApplyConfiguration) WithReplicas(value int32) *PrometheusSpecApplyConfiguration {
+	b.Replicas = &value
+	return b
+}
+
+// WithNetworkPolicy sets the NetworkPolicy field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the NetworkPolicy field is set to the value of the last call.
+func (b *PrometheusSpecApplyConfiguration) WithNetworkPolicy(value *NetworkPolicySpecApplyConfiguration) *PrometheusSpecApplyConfiguration {
+	b.NetworkPolicy = value
+	return b
+}
+
+// WithContainers adds the given value to the Containers field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, values provided by each call will be appended to the Containers field.
+func (b *PrometheusSpecApplyConfiguration) WithContainers(values ...v1.Container) *PrometheusSpecApplyConfiguration {
+	for i := range values {
+		b.Containers = append(b.Containers, values[i])
+	}
+	return b
+}
+
+// WithInitContainers adds the given value to the InitContainers field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, values provided by each call will be appended to the InitContainers field.
+func (b *PrometheusSpecApplyConfiguration) WithInitContainers(values ...v1.Container) *PrometheusSpecApplyConfiguration {
+	for i := range values {
+		b.InitContainers = append(b.InitContainers, values[i])
+	}
+	return b
+}
+
+// WithVolumes adds the given value to the Volumes field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, values provided by each call will be appended to the Volumes field.
+func (b *PrometheusSpecApplyConfiguration) WithVolumes(values ...v1.Volume) *PrometheusSpecApplyConfiguration {
+	for i := range values {
+		b.Volumes = append(b.Volumes, values[i])
+	}
+	return b
+}
+
+// WithVolumeMounts adds the given value to the VolumeMounts field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, values provided by each call will be appended to the VolumeMounts field.
+func (b *PrometheusSpecApplyConfiguration) WithVolumeMounts(values ...v1.VolumeMount) *PrometheusSpecApplyConfiguration {
+	for i := range values {
+		b.VolumeMounts = append(b.VolumeMounts, values[i])
+	}
+	return b
+}
+
+// WithEnv adds the given value to the Env field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, values provided by each call will be appended to the Env field.
+func (b *PrometheusSpecApplyConfiguration) WithEnv(values ...v1.EnvVar) *PrometheusSpecApplyConfiguration {
+	for i := range values {
+		b.Env = append(b.Env, values[i])
+	}
+	return b
+}
+
+// WithAdditionalArgs adds the given value to the AdditionalArgs field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, values provided by each call will be appended to the AdditionalArgs field.
+func (b *PrometheusSpecApplyConfiguration) WithAdditionalArgs(values ...string) *PrometheusSpecApplyConfiguration {
+	for i := range values {
+		b.AdditionalArgs = append(b.AdditionalArgs, values[i])
+	}
+	return b
+}
+
+// WithAdditionalScrapeConfigs sets the AdditionalScrapeConfigs field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the AdditionalScrapeConfigs field is set to the value of the last call.
+func (b *PrometheusSpecApplyConfiguration) WithAdditionalScrapeConfigs(value v1.SecretKeySelector) *PrometheusSpecApplyConfiguration {
+	b.AdditionalScrapeConfigs = &value
+	return b
+}
+
+// WithScrapeConfigSelector sets the ScrapeConfigSelector field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the ScrapeConfigSelector field is set to the value of the last call.
+func (b *PrometheusSpecApplyConfiguration) WithScrapeConfigSelector(value metav1.LabelSelector) *PrometheusSpecApplyConfiguration {
+	b.ScrapeConfigSelector = &value
+	return b
+}
+
+// WithScrapeConfigNamespaceSelector sets the ScrapeConfigNamespaceSelector field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the ScrapeConfigNamespaceSelector field is set to the value of the last call.
+func (b *PrometheusSpecApplyConfiguration) WithScrapeConfigNamespaceSelector(value metav1.LabelSelector) *PrometheusSpecApplyConfiguration {
+	b.ScrapeConfigNamespaceSelector = &value
+	return b
+}
+
+// WithProbeSelector sets the ProbeSelector field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the ProbeSelector field is set to the value of the last call.
+func (b *PrometheusSpecApplyConfiguration) WithProbeSelector(value metav1.LabelSelector) *PrometheusSpecApplyConfiguration {
+	b.ProbeSelector = &value
+	return b
+}
+
+// WithProbeNamespaceSelector sets the ProbeNamespaceSelector field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the ProbeNamespaceSelector field is set to the value of the last call.
+func (b *PrometheusSpecApplyConfiguration) WithProbeNamespaceSelector(value metav1.LabelSelector) *PrometheusSpecApplyConfiguration {
+	b.ProbeNamespaceSelector = &value
+	return b
+}
@@ -0,0 +1,112 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+// Code generated by applyconfiguration-gen. DO NOT EDIT.
+
+package v1alpha1
+
+import (
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// PrometheusStatusApplyConfiguration represents an declarative configuration of the PrometheusStatus type for use
+// with apply.
+type PrometheusStatusApplyConfiguration struct {
+	Conditions    []v1.Condition                       `json:"conditions,omitempty"`
+	Shards        []ShardStatusApplyConfiguration      `json:"shards,omitempty"`
+	Version       *string                              `json:"version,omitempty"`
+	ScrapeConfigs *SelectedResourcesApplyConfiguration `json:"scrapeConfigs,omitempty"`
+	Probes        *SelectedResourcesApplyConfiguration `json:"probes,omitempty"`
+	Replicas      *int32                               `json:"replicas,omitempty"`
+	ReadyReplicas *int32                               `json:"readyReplicas,omitempty"`
+	ScrapeJobs    *int32                               `json:"scrapeJobs,omitempty"`
+}
+
+// PrometheusStatusApplyConfiguration constructs an declarative configuration of the PrometheusStatus type for use with
+// apply.
+func PrometheusStatus() *PrometheusStatusApplyConfiguration {
+	return &PrometheusStatusApplyConfiguration{}
+}
+
+// WithConditions adds the given value to the Conditions field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, values provided by each call will be appended to the Conditions field.
+func (b *PrometheusStatusApplyConfiguration) WithConditions(values ...v1.Condition) *PrometheusStatusApplyConfiguration {
+	for i := range values {
+		b.Conditions = append(b.Conditions, values[i])
+	}
+	return b
+}
+
+// WithShards adds the given value to the Shards field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, values provided by each call will be appended to the Shards field.
+func (b *PrometheusStatusApplyConfiguration) WithShards(values ...*ShardStatusApplyConfiguration) *PrometheusStatusApplyConfiguration {
+	for i := range values {
+		if values[i] == nil {
+			panic("nil value passed to WithShards")
+		}
+		b.Shards = append(b.Shards, *values[i])
+	}
+	return b
+}
+
+// WithVersion sets the Version field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Version field is set to the value of the last call.
+func (b *PrometheusStatusApplyConfiguration) WithVersion(value string) *PrometheusStatusApplyConfiguration {
+	b.Version = &value
+	return b
+}
+
+// WithScrapeConfigs sets the ScrapeConfigs field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the ScrapeConfigs field is set to the value of the last call.
+func (b *PrometheusStatusApplyConfiguration) WithScrapeConfigs(value *SelectedResourcesApplyConfiguration) *PrometheusStatusApplyConfiguration {
+	b.ScrapeConfigs = value
+	return b
+}
+
+// WithProbes sets the Probes field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Probes field is set to the value of the last call.
+func (b *PrometheusStatusApplyConfiguration) WithProbes(value *SelectedResourcesApplyConfiguration) *PrometheusStatusApplyConfiguration {
+	b.Probes = value
+	return b
+}
+
+// WithReplicas sets the Replicas field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Replicas field is set to the value of the last call.
+func (b *PrometheusStatusApplyConfiguration) WithReplicas(value int32) *PrometheusStatusApplyConfiguration {
+	b.Replicas = &value
+	return b
+}
+
+// WithReadyReplicas sets the ReadyReplicas field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the ReadyReplicas field is set to the value of the last call.
+func (b *PrometheusStatusApplyConfiguration) WithReadyReplicas(value int32) *PrometheusStatusApplyConfiguration {
+	b.ReadyReplicas = &value
+	return b
+}
+
+// WithScrapeJobs sets the ScrapeJobs field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the ScrapeJobs field is set to the value of the last call.
+func (b *PrometheusStatusApplyConfiguration) WithScrapeJobs(value int32) *PrometheusStatusApplyConfiguration {
+	b.ScrapeJobs = &value
+	return b
+}
@@ -0,0 +1,74 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+// Code generated by applyconfiguration-gen. DO NOT EDIT.
+
+package v1alpha1
+
+import (
+	v1alpha1 "github.com/marieroque/best-prometheus-operator-in-the-world/api/monitoring/v1alpha1"
+)
+
+// RemoteWriteConfigApplyConfiguration represents an declarative configuration of the RemoteWriteConfig type for use
+// with apply.
+type RemoteWriteConfigApplyConfiguration struct {
+	URL                 *string                   `json:"url,omitempty"`
+	Name                *string                   `json:"name,omitempty"`
+	RemoteTimeout       *string                   `json:"remote_timeout,omitempty"`
+	WriteRelabelConfigs []*v1alpha1.RelabelConfig `json:"write_relabel_configs,omitempty"`
+}
+
+// RemoteWriteConfigApplyConfiguration constructs an declarative configuration of the RemoteWriteConfig type for use with
+// apply.
+func RemoteWriteConfig() *RemoteWriteConfigApplyConfiguration {
+	return &RemoteWriteConfigApplyConfiguration{}
+}
+
+// WithURL sets the URL field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the URL field is set to the value of the last call.
+func (b *RemoteWriteConfigApplyConfiguration) WithURL(value string) *RemoteWriteConfigApplyConfiguration {
+	b.URL = &value
+	return b
+}
+
+// WithName sets the Name field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Name field is set to the value of the last call.
+func (b *RemoteWriteConfigApplyConfiguration) WithName(value string) *RemoteWriteConfigApplyConfiguration {
+	b.Name = &value
+	return b
+}
+
+// WithRemoteTimeout sets the RemoteTimeout field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the RemoteTimeout field is set to the value of the last call.
+func (b *RemoteWriteConfigApplyConfiguration) WithRemoteTimeout(value string) *RemoteWriteConfigApplyConfiguration {
+	b.RemoteTimeout = &value
+	return b
+}
+
+// WithWriteRelabelConfigs adds the given value to the WriteRelabelConfigs field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, values provided by each call will be appended to the WriteRelabelConfigs field.
+func (b *RemoteWriteConfigApplyConfiguration) WithWriteRelabelConfigs(values ...**v1alpha1.RelabelConfig) *RemoteWriteConfigApplyConfiguration {
+	for i := range values {
+		if values[i] == nil {
+			panic("nil value passed to WithWriteRelabelConfigs")
+		}
+		b.WriteRelabelConfigs = append(b.WriteRelabelConfigs, *values[i])
+	}
+	return b
+}
@@ -0,0 +1,162 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+// Code generated by applyconfiguration-gen. DO NOT EDIT.
+
+package v1alpha1
+
+import (
+	monitoringv1alpha1 "github.com/marieroque/best-prometheus-operator-in-the-world/api/monitoring/v1alpha1"
+)
+
+// ScrapeConfigSpecApplyConfiguration represents an declarative configuration of the ScrapeConfigSpec type for use
+// with apply.
+type ScrapeConfigSpecApplyConfiguration struct {
+	JobName         *string                             `json:"job_name,omitempty"`
+	MetricsPath     *string                             `json:"metrics_path,omitempty"`
+	Scheme          *string                             `json:"scheme,omitempty"`
+	TLSConfig       *TLSConfigApplyConfiguration        `json:"tls_config,omitempty"`
+	BearerTokenFile *string                             `json:"bearer_token_file,omitempty"`
+	K8SSDConfigs    []*monitoringv1alpha1.K8SSDConfig   `json:"kubernetes_sd_configs,omitempty"`
+	StaticConfigs   []*monitoringv1alpha1.StaticConfig  `json:"static_configs,omitempty"`
+	FileSDConfigs   []*monitoringv1alpha1.FileSDConfig  `json:"file_sd_configs,omitempty"`
+	DNSSDConfigs    []*monitoringv1alpha1.DNSSDConfig   `json:"dns_sd_configs,omitempty"`
+	HTTPSDConfigs   []*monitoringv1alpha1.HTTPSDConfig  `json:"http_sd_configs,omitempty"`
+	RelabelConfigs  []*monitoringv1alpha1.RelabelConfig `json:"relabel_configs,omitempty"`
+}
+
+// ScrapeConfigSpecApplyConfiguration constructs an declarative configuration of the ScrapeConfigSpec type for use with
+// apply.
+func ScrapeConfigSpec() *ScrapeConfigSpecApplyConfiguration {
+	return &ScrapeConfigSpecApplyConfiguration{}
+}
+
+// WithJobName sets the JobName field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the JobName field is set to the value of the last call.
+func (b *ScrapeConfigSpecApplyConfiguration) WithJobName(value string) *ScrapeConfigSpecApplyConfiguration {
+	b.JobName = &value
+	return b
+}
+
+// WithMetricsPath sets the MetricsPath field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the MetricsPath field is set to the value of the last call.
+func (b *ScrapeConfigSpecApplyConfiguration) WithMetricsPath(value string) *ScrapeConfigSpecApplyConfiguration {
+	b.MetricsPath = &value
+	return b
+}
+
+// WithScheme sets the Scheme field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Scheme field is set to the value of the last call.
+func (b *ScrapeConfigSpecApplyConfiguration) WithScheme(value string) *ScrapeConfigSpecApplyConfiguration {
+	b.Scheme = &value
+	return b
+}
+
+// WithTLSConfig sets the TLSConfig field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the TLSConfig field is set to the value of the last call.
+func (b *ScrapeConfigSpecApplyConfiguration) WithTLSConfig(value *TLSConfigApplyConfiguration) *ScrapeConfigSpecApplyConfiguration {
+	b.TLSConfig = value
+	return b
+}
+
+// WithBearerTokenFile sets the BearerTokenFile field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the BearerTokenFile field is set to the value of the last call.
+func (b *ScrapeConfigSpecApplyConfiguration) WithBearerTokenFile(value string) *ScrapeConfigSpecApplyConfiguration {
+	b.BearerTokenFile = &value
+	return b
+}
+
+// WithK8SSDConfigs adds the given value to the K8SSDConfigs field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, values provided by each call will be appended to the K8SSDConfigs field.
+func (b *ScrapeConfigSpecApplyConfiguration) WithK8SSDConfigs(values ...**monitoringv1alpha1.K8SSDConfig) *ScrapeConfigSpecApplyConfiguration {
+	for i := range values {
+		if values[i] == nil {
+			panic("nil value passed to WithK8SSDConfigs")
+		}
+		b.K8SSDConfigs = append(b.K8SSDConfigs, *values[i])
+	}
+	return b
+}
+
+// WithStaticConfigs adds the given value to the StaticConfigs field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, values provided by each call will be appended to the StaticConfigs field.
+func (b *ScrapeConfigSpecApplyConfiguration) WithStaticConfigs(values ...**monitoringv1alpha1.StaticConfig) *ScrapeConfigSpecApplyConfiguration {
+	for i := range values {
+		if values[i] == nil {
+			panic("nil value passed to WithStaticConfigs")
+		}
+		b.StaticConfigs = append(b.StaticConfigs, *values[i])
+	}
+	return b
+}
+
+// WithFileSDConfigs adds the given value to the FileSDConfigs field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, values provided by each call will be appended to the FileSDConfigs field.
+func (b *ScrapeConfigSpecApplyConfiguration) WithFileSDConfigs(values ...**monitoringv1alpha1.FileSDConfig) *ScrapeConfigSpecApplyConfiguration {
+	for i := range values {
+		if values[i] == nil {
+			panic("nil value passed to WithFileSDConfigs")
+		}
+		b.FileSDConfigs = append(b.FileSDConfigs, *values[i])
+	}
+	return b
+}
+
+// WithDNSSDConfigs adds the given value to the DNSSDConfigs field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, values provided by each call will be appended to the DNSSDConfigs field.
+func (b *ScrapeConfigSpecApplyConfiguration) WithDNSSDConfigs(values ...**monitoringv1alpha1.DNSSDConfig) *ScrapeConfigSpecApplyConfiguration {
+	for i := range values {
+		if values[i] == nil {
+			panic("nil value passed to WithDNSSDConfigs")
+		}
+		b.DNSSDConfigs = append(b.DNSSDConfigs, *values[i])
+	}
+	return b
+}
+
+// WithHTTPSDConfigs adds the given value to the HTTPSDConfigs field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, values provided by each call will be appended to the HTTPSDConfigs field.
+func (b *ScrapeConfigSpecApplyConfiguration) WithHTTPSDConfigs(values ...**monitoringv1alpha1.HTTPSDConfig) *ScrapeConfigSpecApplyConfiguration {
+	for i := range values {
+		if values[i] == nil {
+			panic("nil value passed to WithHTTPSDConfigs")
+		}
+		b.HTTPSDConfigs = append(b.HTTPSDConfigs, *values[i])
+	}
+	return b
+}
+
+// WithRelabelConfigs adds the given value to the RelabelConfigs field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, values provided by each call will be appended to the RelabelConfigs field.
+func (b *ScrapeConfigSpecApplyConfiguration) WithRelabelConfigs(values ...**monitoringv1alpha1.RelabelConfig) *ScrapeConfigSpecApplyConfiguration {
+	for i := range values {
+		if values[i] == nil {
+			panic("nil value passed to WithRelabelConfigs")
+		}
+		b.RelabelConfigs = append(b.RelabelConfigs, *values[i])
+	}
+	return b
+}
@@ -0,0 +1,54 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+// Code generated by applyconfiguration-gen. DO NOT EDIT.
+
+package v1alpha1
+
+// SelectedResourcesApplyConfiguration represents an declarative configuration of the SelectedResources type for use
+// with apply.
+type SelectedResourcesApplyConfiguration struct {
+	Selected []string                             `json:"selected,omitempty"`
+	Rejected []RejectedResourceApplyConfiguration `json:"rejected,omitempty"`
+}
+
+// SelectedResourcesApplyConfiguration constructs an declarative configuration of the SelectedResources type for use with
+// apply.
+func SelectedResources() *SelectedResourcesApplyConfiguration {
+	return &SelectedResourcesApplyConfiguration{}
+}
+
+// WithSelected adds the given value to the Selected field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, values provided by each call will be appended to the Selected field.
+func (b *SelectedResourcesApplyConfiguration) WithSelected(values ...string) *SelectedResourcesApplyConfiguration {
+	for i := range values {
+		b.Selected = append(b.Selected, values[i])
+	}
+	return b
+}
+
+// WithRejected adds the given value to the Rejected field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, values provided by each call will be appended to the Rejected field.
+func (b *SelectedResourcesApplyConfiguration) WithRejected(values ...*RejectedResourceApplyConfiguration) *SelectedResourcesApplyConfiguration {
+	for i := range values {
+		if values[i] == nil {
+			panic("nil value passed to WithRejected")
+		}
+		b.Rejected = append(b.Rejected, *values[i])
+	}
+	return b
+}
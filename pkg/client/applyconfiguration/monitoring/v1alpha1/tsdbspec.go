@@ -0,0 +1,92 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+// Code generated by applyconfiguration-gen. DO NOT EDIT.
+
+package v1alpha1
+
+// TSDBSpecApplyConfiguration represents an declarative configuration of the TSDBSpec type for use
+// with apply.
+type TSDBSpecApplyConfiguration struct {
+	RetentionTime        *string `json:"retentionTime,omitempty"`
+	RetentionSize        *string `json:"retentionSize,omitempty"`
+	WALCompression       *bool   `json:"walCompression,omitempty"`
+	OutOfOrderTimeWindow *string `json:"outOfOrderTimeWindow,omitempty"`
+	Path                 *string `json:"path,omitempty"`
+	MinBlockDuration     *string `json:"minBlockDuration,omitempty"`
+	MaxBlockDuration     *string `json:"maxBlockDuration,omitempty"`
+}
+
+// TSDBSpecApplyConfiguration constructs an declarative configuration of the TSDBSpec type for use with
+// apply.
+func TSDBSpec() *TSDBSpecApplyConfiguration {
+	return &TSDBSpecApplyConfiguration{}
+}
+
+// WithRetentionTime sets the RetentionTime field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the RetentionTime field is set to the value of the last call.
+func (b *TSDBSpecApplyConfiguration) WithRetentionTime(value string) *TSDBSpecApplyConfiguration {
+	b.RetentionTime = &value
+	return b
+}
+
+// WithRetentionSize sets the RetentionSize field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the RetentionSize field is set to the value of the last call.
+func (b *TSDBSpecApplyConfiguration) WithRetentionSize(value string) *TSDBSpecApplyConfiguration {
+	b.RetentionSize = &value
+	return b
+}
+
+// WithWALCompression sets the WALCompression field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the WALCompression field is set to the value of the last call.
+func (b *TSDBSpecApplyConfiguration) WithWALCompression(value bool) *TSDBSpecApplyConfiguration {
+	b.WALCompression = &value
+	return b
+}
+
+// WithOutOfOrderTimeWindow sets the OutOfOrderTimeWindow field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the OutOfOrderTimeWindow field is set to the value of the last call.
+func (b *TSDBSpecApplyConfiguration) WithOutOfOrderTimeWindow(value string) *TSDBSpecApplyConfiguration {
+	b.OutOfOrderTimeWindow = &value
+	return b
+}
+
+// WithPath sets the Path field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Path field is set to the value of the last call.
+func (b *TSDBSpecApplyConfiguration) WithPath(value string) *TSDBSpecApplyConfiguration {
+	b.Path = &value
+	return b
+}
+
+// WithMinBlockDuration sets the MinBlockDuration field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the MinBlockDuration field is set to the value of the last call.
+func (b *TSDBSpecApplyConfiguration) WithMinBlockDuration(value string) *TSDBSpecApplyConfiguration {
+	b.MinBlockDuration = &value
+	return b
+}
+
+// WithMaxBlockDuration sets the MaxBlockDuration field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the MaxBlockDuration field is set to the value of the last call.
+func (b *TSDBSpecApplyConfiguration) WithMaxBlockDuration(value string) *TSDBSpecApplyConfiguration {
+	b.MaxBlockDuration = &value
+	return b
+}
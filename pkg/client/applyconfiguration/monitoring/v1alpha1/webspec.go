@@ -0,0 +1,51 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+// Code generated by applyconfiguration-gen. DO NOT EDIT.
+
+package v1alpha1
+
+import (
+	v1 "k8s.io/api/core/v1"
+)
+
+// WebSpecApplyConfiguration represents an declarative configuration of the WebSpec type for use
+// with apply.
+type WebSpecApplyConfiguration struct {
+	TLS            *WebTLSConfigApplyConfiguration `json:"tls,omitempty"`
+	BasicAuthUsers *v1.LocalObjectReference        `json:"basicAuthUsers,omitempty"`
+}
+
+// WebSpecApplyConfiguration constructs an declarative configuration of the WebSpec type for use with
+// apply.
+func WebSpec() *WebSpecApplyConfiguration {
+	return &WebSpecApplyConfiguration{}
+}
+
+// WithTLS sets the TLS field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the TLS field is set to the value of the last call.
+func (b *WebSpecApplyConfiguration) WithTLS(value *WebTLSConfigApplyConfiguration) *WebSpecApplyConfiguration {
+	b.TLS = value
+	return b
+}
+
+// WithBasicAuthUsers sets the BasicAuthUsers field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the BasicAuthUsers field is set to the value of the last call.
+func (b *WebSpecApplyConfiguration) WithBasicAuthUsers(value v1.LocalObjectReference) *WebSpecApplyConfiguration {
+	b.BasicAuthUsers = &value
+	return b
+}
@@ -0,0 +1,69 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+// Code generated by applyconfiguration-gen. DO NOT EDIT.
+
+package v1alpha1
+
+import (
+	v1 "k8s.io/api/core/v1"
+)
+
+// WebTLSConfigApplyConfiguration represents an declarative configuration of the WebTLSConfig type for use
+// with apply.
+type WebTLSConfigApplyConfiguration struct {
+	Cert           *v1.SecretKeySelector `json:"cert,omitempty"`
+	Key            *v1.SecretKeySelector `json:"key,omitempty"`
+	ClientCA       *v1.SecretKeySelector `json:"clientCA,omitempty"`
+	ClientAuthType *string               `json:"clientAuthType,omitempty"`
+}
+
+// WebTLSConfigApplyConfiguration constructs an declarative configuration of the WebTLSConfig type for use with
+// apply.
+func WebTLSConfig() *WebTLSConfigApplyConfiguration {
+	return &WebTLSConfigApplyConfiguration{}
+}
+
+// WithCert sets the Cert field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Cert field is set to the value of the last call.
+func (b *WebTLSConfigApplyConfiguration) WithCert(value v1.SecretKeySelector) *WebTLSConfigApplyConfiguration {
+	b.Cert = &value
+	return b
+}
+
+// WithKey sets the Key field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Key field is set to the value of the last call.
+func (b *WebTLSConfigApplyConfiguration) WithKey(value v1.SecretKeySelector) *WebTLSConfigApplyConfiguration {
+	b.Key = &value
+	return b
+}
+
+// WithClientCA sets the ClientCA field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the ClientCA field is set to the value of the last call.
+func (b *WebTLSConfigApplyConfiguration) WithClientCA(value v1.SecretKeySelector) *WebTLSConfigApplyConfiguration {
+	b.ClientCA = &value
+	return b
+}
+
+// WithClientAuthType sets the ClientAuthType field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the ClientAuthType field is set to the value of the last call.
+func (b *WebTLSConfigApplyConfiguration) WithClientAuthType(value string) *WebTLSConfigApplyConfiguration {
+	b.ClientAuthType = &value
+	return b
+}